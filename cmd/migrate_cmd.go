@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/migrate"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/migrations"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var (
+	migrateAuthStub = false
+	migrateDir      = "migrations"
+)
+
+var migrateCmd = cobra.Command{
+	Use:  "migrate",
+	Long: "Apply the SQL migrations embedded in the binary",
+}
+
+var migrateUpCmd = cobra.Command{
+	Use:  "up",
+	Long: "Apply every pending migration",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrator(cmd, func(ctx context.Context, m *migrate.Migrator) error {
+			return m.Up(ctx)
+		})
+	},
+}
+
+var migrateDownCmd = cobra.Command{
+	Use:  "down [steps]",
+	Long: "Roll back the most recently applied migrations, one unless steps is given",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				logrus.Fatalf("invalid number of steps %q", args[0])
+			}
+			steps = n
+		}
+
+		runMigrator(cmd, func(ctx context.Context, m *migrate.Migrator) error {
+			return m.Down(ctx, steps)
+		})
+	},
+}
+
+var migrateToCmd = cobra.Command{
+	Use:  "to <version>",
+	Long: "Apply or roll back migrations until version is the latest applied one, 0 rolls back everything",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			logrus.Fatalf("invalid migration version %q", args[0])
+		}
+
+		runMigrator(cmd, func(ctx context.Context, m *migrate.Migrator) error {
+			return m.To(ctx, version)
+		})
+	},
+}
+
+var migrateStatusCmd = cobra.Command{
+	Use:  "status",
+	Long: "List migrations and whether they have been applied",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrator(cmd, printMigrationStatus)
+	},
+}
+
+var migrateCreateCmd = cobra.Command{
+	Use:  "create <name>",
+	Long: "Create empty up and down migration files",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		files, err := migrate.Create(migrateDir, args[0])
+		if err != nil {
+			logrus.WithError(err).Fatal("unable to create migration")
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.PersistentFlags().BoolVar(&migrateAuthStub, "auth-stub", false, "create a minimal auth schema first, for local databases without Supabase")
+	migrateCreateCmd.Flags().StringVar(&migrateDir, "dir", migrateDir, "directory to write the migration files to")
+	migrateCmd.AddCommand(&migrateUpCmd, &migrateDownCmd, &migrateToCmd, &migrateStatusCmd, &migrateCreateCmd)
+}
+
+func openMigrator(conf *config.GlobalConfiguration) *migrate.Migrator {
+	db, err := gorm.Open(postgres.Open(conf.DB.URL), &gorm.Config{Logger: observability.NewGormLogrusLogger(conf.LOGGING.Level, conf.LOGGING.SQL)})
+	if err != nil {
+		logrus.Fatalf("error opening database: %+v", err)
+	}
+
+	return newMigrator(db)
+}
+
+func newMigrator(db *gorm.DB) *migrate.Migrator {
+	sqlDB, err := db.DB()
+	if err != nil {
+		logrus.Fatalf("error opening database: %+v", err)
+	}
+
+	all, err := migrate.Load(migrations.FS, migrations.ReplacedChecksums)
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to load migrations")
+	}
+
+	return migrate.NewMigrator(sqlDB, all)
+}
+
+func runMigrator(cmd *cobra.Command, fn func(ctx context.Context, m *migrate.Migrator) error) {
+	ctx := cmd.Context()
+	m := openMigrator(loadGlobalConfig(ctx))
+
+	if migrateAuthStub {
+		if err := m.CreateAuthStub(ctx); err != nil {
+			logrus.WithError(err).Fatal("unable to create auth schema")
+		}
+	}
+
+	if err := fn(ctx, m); err != nil {
+		logrus.WithError(err).Fatal("migration failed")
+	}
+}
+
+func printMigrationStatus(ctx context.Context, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case status.Dirty:
+			state = "dirty"
+		case status.Modified:
+			state = "modified"
+		case status.Applied && status.Name == "":
+			state = "unknown"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/analytics"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/api"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
@@ -41,18 +42,35 @@ func serve(ctx context.Context) {
 		logrus.WithError(err).Fatal("unable to load config")
 	}
 
+	if err := observability.ConfigureTracing(ctx, &conf.Tracing); err != nil {
+		logrus.WithError(err).Fatal("unable to configure tracing")
+	}
+
 	db, err := gorm.Open(postgres.Open(conf.DB.URL), &gorm.Config{Logger: observability.NewGormLogrusLogger(conf.LOGGING.Level, conf.LOGGING.SQL)})
 	if err != nil {
 		logrus.Fatalf("error opening database: %+v", err)
 	}
 
+	if err := observability.InstrumentDB(db); err != nil {
+		logrus.WithError(err).Fatal("unable to instrument database")
+	}
+
+	if err := newMigrator(db).Check(ctx); err != nil {
+		if conf.DB.RequireMigrated {
+			logrus.WithError(err).Fatal("database isn't migrated")
+		}
+		logrus.WithError(err).Warn("database isn't migrated")
+	}
+
 	logrus.Info(conf.API.Host)
 	logrus.Info(conf.API.Port)
 
 	addr := net.JoinHostPort(conf.API.Host, conf.API.Port)
 	logrus.Infof("Dynamic Portfolio API started on: %s", addr)
 
-	a := api.NewAPIWithVersion(conf, db, utilities.Version)
+	health := api.NewHealth()
+	recorder := analytics.NewRecorder(db, &conf.Analytics)
+	a := api.NewAPIWithVersion(conf, db, health, recorder, utilities.Version)
 	ah := reloader.NewAtomicHandler(a)
 
 	// req := httptest.NewRequest(http.MethodGet, "/health", nil)
@@ -85,17 +103,55 @@ func serve(ctx context.Context) {
 			fn := func(latestCfg *config.GlobalConfiguration) {
 				log.Info("reloading api with new configuration")
 				latestAPI := api.NewAPIWithVersion(
-					latestCfg, db, utilities.Version)
+					latestCfg, db, health, recorder, utilities.Version)
 				ah.Store(latestAPI)
+				health.SetReloadResult(nil)
 			}
 
 			rl := reloader.NewReloader(watchDir)
+			rl.OnError = health.SetReloadResult
 			if err := rl.Watch(ctx, fn); err != nil {
 				log.WithError(err).Error("watcher is exiting")
 			}
 		}()
 	}
 
+	if conf.Jobs.Enabled {
+		runWorkers(ctx, db, conf, &wg)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		recorder.Run(ctx)
+	}()
+
+	if conf.API.MetricsPort != "" {
+		metricsSrv := &http.Server{
+			Addr:              net.JoinHostPort(conf.API.Host, conf.API.MetricsPort),
+			Handler:           metricsMux(),
+			ReadHeaderTimeout: 2 * time.Second,
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			<-ctx.Done()
+			if err := metricsSrv.Close(); err != nil {
+				log.WithError(err).Error("metrics server close failed")
+			}
+		}()
+
+		go func() {
+			logrus.Infof("Metrics served on: %s", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != http.ErrServerClosed {
+				log.WithError(err).Error("metrics server listen failed")
+			}
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -104,15 +160,32 @@ func serve(ctx context.Context) {
 
 		defer baseCancel() // close baseContext
 
+		// Fail readiness first so traffic drains before the listener closes.
+		health.SetShuttingDown()
+		if conf.API.ShutdownDelay > 0 {
+			log.Infof("waiting %s before shutting down", conf.API.ShutdownDelay)
+			time.Sleep(conf.API.ShutdownDelay)
+		}
+
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Minute)
 		defer shutdownCancel()
 
 		if err := httpSrv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.Canceled) {
 			log.WithError(err).Error("shutdown failed")
 		}
+
+		if err := recorder.Flush(shutdownCtx); err != nil {
+			log.WithError(err).Error("unable to flush views")
+		}
 	}()
 
 	if err := httpSrv.ListenAndServe(); err != http.ErrServerClosed {
 		log.WithError(err).Fatal("http server listen failed")
 	}
 }
+
+func metricsMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", observability.MetricsHandler())
+	return mux
+}
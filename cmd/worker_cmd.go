@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/jobs"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/webhooks"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var workerCmd = cobra.Command{
+	Use:  "worker",
+	Long: "Run the background jobs and webhook deliveries without the API server",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		worker(cmd.Context(), loadGlobalConfig(cmd.Context()))
+	},
+}
+
+func worker(ctx context.Context, conf *config.GlobalConfiguration) {
+	if err := observability.ConfigureTracing(ctx, &conf.Tracing); err != nil {
+		logrus.WithError(err).Fatal("unable to configure tracing")
+	}
+
+	db, err := gorm.Open(postgres.Open(conf.DB.URL), &gorm.Config{Logger: observability.NewGormLogrusLogger(conf.LOGGING.Level, conf.LOGGING.SQL)})
+	if err != nil {
+		logrus.Fatalf("error opening database: %+v", err)
+	}
+
+	if err := observability.InstrumentDB(db); err != nil {
+		logrus.WithError(err).Fatal("unable to instrument database")
+	}
+
+	var wg sync.WaitGroup
+
+	if conf.API.MetricsPort != "" {
+		metricsSrv := &http.Server{
+			Addr:              net.JoinHostPort(conf.API.Host, conf.API.MetricsPort),
+			Handler:           metricsMux(),
+			ReadHeaderTimeout: 2 * time.Second,
+		}
+
+		go func() {
+			logrus.Infof("Metrics served on: %s", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != http.ErrServerClosed {
+				logrus.WithError(err).Error("metrics server listen failed")
+			}
+		}()
+		defer metricsSrv.Close()
+	}
+
+	runWorkers(ctx, db, conf, &wg)
+	wg.Wait()
+}
+
+// runWorkers starts the job runner, and the webhook dispatcher unless
+// webhooks are disabled, until ctx is done.
+func runWorkers(ctx context.Context, db *gorm.DB, conf *config.GlobalConfiguration, wg *sync.WaitGroup) {
+	registry := jobs.NewRegistry()
+	services.RegisterJobs(registry, db, conf)
+	runner := jobs.NewRunner(db, &conf.Jobs, registry)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		runner.Run(ctx)
+	}()
+
+	if conf.Webhook.Enabled {
+		dispatcher := webhooks.NewDispatcher(db, &conf.Webhook)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			dispatcher.Run(ctx)
+		}()
+	}
+}
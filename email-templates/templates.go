@@ -0,0 +1,8 @@
+// Package emailtemplates embeds the HTML templates of the emails sent by the
+// built-in auth.
+package emailtemplates
+
+import "embed"
+
+//go:embed *.html
+var FS embed.FS
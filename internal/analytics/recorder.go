@@ -0,0 +1,289 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/pkg"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// visitor is who made a request: the signed in user, otherwise the client's
+// address and user agent, which are only kept in memory.
+type visitor struct {
+	userId string
+	client string
+}
+
+type blogViewKey struct {
+	slug    string
+	day     string
+	visitor visitor
+}
+
+type portfolioVisitKey struct {
+	slug    string
+	module  string
+	day     string
+	visitor visitor
+}
+
+type portfolioVisit struct {
+	referrerHost string
+	country      string
+}
+
+// Recorder de-duplicates views in memory and writes them in batches, so
+// reading a blog or a portfolio doesn't cost a write. It outlives API
+// reloads.
+type Recorder struct {
+	db     *gorm.DB
+	config *config.AnalyticsConfiguration
+	geoip  *pkg.GeoIP
+
+	mu              sync.Mutex
+	blogViews       map[blogViewKey]string
+	portfolioVisits map[portfolioVisitKey]portfolioVisit
+
+	// flushMu serializes flushes, which use salts.
+	flushMu sync.Mutex
+	salts   map[string][]byte
+}
+
+func NewRecorder(db *gorm.DB, config *config.AnalyticsConfiguration) *Recorder {
+	r := &Recorder{
+		db:              db,
+		config:          config,
+		blogViews:       map[blogViewKey]string{},
+		portfolioVisits: map[portfolioVisitKey]portfolioVisit{},
+		salts:           map[string][]byte{},
+	}
+
+	if config.Enabled && config.GeoIPDatabase != "" {
+		geoip, err := pkg.OpenGeoIP(config.GeoIPDatabase)
+		if err != nil {
+			logrus.WithError(err).WithField("component", "analytics").Error("unable to load GeoIP database, countries won't be recorded")
+		}
+		r.geoip = geoip
+	}
+
+	return r
+}
+
+// Day is the UTC day t falls on, as stored with a view.
+func Day(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+func newVisitor(userId string, ip string, userAgent string) visitor {
+	if userId != "" {
+		return visitor{userId: userId}
+	}
+
+	return visitor{client: ip + "\x00" + userAgent}
+}
+
+// RecordBlogView buffers a view of the blog with the given slug. Views of
+// unpublished or missing blogs are discarded when flushed.
+func (r *Recorder) RecordBlogView(slug string, userId string, ip string, userAgent string, referrer string) {
+	if r == nil || !r.config.Enabled {
+		return
+	}
+
+	key := blogViewKey{slug: slug, day: Day(time.Now()), visitor: newVisitor(userId, ip, userAgent)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.blogViews[key]; ok {
+		observability.Views.WithLabelValues("blog", "ignored").Inc()
+		return
+	}
+
+	if len(r.blogViews) >= r.config.MaxBuffered {
+		observability.Views.WithLabelValues("blog", "dropped").Inc()
+		return
+	}
+
+	r.blogViews[key] = referrer
+}
+
+// RecordPortfolioVisit buffers a visit of a module, or "portfolio" for the
+// whole page, of the portfolio with the given slug.
+func (r *Recorder) RecordPortfolioVisit(slug string, module string, userId string, ip string, userAgent string, referrerHost string) {
+	if r == nil || !r.config.Enabled {
+		return
+	}
+
+	key := portfolioVisitKey{slug: slug, module: module, day: Day(time.Now()), visitor: newVisitor(userId, ip, userAgent)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.portfolioVisits[key]; ok {
+		observability.Views.WithLabelValues("portfolio", "ignored").Inc()
+		return
+	}
+
+	if len(r.portfolioVisits) >= r.config.MaxBuffered {
+		observability.Views.WithLabelValues("portfolio", "dropped").Inc()
+		return
+	}
+
+	r.portfolioVisits[key] = portfolioVisit{referrerHost: referrerHost, country: r.geoip.Country(net.ParseIP(ip))}
+}
+
+// Run flushes the buffered views every flush interval until ctx is done.
+// Call Flush once the server has stopped to write the remaining ones.
+func (r *Recorder) Run(ctx context.Context) {
+	if !r.config.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logrus.WithError(err).WithField("component", "analytics").Error("unable to flush views")
+			}
+		}
+	}
+}
+
+// Flush writes the buffered views. Views that can't be written are dropped.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	blogViews, portfolioVisits := r.blogViews, r.portfolioVisits
+	r.blogViews = map[blogViewKey]string{}
+	r.portfolioVisits = map[portfolioVisitKey]portfolioVisit{}
+	r.mu.Unlock()
+
+	return errors.Join(r.flushBlogViews(ctx, blogViews), r.flushPortfolioVisits(ctx, portfolioVisits))
+}
+
+func (r *Recorder) flushBlogViews(ctx context.Context, buffered map[blogViewKey]string) error {
+	if len(buffered) == 0 {
+		return nil
+	}
+
+	views := make([]schemas.BlogView, 0, len(buffered))
+	for key, referrer := range buffered {
+		// Like portfolio visitors, signed in readers are only stored hashed,
+		// so blog views don't keep a reading history of users.
+		prefix, id := "anon:", key.visitor.client
+		if key.visitor.userId != "" {
+			prefix, id = "user:", "user:"+key.visitor.userId
+		}
+
+		hash, err := r.hash(ctx, key.day, id)
+		if err != nil {
+			observability.Views.WithLabelValues("blog", "dropped").Add(float64(len(buffered)))
+			return err
+		}
+
+		views = append(views, schemas.BlogView{Slug: key.slug, Day: key.day, Viewer: prefix + hash, ReaderId: key.visitor.userId, Referrer: referrer})
+	}
+
+	recorded, err := repositories.NewAnalyticsRepository(r.db.WithContext(ctx)).InsertBlogViews(views)
+	if err != nil {
+		observability.Views.WithLabelValues("blog", "dropped").Add(float64(len(views)))
+		return err
+	}
+
+	observability.Views.WithLabelValues("blog", "recorded").Add(float64(recorded))
+	observability.Views.WithLabelValues("blog", "ignored").Add(float64(int64(len(views)) - recorded))
+	return nil
+}
+
+func (r *Recorder) flushPortfolioVisits(ctx context.Context, buffered map[portfolioVisitKey]portfolioVisit) error {
+	if len(buffered) == 0 {
+		return nil
+	}
+
+	visits := make([]schemas.PortfolioVisit, 0, len(buffered))
+	for key, visit := range buffered {
+		// Signed in visitors are hashed too, so they can only be told apart
+		// within a day unless they opted in to being shown.
+		id := key.visitor.client
+		if key.visitor.userId != "" {
+			id = "user:" + key.visitor.userId
+		}
+
+		hash, err := r.hash(ctx, key.day, id)
+		if err != nil {
+			observability.Views.WithLabelValues("portfolio", "dropped").Add(float64(len(buffered)))
+			return err
+		}
+
+		visits = append(visits, schemas.PortfolioVisit{
+			Slug:         key.slug,
+			Module:       key.module,
+			Day:          key.day,
+			Visitor:      hash,
+			ViewerId:     key.visitor.userId,
+			ReferrerHost: visit.referrerHost,
+			Country:      visit.country,
+		})
+	}
+
+	recorded, err := repositories.NewAnalyticsRepository(r.db.WithContext(ctx)).InsertPortfolioVisits(visits)
+	if err != nil {
+		observability.Views.WithLabelValues("portfolio", "dropped").Add(float64(len(visits)))
+		return err
+	}
+
+	observability.Views.WithLabelValues("portfolio", "recorded").Add(float64(recorded))
+	observability.Views.WithLabelValues("portfolio", "ignored").Add(float64(int64(len(visits)) - recorded))
+	return nil
+}
+
+// hash returns the HMAC of id keyed with the salt of day. The salt is shared
+// by all replicas through the database and deleted after the day, so the
+// same visitor hashes differently every day.
+func (r *Recorder) hash(ctx context.Context, day string, id string) (string, error) {
+	salt, ok := r.salts[day]
+	if !ok {
+		candidate := make([]byte, 32)
+		if _, err := rand.Read(candidate); err != nil {
+			return "", err
+		}
+
+		var err error
+		salt, err = repositories.NewAnalyticsRepository(r.db.WithContext(ctx)).GetOrCreateSalt(day, candidate)
+		if err != nil {
+			return "", err
+		}
+
+		// Only today's and yesterday's views are ever being flushed.
+		for cached := range r.salts {
+			if cached < day {
+				delete(r.salts, cached)
+			}
+		}
+		r.salts[day] = salt
+	}
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil)[:16]), nil
+}
@@ -0,0 +1,387 @@
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/lib/pq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func newTestRecorder(t *testing.T, maxBuffered int) (*Recorder, *fakeState) {
+	db, state := openFakeDB(t)
+	return NewRecorder(db, &config.AnalyticsConfiguration{Enabled: true, MaxBuffered: maxBuffered}), state
+}
+
+func TestRecordBlogView(t *testing.T) {
+	r, _ := newTestRecorder(t, 4)
+
+	r.RecordBlogView("post", "user-1", "10.0.0.1", "firefox", "")
+	// The same reader from another address.
+	r.RecordBlogView("post", "user-1", "10.0.0.2", "chrome", "https://example.com")
+	r.RecordBlogView("post", "", "10.0.0.1", "firefox", "")
+	r.RecordBlogView("post", "", "10.0.0.1", "firefox", "https://example.com")
+	// Another user agent behind the same address is another visitor.
+	r.RecordBlogView("post", "", "10.0.0.1", "chrome", "")
+	r.RecordBlogView("other", "user-1", "10.0.0.1", "firefox", "")
+	// Over MaxBuffered.
+	r.RecordBlogView("third", "user-1", "10.0.0.1", "firefox", "")
+
+	if len(r.blogViews) != 4 {
+		t.Fatalf("%d views buffered, want 4", len(r.blogViews))
+	}
+
+	// The first view of a reader keeps its referrer.
+	key := blogViewKey{slug: "post", day: Day(time.Now()), visitor: visitor{userId: "user-1"}}
+	if referrer, ok := r.blogViews[key]; !ok || referrer != "" {
+		t.Errorf("referrer of the signed in view = %q, %v, want the first one", referrer, ok)
+	}
+	if _, ok := r.blogViews[blogViewKey{slug: "third", day: key.day, visitor: key.visitor}]; ok {
+		t.Error("view buffered past MaxBuffered")
+	}
+}
+
+func TestRecordDisabled(t *testing.T) {
+	r, _ := newTestRecorder(t, 10)
+	r.config.Enabled = false
+
+	r.RecordBlogView("post", "user-1", "10.0.0.1", "firefox", "")
+	r.RecordPortfolioVisit("someone", "portfolio", "", "10.0.0.1", "firefox", "")
+	if len(r.blogViews) != 0 || len(r.portfolioVisits) != 0 {
+		t.Error("views buffered while analytics are disabled")
+	}
+
+	// Without analytics the API is built without a recorder.
+	var none *Recorder
+	none.RecordBlogView("post", "user-1", "10.0.0.1", "firefox", "")
+	none.RecordPortfolioVisit("someone", "portfolio", "", "10.0.0.1", "firefox", "")
+}
+
+func TestRecordPortfolioVisit(t *testing.T) {
+	r, _ := newTestRecorder(t, 10)
+
+	r.RecordPortfolioVisit("someone", "portfolio", "", "10.0.0.1", "firefox", "example.com")
+	r.RecordPortfolioVisit("someone", "portfolio", "", "10.0.0.1", "firefox", "other.com")
+	r.RecordPortfolioVisit("someone", "educations", "", "10.0.0.1", "firefox", "")
+	r.RecordPortfolioVisit("someone", "portfolio", "user-1", "10.0.0.1", "firefox", "")
+
+	if len(r.portfolioVisits) != 3 {
+		t.Fatalf("%d visits buffered, want 3", len(r.portfolioVisits))
+	}
+	key := portfolioVisitKey{slug: "someone", module: "portfolio", day: Day(time.Now()), visitor: newVisitor("", "10.0.0.1", "firefox")}
+	if visit := r.portfolioVisits[key]; visit.referrerHost != "example.com" {
+		t.Errorf("referrer host = %q, want the first one", visit.referrerHost)
+	}
+}
+
+func TestFlushBlogViews(t *testing.T) {
+	r, state := newTestRecorder(t, 10)
+
+	r.RecordBlogView("post", "user-1", "10.0.0.1", "firefox", "https://example.com")
+	r.RecordBlogView("post", "", "10.0.0.1", "firefox", "")
+	r.RecordBlogView("other", "user-1", "10.0.0.1", "firefox", "")
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(state.blogViews) != 3 {
+		t.Fatalf("%d views written, want 3", len(state.blogViews))
+	}
+	viewers := map[string]string{}
+	for _, view := range state.blogViews {
+		prefix, hash, _ := strings.Cut(view.viewer, ":")
+		if !hashPattern.MatchString(hash) || strings.Contains(view.viewer, "user-1") {
+			t.Errorf("viewer %q isn't hashed", view.viewer)
+		}
+
+		switch prefix {
+		case "user":
+			if view.readerId != "user-1" {
+				t.Errorf("reader of %q = %q, want user-1", view.viewer, view.readerId)
+			}
+		case "anon":
+			if view.readerId != "" {
+				t.Errorf("anonymous view has reader %q", view.readerId)
+			}
+		default:
+			t.Errorf("viewer %q has no kind", view.viewer)
+		}
+
+		if previous, ok := viewers[view.slug+" "+prefix]; ok && previous != view.viewer {
+			t.Errorf("the same reader hashed as %q and %q", previous, view.viewer)
+		}
+		viewers[view.slug+" "+prefix] = view.viewer
+	}
+	if viewers["post user"] != viewers["other user"] {
+		t.Errorf("reader hashed as %q and %q on the same day", viewers["post user"], viewers["other user"])
+	}
+	if viewers["post user"] == viewers["post anon"] {
+		t.Error("reader and anonymous visitor hashed the same")
+	}
+
+	if state.salts != 1 {
+		t.Errorf("salt read %d times, want once", state.salts)
+	}
+	if len(r.blogViews) != 0 {
+		t.Errorf("%d views left buffered", len(r.blogViews))
+	}
+
+	// Nothing is buffered, so nothing is written.
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if state.inserts != 1 {
+		t.Errorf("%d inserts, want 1", state.inserts)
+	}
+}
+
+func TestFlushPortfolioVisits(t *testing.T) {
+	r, state := newTestRecorder(t, 10)
+
+	r.RecordPortfolioVisit("someone", "portfolio", "", "10.0.0.1", "firefox", "example.com")
+	r.RecordPortfolioVisit("someone", "educations", "", "10.0.0.1", "firefox", "")
+	r.RecordPortfolioVisit("someone", "portfolio", "user-1", "10.0.0.1", "firefox", "")
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(state.portfolioVisits) != 3 {
+		t.Fatalf("%d visits written, want 3", len(state.portfolioVisits))
+	}
+	visitors := map[string]string{}
+	for _, visit := range state.portfolioVisits {
+		if !hashPattern.MatchString(visit.visitor) {
+			t.Errorf("visitor %q isn't hashed", visit.visitor)
+		}
+		if visit.viewerId == "" {
+			visitors[visit.module] = visit.visitor
+		} else if visit.viewerId != "user-1" || visit.module != "portfolio" {
+			t.Errorf("visit %+v, want user-1 on the portfolio", visit)
+		} else {
+			visitors["user"] = visit.visitor
+		}
+	}
+	if visitors["portfolio"] != visitors["educations"] {
+		t.Errorf("anonymous visitor hashed as %q and %q on the same day", visitors["portfolio"], visitors["educations"])
+	}
+	if visitors["portfolio"] == visitors["user"] {
+		t.Error("signed in and anonymous visitors hashed the same")
+	}
+}
+
+func TestFlushDropsViewsOnError(t *testing.T) {
+	r, state := newTestRecorder(t, 10)
+	state.failInserts = true
+
+	r.RecordBlogView("post", "", "10.0.0.1", "firefox", "")
+	r.RecordPortfolioVisit("someone", "portfolio", "", "10.0.0.1", "firefox", "")
+
+	if err := r.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() = nil, want the insert errors")
+	}
+	if len(r.blogViews) != 0 || len(r.portfolioVisits) != 0 {
+		t.Error("views kept after a failed flush")
+	}
+
+	state.failInserts = false
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.blogViews) != 0 || len(state.portfolioVisits) != 0 {
+		t.Error("dropped views written by the next flush")
+	}
+}
+
+func TestHash(t *testing.T) {
+	r, state := newTestRecorder(t, 10)
+	ctx := context.Background()
+
+	first, err := r.hash(ctx, "2026-10-18", "user:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := r.hash(ctx, "2026-10-18", "user:1")
+	other, _ := r.hash(ctx, "2026-10-18", "user:2")
+	nextDay, _ := r.hash(ctx, "2026-10-19", "user:1")
+
+	if first != again {
+		t.Errorf("hash changed within a day: %q, %q", first, again)
+	}
+	if first == other {
+		t.Error("two visitors hashed the same")
+	}
+	if first == nextDay {
+		t.Error("visitor hashed the same on two days")
+	}
+	if state.salts != 2 {
+		t.Errorf("salt read %d times, want once a day", state.salts)
+	}
+
+	// Older salts are forgotten once a newer day is flushed.
+	if _, ok := r.salts["2026-10-18"]; ok {
+		t.Error("salt of an older day kept")
+	}
+}
+
+var (
+	fakeRegister sync.Once
+	fakeStates   sync.Map
+)
+
+type fakeBlogView struct {
+	slug     string
+	viewer   string
+	readerId string
+}
+
+type fakePortfolioVisit struct {
+	module   string
+	visitor  string
+	viewerId string
+}
+
+// fakeState is a database answering the recorder's queries.
+type fakeState struct {
+	mu              sync.Mutex
+	salts           int
+	inserts         int
+	failInserts     bool
+	blogViews       []fakeBlogView
+	portfolioVisits []fakePortfolioVisit
+}
+
+func openFakeDB(t *testing.T) (*gorm.DB, *fakeState) {
+	fakeRegister.Do(func() { sql.Register("analyticsfake", fakeDriver{}) })
+
+	state := &fakeState{}
+	fakeStates.Store(t.Name(), state)
+	t.Cleanup(func() { fakeStates.Delete(t.Name()) })
+
+	sqlDB, err := sql.Open("analyticsfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, state
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	state, ok := fakeStates.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("no fake database %q", name)
+	}
+	return &fakeConn{state: state.(*fakeState)}, nil
+}
+
+type fakeConn struct {
+	state *fakeState
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func stringArray(arg driver.NamedValue) []string {
+	var values pq.StringArray
+	if err := values.Scan(arg.Value); err != nil {
+		panic(err)
+	}
+	return values
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.Contains(query, "INSERT INTO portfolio_visits") {
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+
+	s.inserts++
+	if s.failInserts {
+		return nil, errors.New("connection refused")
+	}
+
+	modules, visitors, viewerIds := stringArray(args[1]), stringArray(args[3]), stringArray(args[4])
+	for i := range visitors {
+		s.portfolioVisits = append(s.portfolioVisits, fakePortfolioVisit{module: modules[i], visitor: visitors[i], viewerId: viewerIds[i]})
+	}
+	return driver.RowsAffected(len(visitors)), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "INSERT INTO analytics_salts"):
+		s.salts++
+		// Every day gets its own salt, whatever the candidate.
+		salt := sha256.Sum256([]byte(args[0].Value.(string)))
+		return &fakeRows{columns: []string{"salt"}, values: [][]driver.Value{{salt[:]}}}, nil
+
+	case strings.Contains(query, "INSERT INTO blog_views"):
+		s.inserts++
+		if s.failInserts {
+			return nil, errors.New("connection refused")
+		}
+
+		slugs, viewers, readerIds := stringArray(args[0]), stringArray(args[2]), stringArray(args[3])
+		for i := range slugs {
+			s.blogViews = append(s.blogViews, fakeBlogView{slug: slugs[i], viewer: viewers[i], readerId: readerIds[i]})
+		}
+		return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(slugs))}}}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+type handlerAdmin struct {
+	revocations services.ServiceRevocation
+}
+
+// Ban bans the user, for good unless banned_until is given, and revokes all
+// their sessions. The body is optional.
+func (h *handlerAdmin) Ban(ctx *gin.Context) {
+	adminId := utilities.GetClaims(ctx).Subject
+	userId := ctx.Param("Id")
+
+	var data schemas.SchemaBanUser
+	if err := ctx.ShouldBindJSON(&data); err != nil && !errors.Is(err, io.EOF) {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	err := h.revocations.Ban(ctx, adminId, userId, &data)
+
+	if err != nil {
+		HandleResponseError(ctx, adminError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func (h *handlerAdmin) Unban(ctx *gin.Context) {
+	userId := ctx.Param("Id")
+
+	err := h.revocations.Unban(ctx, userId)
+
+	if err != nil {
+		HandleResponseError(ctx, adminError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+// RevokeSessions revokes the session or token named in the body, or all of
+// the user's sessions without one.
+func (h *handlerAdmin) RevokeSessions(ctx *gin.Context) {
+	userId := ctx.Param("Id")
+
+	var data schemas.SchemaRevokeSessions
+	if err := ctx.ShouldBindJSON(&data); err != nil && !errors.Is(err, io.EOF) {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	err := h.revocations.RevokeSessions(ctx, userId, &data)
+
+	if err != nil {
+		HandleResponseError(ctx, adminError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func adminError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidUserId):
+		return BadRequestError(ErrorCodeValidationFailed, "Invalid user id. The user id must be a UUID.")
+	case errors.Is(err, services.ErrUserNotFound):
+		return CotFoundError(ErrorCodeUserNotFound, "User not found")
+	case errors.Is(err, services.ErrUserNotBanned):
+		return CotFoundError(ErrorCodeUserNotFound, "User is not banned")
+	}
+
+	return err
+}
+
+func NewAdminHandler(revocations services.ServiceRevocation) *handlerAdmin {
+	return &handlerAdmin{
+		revocations: revocations,
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+// maxAnalyticsDays bounds the period analytics can be requested for.
+const maxAnalyticsDays = 365
+
+type handlerAnalytics struct {
+	service services.ServiceAnalytics
+}
+
+func (h *handlerAnalytics) GetBlogAnalytics(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+	slug := ctx.Param("slug")
+
+	days, err := parseAnalyticsDays(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetBlogAnalytics(ctx, userId, slug, days)
+
+	if err != nil {
+		HandleResponseError(ctx, blogError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerAnalytics) GetPortfolioAnalytics(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	days, err := parseAnalyticsDays(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetPortfolioAnalytics(ctx, userId, days)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func parseAnalyticsDays(ctx *gin.Context) (int, error) {
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > maxAnalyticsDays {
+		return 0, ValidationError("Invalid days value. Days must be between 1 and 365.", err)
+	}
+
+	return days, nil
+}
+
+// recordBlogView counts a successful read of the blog, including reads
+// served from the response cache, so it must run before cacheResponse.
+func (a *API) recordBlogView() gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+
+		if a.recorder == nil || ctx.Writer.Status() != http.StatusOK {
+			return
+		}
+
+		a.recorder.RecordBlogView(ctx.Param("slug"), viewerId(ctx), ctx.ClientIP(), ctx.Request.UserAgent(), a.viewReferrer(ctx))
+	})
+}
+
+// recordPortfolioVisit counts a successful visit of a portfolio, or of one of
+// its modules, so it must run before cacheResponse.
+func (a *API) recordPortfolioVisit() gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+
+		if a.recorder == nil || ctx.Writer.Status() != http.StatusOK {
+			return
+		}
+
+		module := ctx.Param("module")
+		if module == "" {
+			module = "portfolio"
+		}
+
+		a.recorder.RecordPortfolioVisit(ctx.Param("slug"), module, viewerId(ctx), ctx.ClientIP(), ctx.Request.UserAgent(), referrerHost(ctx))
+	})
+}
+
+func viewerId(ctx *gin.Context) string {
+	if claims := utilities.GetClaims(ctx); claims != nil {
+		return claims.Subject
+	}
+
+	return ""
+}
+
+// viewReferrer is the page of the site the reader came from, without its
+// query, or empty when unknown.
+func (a *API) viewReferrer(ctx *gin.Context) string {
+	referrer := utilities.GetReferrer(ctx.Request, a.config)
+	if referrer == a.config.SiteURL {
+		return ""
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+// referrerHost is the host of the page the visitor came from, without "www.",
+// or empty when unknown.
+func referrerHost(ctx *gin.Context) string {
+	u, err := url.Parse(ctx.Request.Referer())
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func NewAnalyticsHandler(service services.ServiceAnalytics) *handlerAnalytics {
+	return &handlerAnalytics{
+		service: service,
+	}
+}
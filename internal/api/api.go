@@ -1,14 +1,23 @@
 package api
 
 import (
+	"context"
 	"net/http"
 
 	helmet "github.com/danielkov/gin-helmet"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/analytics"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/openapi"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/pkg"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -18,7 +27,7 @@ const (
 )
 
 func NewAPI(globalConfig *config.GlobalConfiguration, db *gorm.DB) *API {
-	return NewAPIWithVersion(globalConfig, db, defaultVersion)
+	return NewAPIWithVersion(globalConfig, db, NewHealth(), analytics.NewRecorder(db, &globalConfig.Analytics), defaultVersion)
 }
 
 type API struct {
@@ -26,25 +35,60 @@ type API struct {
 	db      *gorm.DB
 	config  *config.GlobalConfiguration
 	version string
+	openAPI *openapi.Document
+	cache   cache.Store
+
+	presigner *pkg.Presigner
+	health    *Health
+	verifier  *pkg.JWTVerifier
+	recorder  *analytics.Recorder
+
+	personalAccessTokens services.ServicePersonalAccessToken
+	revocations          services.ServiceRevocation
+}
+
+// NewAPIWithVersion builds the API. The health and the view recorder are
+// shared by the APIs built on every config reload.
+func NewAPIWithVersion(globalConfig *config.GlobalConfiguration, db *gorm.DB, health *Health, recorder *analytics.Recorder, version string) *API {
+	presigner := pkg.NewPresigner(context.TODO(), &globalConfig.AWS)
+
+	return newAPI(globalConfig, db, presigner, health, recorder, version)
+}
+
+// NewOpenAPIDocument builds the OpenAPI document for the routes the API
+// registers without connecting to the database or the storage bucket.
+func NewOpenAPIDocument(globalConfig *config.GlobalConfiguration, version string) *openapi.Document {
+	return newAPI(globalConfig, nil, nil, NewHealth(), nil, version).openAPI
 }
 
-func NewAPIWithVersion(globalConfig *config.GlobalConfiguration, db *gorm.DB, version string) *API {
-	api := &API{config: globalConfig, db: db, version: version}
+func newAPI(globalConfig *config.GlobalConfiguration, db *gorm.DB, presigner *pkg.Presigner, health *Health, recorder *analytics.Recorder, version string) *API {
+	api := &API{config: globalConfig, db: db, version: version, cache: cache.NewStore(&globalConfig.Cache), presigner: presigner, health: health, verifier: pkg.NewJWTVerifier(&globalConfig.JWT), recorder: recorder}
+	api.personalAccessTokens = services.NewPersonalAccessTokenService(db)
+	api.revocations = services.NewRevocationService(db, &globalConfig.JWT)
 	gin.SetMode(gin.ReleaseMode)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		utilities.ConfigureValidator(v)
+	}
 	app := gin.New()
+	// Let handlers pass ctx as a context.Context carrying the request's span.
+	app.ContextWithFallback = true
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:  []string{"*"},
 		AllowMethods:  []string{"*"},
 		AllowHeaders:  []string{"*"},
+		ExposeHeaders: []string{"Link", APIVersionHeaderName, "Surrogate-Key", "X-Cache"},
 		AllowWildcard: true,
 	}))
 	app.Use(helmet.Default())
 	app.Use(gzip.Gzip(gzip.BestCompression))
 
 	app.Use(observability.AddRequestID(globalConfig))
+	app.Use(observability.TraceRequests(globalConfig.Tracing.ServiceName)...)
 	app.Use(observability.NewStructuredLogger(logrus.StandardLogger(), globalConfig))
+	app.Use(observability.RequestMetrics())
 	app.Use(recoverer())
+	app.Use(apiVersionMiddleware())
 
 	logrus.Info(globalConfig.API.MaxRequestDuration)
 
@@ -54,12 +98,18 @@ func NewAPIWithVersion(globalConfig *config.GlobalConfiguration, db *gorm.DB, ve
 	// }
 
 	app.GET("/health", api.HealthCheck)
+	app.GET("/health/live", api.Live)
+	app.GET("/health/ready", api.Ready)
+	app.GET("/openapi.json", api.OpenAPISpec)
+	app.GET("/docs", api.OpenAPIDocs)
+	app.GET("/docs/:file", api.OpenAPIDocsAsset)
 
 	router := app.Group("/")
 
-	setupRoutes(router, db, api, globalConfig)
+	setupRoutes(router, db, presigner, api, globalConfig)
 
 	api.handler = app
+	api.openAPI = buildOpenAPIDocument(app.Routes(), globalConfig, version)
 
 	return api
 }
@@ -2,17 +2,35 @@ package api
 
 import (
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 )
 
-const APIVersionHeaderName = "X-Supabase-Api-Version"
+// APIVersionHeaderName is the request header clients use to pin the date of
+// the API they were written against. The resolved version is echoed back in
+// the same header.
+const APIVersionHeaderName = "X-Dp-Api-Version"
 
 type APIVersion = time.Time
 
 var (
 	APIVersionInitial  = time.Time{}
 	APIVersion20240101 = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// APIVersion20261019 replaces the {"list", "cursor"} envelope of list
+	// endpoints with schemas.Page.
+	APIVersion20261019 = schemas.PageAPIVersion
 )
 
+// apiVersions lists every released version, newest first. Adding a version
+// here is all it takes for clients to be able to request it.
+var apiVersions = []APIVersion{
+	APIVersion20261019,
+	APIVersion20240101,
+}
+
 func DetermineClosestAPIVersion(date string) (APIVersion, error) {
 	if date == "" {
 		return APIVersionInitial, nil
@@ -23,8 +41,10 @@ func DetermineClosestAPIVersion(date string) (APIVersion, error) {
 		return APIVersionInitial, err
 	}
 
-	if parsed.Compare(APIVersion20240101) >= 0 {
-		return APIVersion20240101, nil
+	for _, version := range apiVersions {
+		if parsed.Compare(version) >= 0 {
+			return version, nil
+		}
 	}
 
 	return APIVersionInitial, nil
@@ -33,3 +53,30 @@ func DetermineClosestAPIVersion(date string) (APIVersion, error) {
 func FormatAPIVersion(apiVersion APIVersion) string {
 	return apiVersion.Format("2006-01-02")
 }
+
+// versionedResponse is implemented by response bodies whose shape depends on
+// the API version the client asked for, such as schemas.Page. sendJSON
+// serializes whatever ForAPIVersion returns instead of the value itself.
+type versionedResponse interface {
+	ForAPIVersion(version APIVersion) any
+}
+
+// apiVersionMiddleware resolves the version requested through
+// APIVersionHeaderName, stores it on the context for handlers and serializers
+// and echoes it in the response. A header that isn't a date gets the same
+// version as no header.
+func apiVersionMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		version, err := DetermineClosestAPIVersion(ctx.GetHeader(APIVersionHeaderName))
+		if err != nil {
+			observability.GetLogEntry(ctx).Entry.WithError(err).Debugf("ignoring invalid %s header", APIVersionHeaderName)
+		}
+
+		withAPIVersion(ctx, version)
+		if version != APIVersionInitial {
+			ctx.Writer.Header().Set(APIVersionHeaderName, FormatAPIVersion(version))
+		}
+
+		ctx.Next()
+	})
+}
@@ -1,9 +1,25 @@
 package api
 
 import (
+	"errors"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/pkg"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+)
+
+// Resources personal access tokens are scoped to, as "<resource>:read" or
+// "<resource>:write".
+const (
+	scopeProfile     = "profile"
+	scopePortfolio   = "portfolio"
+	scopeWorkGallery = "work-gallery"
+	scopeBlogs       = "blogs"
+	scopeComments    = "comments"
 )
 
 func (a *API) requireAuthentication() gin.HandlerFunc {
@@ -11,17 +27,16 @@ func (a *API) requireAuthentication() gin.HandlerFunc {
 		tokenStr, err := pkg.ExtractBearerToken(ctx)
 		if err != nil {
 			HandleResponseError(ctx, UnauthorizedError(ctx.Request.Method, err.Error()))
+			ctx.Abort()
 			return
 		}
 
-		token, err := pkg.ParseJWTClaims(tokenStr, ctx, config.AccessTokenClaims{}, a.config.JWT.Secret)
-		if err != nil {
-			HandleResponseError(ctx, UnauthorizedError(ctx.Request.Method, err.Error()))
+		if err := a.authenticate(ctx, tokenStr); err != nil {
+			HandleResponseError(ctx, err)
+			ctx.Abort()
 			return
 		}
 
-		withToken(ctx, token)
-
 		ctx.Next()
 	})
 }
@@ -34,13 +49,129 @@ func (a *API) authenticateIfSessionPresent() gin.HandlerFunc {
 			return
 		}
 
-		token, err := pkg.ParseJWTClaims(tokenStr, ctx, config.AccessTokenClaims{}, a.config.JWT.Secret)
-		if err != nil {
+		if err := a.authenticate(ctx, tokenStr); err != nil {
 			ctx.Next()
 			return
 		}
 
-		withToken(ctx, token)
 		ctx.Next()
 	})
 }
+
+// authenticate accepts either a JWT or a personal access token carrying the
+// scope set by tokenScope for the route.
+func (a *API) authenticate(ctx *gin.Context, tokenStr string) error {
+	if strings.HasPrefix(tokenStr, services.PersonalAccessTokenPrefix) {
+		return a.authenticatePersonalAccessToken(ctx, tokenStr)
+	}
+
+	claims := &config.AccessTokenClaims{}
+	token, err := a.verifier.ParseClaims(ctx, tokenStr, claims)
+	if err != nil {
+		return UnauthorizedError(ctx.Request.Method, err.Error())
+	}
+
+	if err := a.revocations.Check(ctx, claims); err != nil {
+		return revocationError(err)
+	}
+
+	withToken(ctx, token)
+	return nil
+}
+
+func (a *API) authenticatePersonalAccessToken(ctx *gin.Context, tokenStr string) error {
+	pat, err := a.personalAccessTokens.Authenticate(ctx, tokenStr)
+	if errors.Is(err, services.ErrInvalidPersonalAccessToken) {
+		return UnauthorizedError("Invalid personal access token: token may be revoked or expired")
+	} else if err != nil {
+		return err
+	}
+
+	if err := a.revocations.CheckBanned(ctx, pat.UserId.String()); err != nil {
+		return revocationError(err)
+	}
+
+	scope := requiredScope(ctx)
+	if scope == "" {
+		return ForbiddenError(ErrorCodeInsufficientScope, "Personal access tokens can't be used for this endpoint")
+	}
+	if !hasScope(pat.Scopes, scope) {
+		return ForbiddenError(ErrorCodeInsufficientScope, "Personal access token is missing the %s scope", scope)
+	}
+
+	// Handlers only look at claims, so a token stands in for the user's JWT.
+	withToken(ctx, &jwt.Token{
+		Valid: true,
+		Claims: &config.AccessTokenClaims{
+			StandardClaims: jwt.StandardClaims{Subject: pat.UserId.String()},
+			Role:           "authenticated",
+			AuthenticationMethodReference: []config.AMREntry{
+				{Method: "personal_access_token", Timestamp: pat.CreatedAt.Unix()},
+			},
+		},
+	})
+	return nil
+}
+
+func revocationError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserBanned):
+		return ForbiddenError(ErrorCodeUserBanned, "User is banned")
+	case errors.Is(err, services.ErrSessionRevoked):
+		return httpError(http.StatusUnauthorized, ErrorCodeSessionNotFound, "Session has been revoked")
+	}
+
+	return err
+}
+
+// requireAdmin lets through service role tokens and users whose app
+// metadata has role admin, which only the auth server can set.
+func (a *API) requireAdmin() gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		claims := getClaims(ctx)
+		if claims == nil || (claims.Role != "service_role" && claims.AppMetaData["role"] != "admin") {
+			HandleResponseError(ctx, ForbiddenError(ErrorCodeNotAdmin, "User not allowed"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	})
+}
+
+// tokenScope sets the resource personal access tokens need a scope for on
+// the routes of a group. Routes without one can't be used with them.
+func (a *API) tokenScope(resource string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		withScopeResource(ctx, resource)
+		ctx.Next()
+	})
+}
+
+// requiredScope is read access for safe methods and write access otherwise.
+func requiredScope(ctx *gin.Context) string {
+	resource := getScopeResource(ctx)
+	if resource == "" {
+		return ""
+	}
+
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return resource + ":read"
+	}
+
+	return resource + ":write"
+}
+
+// hasScope reports whether scopes grant scope, where write implies read.
+func hasScope(scopes []string, scope string) bool {
+	resource, _, _ := strings.Cut(scope, ":")
+
+	for _, s := range scopes {
+		if s == scope || s == resource+":write" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+type handlerAuth struct {
+	service services.ServiceAuth
+}
+
+func (h *handlerAuth) Signup(ctx *gin.Context) {
+	var data schemas.SchemaSignup
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	session, user, err := h.service.Signup(ctx, &data, sessionInfo(ctx))
+	if err != nil {
+		HandleResponseError(ctx, authError(err))
+		return
+	}
+
+	if session != nil {
+		sendJSON(ctx, http.StatusOK, session)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, user)
+}
+
+func (h *handlerAuth) Verify(ctx *gin.Context) {
+	var data schemas.SchemaVerify
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Verify(ctx, &data, sessionInfo(ctx))
+	if err != nil {
+		HandleResponseError(ctx, authError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerAuth) Login(ctx *gin.Context) {
+	var data schemas.SchemaLogin
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Login(ctx, &data, sessionInfo(ctx))
+	if err != nil {
+		HandleResponseError(ctx, authError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerAuth) Refresh(ctx *gin.Context) {
+	var data schemas.SchemaRefresh
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Refresh(ctx, data.RefreshToken)
+	if err != nil {
+		HandleResponseError(ctx, authError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerAuth) Logout(ctx *gin.Context) {
+	claims := utilities.GetClaims(ctx)
+
+	if err := h.service.Logout(ctx, claims.Subject, claims.SessionId); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *handlerAuth) Recover(ctx *gin.Context) {
+	var data schemas.SchemaRecover
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := h.service.Recover(ctx, data.Email); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func (h *handlerAuth) UpdatePassword(ctx *gin.Context) {
+	claims := utilities.GetClaims(ctx)
+
+	var data schemas.SchemaUpdatePassword
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := h.service.UpdatePassword(ctx, claims.Subject, claims.SessionId, data.Password); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func sessionInfo(ctx *gin.Context) services.SessionInfo {
+	return services.SessionInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+	}
+}
+
+// authError maps the auth service's sentinel errors to the responses
+// Supabase gives for them.
+func authError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return BadRequestError(ErrorCodeInvalidCredentials, "Invalid login credentials")
+	case errors.Is(err, services.ErrEmailNotConfirmed):
+		return BadRequestError(ErrorCodeEmailNotConfirmed, "Email not confirmed")
+	case errors.Is(err, services.ErrEmailExists):
+		return UnprocessableEntityError(ErrorCodeEmailExists, "A user with this email address has already been registered")
+	case errors.Is(err, services.ErrInvalidToken):
+		return ForbiddenError(ErrorCodeOTPExpired, "Token has expired or is invalid")
+	case errors.Is(err, services.ErrInvalidRefreshToken):
+		return BadRequestError(ErrorCodeRefreshTokenNotFound, "Invalid Refresh Token")
+	case errors.Is(err, services.ErrUserBanned):
+		return ForbiddenError(ErrorCodeUserBanned, "User is banned")
+	}
+
+	return err
+}
+
+func NewAuthHandler(service services.ServiceAuth) *handlerAuth {
+	return &handlerAuth{
+		service: service,
+	}
+}
@@ -1,8 +1,8 @@
 package api
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -20,17 +20,9 @@ func (h *handlerBlog) GetAll(ctx *gin.Context) {
 	if claims != nil {
 		userId = &claims.Subject
 	}
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
@@ -40,29 +32,27 @@ func (h *handlerBlog) GetAll(ctx *gin.Context) {
 		query = &queryStr
 	}
 
-	res, err := h.service.GetAll(userId, query, cursor, limit)
+	sort, err := parseSort(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetAll(ctx, userId, query, sort, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	sendPage(ctx, res)
 }
 
 func (h *handlerBlog) GetUserBlogs(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
@@ -72,14 +62,14 @@ func (h *handlerBlog) GetUserBlogs(ctx *gin.Context) {
 		query = &queryStr
 	}
 
-	res, err := h.service.GetUserBlogs(userId, query, cursor, limit)
+	res, err := h.service.GetUserBlogs(ctx, userId, query, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	sendPage(ctx, res)
 }
 
 func (h *handlerBlog) Get(ctx *gin.Context) {
@@ -90,7 +80,7 @@ func (h *handlerBlog) Get(ctx *gin.Context) {
 	}
 	id := ctx.Param("Id")
 
-	res, err := h.service.Get(*userId, id)
+	res, err := h.service.Get(ctx, *userId, id)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -108,13 +98,32 @@ func (h *handlerBlog) GetBlogBySlug(ctx *gin.Context) {
 	}
 	slug := ctx.Param("slug")
 
-	res, err := h.service.GetBlogBySlug(userId, slug)
+	res, err := h.service.GetBlogBySlug(ctx, userId, slug)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerBlog) GetRelated(ctx *gin.Context) {
+	slug := ctx.Param("slug")
 
+	limit, err := parseLimit(ctx, 5, maxRecommendations)
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
+	res, err := h.service.GetRelated(ctx, slug, limit)
+
+	if err != nil {
+		HandleResponseError(ctx, blogError(err))
+		return
+	}
+
 	sendJSON(ctx, http.StatusOK, res)
 }
 
@@ -133,10 +142,10 @@ func (h *handlerBlog) Create(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Create(userId, &data, status == "publish")
+	res, err := h.service.Create(ctx, userId, &data, status == "publish")
 
 	if err != nil {
-		HandleResponseError(ctx, err)
+		HandleResponseError(ctx, tagError(err))
 		return
 	}
 
@@ -160,10 +169,10 @@ func (h *handlerBlog) Update(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Update(userId, id, &data, status == "publish")
+	res, err := h.service.Update(ctx, userId, id, &data, status == "publish")
 
 	if err != nil {
-		HandleResponseError(ctx, err)
+		HandleResponseError(ctx, tagError(err))
 		return
 	}
 
@@ -175,7 +184,7 @@ func (h *handlerBlog) Unpublish(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	err := h.service.Unpublish(userId, id)
+	err := h.service.Unpublish(ctx, userId, id)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -189,7 +198,7 @@ func (h *handlerBlog) Delete(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	err := h.service.Delete(userId, id)
+	err := h.service.Delete(ctx, userId, id)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -202,7 +211,7 @@ func (h *handlerBlog) Delete(ctx *gin.Context) {
 func (h *handlerBlog) GetMetadata(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetMetadata(userId)
+	res, err := h.service.GetMetadata(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -226,7 +235,7 @@ func (h *handlerBlog) UpdateMetadata(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpdateMetadata(userId, &data)
+	err := h.service.UpdateMetadata(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -252,7 +261,7 @@ func (h *handlerBlog) Reaction(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Reaction(id, userId, &data)
+	res, err := h.service.Reaction(ctx, id, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -267,7 +276,7 @@ func (h *handlerBlog) Bookmark(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	res, err := h.service.Bookmark(id, userId)
+	res, err := h.service.Bookmark(ctx, id, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -282,7 +291,7 @@ func (h *handlerBlog) RemoveBookmark(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	err := h.service.RemoveBookmark(id, userId)
+	err := h.service.RemoveBookmark(ctx, id, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -292,6 +301,14 @@ func (h *handlerBlog) RemoveBookmark(ctx *gin.Context) {
 	sendJSON(ctx, http.StatusOK, nil)
 }
 
+func blogError(err error) error {
+	if errors.Is(err, services.ErrBlogNotFound) {
+		return CotFoundError(ErrorCodeBlogNotFound, "Blog not found")
+	}
+
+	return err
+}
+
 func NewBlogHandler(service services.ServiceBlog) *handlerBlog {
 	return &handlerBlog{service: service}
 }
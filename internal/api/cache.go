@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+)
+
+// cacheTags resolves the tags a cached response is invalidated by, before the
+// handler runs so they can be sent in the Surrogate-Key header.
+type cacheTags func(ctx *gin.Context) ([]string, error)
+
+type cachedResponse struct {
+	Tags []string        `json:"tags"`
+	Body json.RawMessage `json:"body"`
+}
+
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// cacheResponse serves anonymous requests from the response cache and stores
+// successful responses in it. Authenticated responses may depend on the
+// caller, so they are never cached and are marked private.
+func (a *API) cacheResponse(tags cacheTags) gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Writer.Header().Set("Vary", "Authorization, "+APIVersionHeaderName)
+
+		if ctx.GetHeader("Authorization") != "" {
+			ctx.Writer.Header().Set("Cache-Control", "private, no-cache")
+			ctx.Next()
+			return
+		}
+
+		key := FormatAPIVersion(getAPIVersion(ctx)) + " " + ctx.Request.RequestURI
+		if data, ok := a.cache.Get(key); ok {
+			var cached cachedResponse
+			if err := json.Unmarshal(data, &cached); err == nil {
+				a.setPublicCacheHeaders(ctx, cached.Tags)
+				ctx.Writer.Header().Set("X-Cache", "HIT")
+				ctx.Data(http.StatusOK, "application/json; charset=utf-8", cached.Body)
+				ctx.Abort()
+				return
+			}
+		}
+
+		entryTags, err := tags(ctx)
+		if err != nil {
+			// Unknown owner, most likely a missing slug: let the handler
+			// answer without caching the response.
+			ctx.Next()
+			return
+		}
+
+		writer := &cacheWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		a.setPublicCacheHeaders(ctx, entryTags)
+		ctx.Writer.Header().Set("X-Cache", "MISS")
+
+		ctx.Next()
+
+		if ctx.Writer.Status() != http.StatusOK {
+			return
+		}
+
+		data, err := json.Marshal(cachedResponse{Tags: entryTags, Body: writer.body.Bytes()})
+		if err != nil {
+			return
+		}
+		a.cache.Set(key, data, entryTags, a.config.Cache.TTL)
+	})
+}
+
+func (a *API) setPublicCacheHeaders(ctx *gin.Context, tags []string) {
+	ctx.Writer.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(a.config.Cache.TTL.Seconds())))
+	ctx.Writer.Header().Set("Surrogate-Key", strings.Join(tags, " "))
+}
+
+func (a *API) portfolioCacheTags(ctx *gin.Context) ([]string, error) {
+	profile, err := repositories.NewUserRepository(a.db.WithContext(ctx)).GetProfileBySlug(ctx.Param("slug"))
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{cache.UserTag(profile.UserId.String())}, nil
+}
+
+func (a *API) blogCacheTags(ctx *gin.Context) ([]string, error) {
+	userId, err := repositories.NewBlogRepository(a.db.WithContext(ctx)).GetOwnerIdBySlug(ctx.Param("slug"))
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{cache.UserTag(userId)}, nil
+}
+
+// skillsCacheTags tags the skill list, invalidated when a portfolio's skills
+// change. The usage counts it is ordered by are recomputed by the scores job,
+// which may run in the worker, so new counts show once the entry expires.
+func skillsCacheTags(ctx *gin.Context) ([]string, error) {
+	return []string{cache.SkillsTag}, nil
+}
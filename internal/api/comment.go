@@ -46,28 +46,20 @@ func (h *handlerComment) GetAll(ctx *gin.Context) {
 		parentId = &val
 	}
 
-	limitStr := ctx.DefaultQuery("limit", "5")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 5)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
-	res, err := h.service.GetAll(userId, moduleStr, slug, cursor, limit, parentId)
+	res, err := h.service.GetAll(ctx, userId, moduleStr, slug, page, parentId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	sendPage(ctx, res)
 
 }
 
@@ -86,7 +78,7 @@ func (h *handlerComment) Create(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Create(userId, &data)
+	res, err := h.service.Create(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -111,7 +103,7 @@ func (h *handlerComment) Reply(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Reply(userId, id, &data)
+	res, err := h.service.Reply(ctx, userId, id, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -137,7 +129,7 @@ func (h *handlerComment) Reaction(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Reaction(id, userId, &data)
+	res, err := h.service.Reaction(ctx, id, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -13,8 +13,10 @@ func (c contextKey) String() string {
 }
 
 const (
-	tokenKey     = contextKey("jwt")
-	requestIDKey = contextKey("request_id")
+	tokenKey      = contextKey("jwt")
+	requestIDKey  = contextKey("request_id")
+	apiVersionKey = contextKey("api_version")
+	scopeKey      = contextKey("scope_resource")
 )
 
 func withToken(ctx *gin.Context, token *jwt.Token) {
@@ -38,6 +40,14 @@ func getClaims(ctx *gin.Context) *config.AccessTokenClaims {
 	return token.Claims.(*config.AccessTokenClaims)
 }
 
+func withScopeResource(ctx *gin.Context, resource string) {
+	ctx.Set(string(scopeKey), resource)
+}
+
+func getScopeResource(ctx *gin.Context) string {
+	return ctx.GetString(string(scopeKey))
+}
+
 func withRequestID(ctx *gin.Context, id string) {
 	ctx.Set(string(requestIDKey), id)
 }
@@ -49,3 +59,18 @@ func getRequestID(ctx *gin.Context) string {
 	}
 	return obj.(string)
 }
+
+func withAPIVersion(ctx *gin.Context, version APIVersion) {
+	ctx.Set(string(apiVersionKey), version)
+}
+
+// getAPIVersion returns the version resolved by apiVersionMiddleware. Requests
+// that never reached the middleware fall back to reading the header directly.
+func getAPIVersion(ctx *gin.Context) APIVersion {
+	obj, exists := ctx.Get(string(apiVersionKey))
+	if !exists || obj == nil {
+		version, _ := DetermineClosestAPIVersion(ctx.GetHeader(APIVersionHeaderName))
+		return version
+	}
+	return obj.(APIVersion)
+}
@@ -30,4 +30,18 @@ const (
 	ErrorCodeOverEmailSendRateLimit ErrorCode = "over_email_send_rate_limit"
 	ErrorCodeOverSMSSendRateLimit   ErrorCode = "over_sms_send_rate_limit"
 	ErrorCodeRequestTimeout         ErrorCode = "request_timeout"
+	ErrorCodeInvalidCredentials     ErrorCode = "invalid_credentials"
+	ErrorCodeOTPExpired             ErrorCode = "otp_expired"
+	ErrorCodeRefreshTokenNotFound   ErrorCode = "refresh_token_not_found"
+	ErrorCodeInsufficientScope      ErrorCode = "insufficient_scope"
+	ErrorCodeAccessTokenNotFound    ErrorCode = "access_token_not_found"
+	ErrorCodeSessionNotFound        ErrorCode = "session_not_found"
+	ErrorCodeWebhookNotFound        ErrorCode = "webhook_not_found"
+	ErrorCodeDeliveryNotFound       ErrorCode = "webhook_delivery_not_found"
+	ErrorCodeWebhookLimitReached    ErrorCode = "webhook_limit_reached"
+	ErrorCodeBlogNotFound           ErrorCode = "blog_not_found"
+	ErrorCodePortfolioNotFound      ErrorCode = "portfolio_not_found"
+	ErrorCodeTagNotFound            ErrorCode = "tag_not_found"
+	ErrorCodeTagAliasNotFound       ErrorCode = "tag_alias_not_found"
+	ErrorCodeTagDeprecated          ErrorCode = "tag_deprecated"
 )
@@ -23,13 +23,14 @@ func (e DatabaseError) Error() string {
 }
 
 type HTTPError struct {
-	HTTPStatus      int         `json:"statusCode"`
-	ErrorCode       string      `json:"error_code,omitempty"`
-	Message         string      `json:"message"`
-	ErrorData       interface{} `json:"error,omitempty"`
-	InternalError   error       `json:"-"`
-	InternalMessage string      `json:"-"`
-	ErrorID         string      `json:"error_id,omitempty"`
+	HTTPStatus      int                    `json:"statusCode"`
+	ErrorCode       string                 `json:"error_code,omitempty"`
+	Message         string                 `json:"message"`
+	ErrorData       interface{}            `json:"error,omitempty"`
+	Errors          []utilities.FieldError `json:"errors,omitempty"`
+	InternalError   error                  `json:"-"`
+	InternalMessage string                 `json:"-"`
+	ErrorID         string                 `json:"error_id,omitempty"`
 }
 
 func (e *HTTPError) Error() string {
@@ -121,22 +122,16 @@ type ErrorCause interface {
 }
 
 type HTTPErrorResponse20240101 struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
+	Code    ErrorCode              `json:"code"`
+	Message string                 `json:"message"`
+	Errors  []utilities.FieldError `json:"errors,omitempty"`
 }
 
 func HandleResponseError(ctx *gin.Context, err error) {
 	log := observability.GetLogEntry(ctx).Entry
 	errorID := utilities.GetRequestID(ctx)
 
-	apiVersion, averr := DetermineClosestAPIVersion(ctx.Request.Header.Get(APIVersionHeaderName))
-
-	if averr != nil {
-		log.WithError(averr).Warn("Invalid version passed to " + APIVersionHeaderName + " header, defaulting to initial version")
-	} else if apiVersion != APIVersionInitial {
-		// Echo back the determined API version from the request
-		ctx.Writer.Header().Set(APIVersionHeaderName, FormatAPIVersion(apiVersion))
-	}
+	apiVersion := getAPIVersion(ctx)
 
 	switch e := err.(type) {
 	case *HTTPError:
@@ -159,6 +154,7 @@ func HandleResponseError(ctx *gin.Context, err error) {
 			resp := HTTPErrorResponse20240101{
 				Code:    e.ErrorCode,
 				Message: e.Message,
+				Errors:  e.Errors,
 			}
 
 			if resp.Code == "" {
@@ -202,10 +198,23 @@ func HandleResponseError(ctx *gin.Context, err error) {
 	case validator.ValidationErrors:
 		log.WithError(e).Errorf("validation error: %s", e.Error())
 
-		errJson := utilities.ValidationErrorsToJSON(err)
-		validationError := httpValidationError(http.StatusBadRequest, ErrorCodeValidationFailed, "Validation Error", errJson)
+		trans := utilities.Translator(ctx.Request.Header.Get("Accept-Language"))
+		fieldErrors := utilities.ValidationErrorsToFieldErrors(e, trans)
 
-		ctx.JSON(http.StatusBadRequest, validationError)
+		if apiVersion.Compare(APIVersion20240101) >= 0 {
+			ctx.JSON(http.StatusBadRequest, HTTPErrorResponse20240101{
+				Code:    ErrorCodeValidationFailed,
+				Message: "Validation Error",
+				Errors:  fieldErrors,
+			})
+		} else {
+			ctx.JSON(http.StatusBadRequest, HTTPError{
+				HTTPStatus: http.StatusBadRequest,
+				ErrorCode:  ErrorCodeValidationFailed,
+				Message:    "Validation Error",
+				Errors:     fieldErrors,
+			})
+		}
 
 	case ErrorCause:
 		HandleResponseError(ctx, e.Cause())
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+// maxFeedLimit bounds the items of a feed page.
+const maxFeedLimit = 50
+
+type handlerFeed struct {
+	service services.ServiceFeed
+}
+
+func (h *handlerFeed) GetFeed(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	query, err := parseFeedQuery(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetFeed(ctx, userId, query)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if res.NextCursor != nil {
+		ctx.Header("Link", pageLink(ctx, *res.NextCursor, "next"))
+	}
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func parseFeedQuery(ctx *gin.Context) (schemas.FeedQuery, error) {
+	var query schemas.FeedQuery
+	var err error
+
+	query.Limit, err = parseLimit(ctx, 20, maxFeedLimit)
+	if err != nil {
+		return query, err
+	}
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		query.Cursor, err = schemas.ParseFeedCursor(cursor)
+		if err != nil {
+			return query, ValidationError("Invalid cursor value. Cursor must be the next_cursor of a previous page.", err)
+		}
+	}
+
+	return query, nil
+}
+
+func NewFeedHandler(service services.ServiceFeed) *handlerFeed {
+	return &handlerFeed{
+		service: service,
+	}
+}
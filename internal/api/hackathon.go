@@ -16,7 +16,7 @@ type handlerUserHackathon struct {
 func (h *handlerUserHackathon) GetAll(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetAll(userId)
+	res, err := h.service.GetAll(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -40,7 +40,7 @@ func (h *handlerUserHackathon) Create(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Create(userId, &data)
+	res, err := h.service.Create(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -66,7 +66,7 @@ func (h *handlerUserHackathon) Update(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Update(userId, id, &data)
+	res, err := h.service.Update(ctx, userId, id, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -92,7 +92,7 @@ func (h *handlerUserHackathon) Reorder(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.Reorder(userId, id, int(data.NewIndex))
+	err := h.service.Reorder(ctx, userId, id, int(data.NewIndex))
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -107,7 +107,7 @@ func (h *handlerUserHackathon) Delete(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	err := h.service.Delete(userId, id)
+	err := h.service.Delete(ctx, userId, id)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -120,7 +120,7 @@ func (h *handlerUserHackathon) Delete(ctx *gin.Context) {
 func (h *handlerUserHackathon) GetMetadata(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetMetadata(userId)
+	res, err := h.service.GetMetadata(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -144,7 +144,7 @@ func (h *handlerUserHackathon) UpdateMetadata(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpdateMetadata(userId, &data)
+	err := h.service.UpdateMetadata(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
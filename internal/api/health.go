@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/migrate"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/migrations"
+	"github.com/sirupsen/logrus"
+)
+
+// Health is process state reported by the readiness probe. It outlives the
+// API instances replaced on every config reload, so serve creates it once.
+type Health struct {
+	shuttingDown atomic.Bool
+
+	mu        sync.Mutex
+	reloadErr error
+}
+
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// SetShuttingDown makes the readiness probe fail from now on.
+func (h *Health) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// SetReloadResult records the outcome of the latest config reload.
+func (h *Health) SetReloadResult(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.reloadErr = err
+}
+
+func (h *Health) reloadResult() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.reloadErr
+}
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+// HealthCheckResult is the public outcome of a readiness check. Errors and
+// details can name hosts, drivers and versions, so they are only logged.
+type HealthCheckResult struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+type readinessCheck func(ctx context.Context) (any, error)
+
+// Live reports that the process is up and serving HTTP. It never touches
+// dependencies so a database outage doesn't get pods restarted.
+func (a *API) Live(ctx *gin.Context) {
+	sendJSON(ctx, http.StatusOK, gin.H{"status": healthStatusOK})
+}
+
+// Ready runs every readiness check concurrently, each bounded by
+// HealthCheckTimeout, and answers 503 if any of them fails.
+func (a *API) Ready(ctx *gin.Context) {
+	checks := map[string]readinessCheck{
+		"database":   a.checkDatabase,
+		"migrations": a.checkMigrations,
+		"config":     a.checkConfig,
+		"shutdown":   a.checkShutdown,
+	}
+	if a.presigner != nil {
+		checks["storage"] = a.checkStorage
+	}
+
+	res := ReadinessResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]HealthCheckResult, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := a.runCheck(ctx, name, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[name] = result
+			if result.Status != healthStatusOK {
+				res.Status = healthStatusUnavailable
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if res.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	sendJSON(ctx, status, res)
+}
+
+func (a *API) runCheck(ctx *gin.Context, name string, check readinessCheck) HealthCheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, a.config.API.HealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check(checkCtx)
+	if err != nil {
+		observability.GetLogEntry(ctx).Entry.WithError(err).WithFields(logrus.Fields{
+			"check":      name,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"details":    details,
+		}).Warn("readiness check failed")
+		return HealthCheckResult{Status: healthStatusUnavailable}
+	}
+
+	return HealthCheckResult{Status: healthStatusOK}
+}
+
+func (a *API) checkDatabase(ctx context.Context) (any, error) {
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, sqlDB.PingContext(ctx)
+}
+
+// checkMigrations fails on a dirty or modified migration, and on pending ones
+// only when DB.RequireMigrated is set.
+func (a *API) checkMigrations(ctx context.Context) (any, error) {
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := migrate.Load(migrations.FS, migrations.ReplacedChecksums)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := migrate.NewMigrator(sqlDB, all).Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int64
+	pending := 0
+	for _, status := range statuses {
+		if status.Dirty {
+			return gin.H{"version": status.Version}, fmt.Errorf("migration %d is dirty", status.Version)
+		}
+		if status.Modified {
+			return gin.H{"version": status.Version}, fmt.Errorf("migration %d was modified after it was applied", status.Version)
+		}
+		if status.Applied {
+			version = status.Version
+		} else {
+			pending++
+		}
+	}
+
+	details := gin.H{"version": version, "pending": pending}
+	if pending > 0 && a.config.DB.RequireMigrated {
+		return details, fmt.Errorf("%d migrations are pending", pending)
+	}
+
+	return details, nil
+}
+
+func (a *API) checkStorage(ctx context.Context) (any, error) {
+	return nil, a.presigner.HeadBucket(ctx)
+}
+
+func (a *API) checkConfig(ctx context.Context) (any, error) {
+	if err := a.health.reloadResult(); err != nil {
+		return nil, fmt.Errorf("last config reload failed: %w", err)
+	}
+
+	return nil, nil
+}
+
+func (a *API) checkShutdown(ctx context.Context) (any, error) {
+	if a.health.shuttingDown.Load() {
+		return nil, errors.New("shutting down")
+	}
+
+	return nil, nil
+}
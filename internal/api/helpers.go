@@ -1,7 +1,99 @@
 package api
 
-import "github.com/gin-gonic/gin"
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+)
 
 func sendJSON(ctx *gin.Context, status int, obj interface{}) {
+	if versioned, ok := obj.(versionedResponse); ok {
+		obj = versioned.ForAPIVersion(getAPIVersion(ctx))
+	}
 	ctx.JSON(status, obj)
 }
+
+// parsePageQuery reads the limit, cursor and include_total query parameters
+// shared by every list endpoint.
+func parsePageQuery(ctx *gin.Context, defaultLimit int) (schemas.PageQuery, error) {
+	var page schemas.PageQuery
+	var err error
+
+	page.Limit, err = strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || page.Limit <= 0 {
+		return page, ValidationError("Invalid limit value. Limit must be a positive integer.", err)
+	}
+
+	page.Cursor, err = strconv.Atoi(ctx.DefaultQuery("cursor", "0"))
+	if err != nil || page.Cursor < 0 {
+		return page, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err)
+	}
+
+	page.IncludeTotal, err = strconv.ParseBool(ctx.DefaultQuery("include_total", "false"))
+	if err != nil {
+		return page, ValidationError("Invalid include_total value. include_total must be a boolean.", err)
+	}
+
+	return page, nil
+}
+
+// maxRecommendations bounds the related blogs and similar portfolios
+// returned.
+const maxRecommendations = 20
+
+// parseLimit reads the limit query parameter of lists that aren't paginated,
+// bounded by maxLimit.
+func parseLimit(ctx *gin.Context, defaultLimit int, maxLimit int) (int, error) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		return 0, ValidationError(fmt.Sprintf("Invalid limit value. Limit must be between 1 and %d.", maxLimit), err)
+	}
+
+	return limit, nil
+}
+
+func parseSort(ctx *gin.Context) (string, error) {
+	sort := ctx.DefaultQuery("sort", schemas.SortLatest)
+	if !slices.Contains(schemas.Sorts, sort) {
+		return "", ValidationError("Invalid sort value. Sort must be one of "+strings.Join(schemas.Sorts, ", ")+".", nil)
+	}
+
+	return sort, nil
+}
+
+// sendPage writes a list response with RFC 8288 Link headers. The page
+// itself picks its shape for the client's API version.
+func sendPage(ctx *gin.Context, page schemas.Paginated) {
+	setPageLinks(ctx, page)
+	sendJSON(ctx, http.StatusOK, page)
+}
+
+func setPageLinks(ctx *gin.Context, page schemas.Paginated) {
+	next, prev := page.Cursors()
+
+	var links []string
+	if next != nil {
+		links = append(links, pageLink(ctx, strconv.Itoa(*next), "next"))
+	}
+	if prev != nil {
+		links = append(links, pageLink(ctx, "0", "first"), pageLink(ctx, strconv.Itoa(*prev), "prev"))
+	}
+	if len(links) > 0 {
+		ctx.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageLink(ctx *gin.Context, cursor string, rel string) string {
+	query := ctx.Request.URL.Query()
+	query.Set("cursor", cursor)
+
+	u := *ctx.Request.URL
+	u.RawQuery = query.Encode()
+
+	return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+}
@@ -2,28 +2,26 @@ package api
 
 import (
 	"net/http"
-	"strconv"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
 )
 
+// maxTypeaheadLength bounds the length of a typeahead prefix, in characters.
+const maxTypeaheadLength = 100
+
 type handlerMetadata struct {
 	service services.ServiceMetadata
 }
 
 func (h *handlerMetadata) GetAllSkills(ctx *gin.Context) {
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
@@ -33,7 +31,36 @@ func (h *handlerMetadata) GetAllSkills(ctx *gin.Context) {
 		query = &queryStr
 	}
 
-	res, err := h.service.GetAllSkills(query, cursor, limit)
+	res, err := h.service.GetAllSkills(ctx, query, page)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendPage(ctx, res)
+}
+
+func (h *handlerMetadata) Suggest(ctx *gin.Context) {
+	kind := ctx.Param("kind")
+	if !slices.Contains(schemas.TypeaheadKinds, kind) {
+		HandleResponseError(ctx, ValidationError("Invalid kind value. Kind must be one of "+strings.Join(schemas.TypeaheadKinds, ", ")+".", nil))
+		return
+	}
+
+	q := strings.TrimSpace(ctx.Query("q"))
+	if q == "" || utf8.RuneCountInString(q) > maxTypeaheadLength {
+		HandleResponseError(ctx, ValidationError("Invalid q value. q must be between 1 and 100 characters.", nil))
+		return
+	}
+
+	limit, err := parseLimit(ctx, 10, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Suggest(ctx, kind, q, limit)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
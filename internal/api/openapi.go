@@ -0,0 +1,287 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/openapi"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+)
+
+// openAPIDocs is the reference page with the Swagger UI it runs, served
+// from the API so it works offline and under a strict CSP.
+//
+//go:embed openapi_docs
+var openAPIDocs embed.FS
+
+var (
+	limitParam        = openapi.Parameter{Name: "limit", In: "query", Description: "Maximum number of items to return.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1)}}
+	cursorParam       = openapi.Parameter{Name: "cursor", In: "query", Description: "Cursor returned by the previous page.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(0)}}
+	queryParam        = openapi.Parameter{Name: "query", In: "query", Description: "Full text search query.", Schema: &openapi.Schema{Type: "string"}}
+	apiVersionParam   = openapi.Parameter{Name: APIVersionHeaderName, In: "header", Description: "API version date (YYYY-MM-DD) the client was written against. Responses use the newest version released on or before it.", Schema: &openapi.Schema{Type: "string", Format: "date"}}
+	includeTotalParam = openapi.Parameter{Name: "include_total", In: "query", Description: "Also count every matching item and return it as `total`.", Schema: &openapi.Schema{Type: "boolean"}}
+	sortParam         = openapi.Parameter{Name: "sort", In: "query", Description: "Order of the list. `latest`, the default, is by last update; `trending` favours recent engagement; `top_week`, `top_month` and `top_all` rank by engagement over the period. Scores are refreshed every few minutes.", Schema: &openapi.Schema{Type: "string", Enum: []any{"latest", "trending", "top_week", "top_month", "top_all"}}}
+	statusParam       = openapi.Parameter{Name: "status", In: "query", Description: "Pass `publish` to publish the blog.", Schema: &openapi.Schema{Type: "string", Enum: []any{"publish"}}}
+)
+
+var (
+	pageQuery   = []openapi.Parameter{limitParam, cursorParam, includeTotalParam}
+	searchQuery = []openapi.Parameter{limitParam, cursorParam, includeTotalParam, queryParam}
+	rankedQuery = []openapi.Parameter{limitParam, cursorParam, includeTotalParam, queryParam, sortParam}
+
+	feedQuery = []openapi.Parameter{
+		{Name: "limit", In: "query", Description: "Maximum number of items to return.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(50)}},
+		{Name: "cursor", In: "query", Description: "`next_cursor` of the previous page.", Schema: &openapi.Schema{Type: "string"}},
+	}
+
+	recommendationQuery = []openapi.Parameter{
+		{Name: "limit", In: "query", Description: "Maximum number of items to return.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(20)}},
+	}
+
+	unifiedSearchQuery = []openapi.Parameter{
+		{Name: "q", In: "query", Description: "What to search for. Supports quoted phrases, `or` and `-` to exclude a word. Titles and names also match despite typos.", Required: true, Schema: &openapi.Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(maxSearchLength)}},
+		{Name: "type", In: "query", Description: "Kind of results to return. Facets always count every kind.", Schema: &openapi.Schema{Type: "string", Enum: []any{"all", "portfolios", "blogs", "projects", "skills"}}},
+		limitParam,
+		cursorParam,
+		includeTotalParam,
+	}
+
+	typeaheadQuery = []openapi.Parameter{
+		{Name: "q", In: "query", Description: "What the user typed so far.", Required: true, Schema: &openapi.Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(maxTypeaheadLength)}},
+		{Name: "limit", In: "query", Description: "Maximum number of suggestions to return.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(20)}},
+	}
+
+	tagQuery = []openapi.Parameter{limitParam, cursorParam, includeTotalParam, sortParam}
+
+	popularTagsQuery = []openapi.Parameter{
+		{Name: "limit", In: "query", Description: "Maximum number of tags to return.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(maxPopularTags)}},
+	}
+
+	analyticsQuery = []openapi.Parameter{
+		{Name: "days", In: "query", Description: "Number of days to report on, today included.", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(365)}},
+	}
+
+	portfolioListQuery = append(append([]openapi.Parameter{}, rankedQuery...), []openapi.Parameter{
+		{Name: "skills", In: "query", Description: "Skills to filter on. Repeat the parameter for several.", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+		{Name: "skills_match", In: "query", Description: "Whether portfolios need `any`, the default, or `all` of the skills.", Schema: &openapi.Schema{Type: "string", Enum: []any{"any", "all"}}},
+		{Name: "work_domains", In: "query", Description: "Work domains to filter on, any of them. Repeat the parameter for several.", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+		{Name: "colleges", In: "query", Description: "Colleges to filter on, any of them. Repeat the parameter for several.", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+		{Name: "graduation_year_min", In: "query", Description: "Earliest graduation year.", Schema: &openapi.Schema{Type: "integer"}},
+		{Name: "graduation_year_max", In: "query", Description: "Latest graduation year.", Schema: &openapi.Schema{Type: "integer"}},
+		{Name: "has_hackathons", In: "query", Description: "Whether portfolios list hackathons.", Schema: &openapi.Schema{Type: "boolean"}},
+		{Name: "has_certifications", In: "query", Description: "Whether portfolios list certifications.", Schema: &openapi.Schema{Type: "boolean"}},
+		{Name: "job_types", In: "query", Description: "Job types of the work experiences to filter on, any of them. Repeat the parameter for several.", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string", Enum: []any{"PART_TIME", "SEMI_FULL_TIME", "FULL_TIME"}}}},
+	}...)
+
+	portfolioQuery = []openapi.Parameter{
+		{Name: "fields", In: "query", Description: "Comma separated fields to return, e.g. `basic_details,skills`. The id is always returned.", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "include", In: "query", Description: "Comma separated modules to embed, each optionally limited with `:n`, e.g. `educations,work_experiences,blogs:5`. Modules are educations, work_experiences, certifications, hackathons, works, skills and blogs.", Schema: &openapi.Schema{Type: "string"}},
+	}
+)
+
+// routeDocs describes every route registered in setupRoutes, keyed by
+// "<METHOD> <gin path>". Routes missing from here are still documented from
+// the router, just without a summary or request schema.
+var routeDocs = map[string]openapi.OperationSpec{
+	"GET /health":       {Summary: "Health check", Tags: []string{"system"}, Response: HealthCheckResponse{}},
+	"GET /health/live":  {Summary: "Liveness probe", Tags: []string{"system"}},
+	"GET /health/ready": {Summary: "Readiness probe, 503 while a dependency is down", Tags: []string{"system"}, Response: ReadinessResponse{}},
+	"GET /openapi.json": {Summary: "OpenAPI document", Tags: []string{"system"}},
+	"GET /docs":         {Summary: "API reference page", Tags: []string{"system"}},
+	"GET /docs/:file":   {Summary: "API reference page assets", Tags: []string{"system"}},
+
+	"POST /auth/signup":  {Summary: "Sign up with email and password", Description: "Returns a session when `DP_AUTH_AUTO_CONFIRM` is set, otherwise the unconfirmed user. An address that already has an account gets the same response and an email instead.", Tags: []string{"auth"}, Request: schemas.SchemaSignup{}, Response: schemas.Session{}},
+	"POST /auth/verify":  {Summary: "Verify a confirmation or recovery token", Tags: []string{"auth"}, Request: schemas.SchemaVerify{}, Response: schemas.Session{}},
+	"POST /auth/login":   {Summary: "Sign in with email and password", Tags: []string{"auth"}, Request: schemas.SchemaLogin{}, Response: schemas.Session{}},
+	"POST /auth/refresh": {Summary: "Exchange a refresh token for a new session", Tags: []string{"auth"}, Request: schemas.SchemaRefresh{}, Response: schemas.Session{}},
+	"POST /auth/recover": {Summary: "Send a password reset email", Tags: []string{"auth"}, Request: schemas.SchemaRecover{}},
+	"POST /auth/logout":  {Summary: "Sign out the current session", Tags: []string{"auth"}, Security: openapi.SecurityRequired},
+	"PUT /auth/password": {Summary: "Change the password and sign out other sessions", Tags: []string{"auth"}, Security: openapi.SecurityRequired, Request: schemas.SchemaUpdatePassword{}},
+
+	"POST /admin/users/:Id/ban":    {Summary: "Ban a user and revoke all their sessions", Tags: []string{"admin"}, Security: openapi.SecurityRequired, Request: schemas.SchemaBanUser{}},
+	"DELETE /admin/users/:Id/ban":  {Summary: "Lift a user's ban", Tags: []string{"admin"}, Security: openapi.SecurityRequired},
+	"POST /admin/users/:Id/revoke": {Summary: "Revoke one session or token of a user, or all of them", Tags: []string{"admin"}, Security: openapi.SecurityRequired, Request: schemas.SchemaRevokeSessions{}},
+
+	"PUT /admin/tags/:name":                   {Summary: "Rename, describe or deprecate a tag", Description: "A renamed tag keeps its old name as an alias. Deprecated tags stay on their blogs but can't be added to others.", Tags: []string{"admin"}, Security: openapi.SecurityRequired, Request: schemas.SchemaUpdateTag{}, Response: schemas.SelectTag{}},
+	"POST /admin/tags/:name/merge":            {Summary: "Merge a tag into another", Description: "Moves the tag's blogs, followers and aliases, then deletes it, keeping its name as an alias.", Tags: []string{"admin"}, Security: openapi.SecurityRequired, Request: schemas.SchemaMergeTag{}, Response: schemas.SelectTag{}},
+	"POST /admin/tags/:name/aliases":          {Summary: "Add an alias to a tag", Tags: []string{"admin"}, Security: openapi.SecurityRequired, Request: schemas.SchemaTagAlias{}, Response: schemas.SelectTag{}},
+	"DELETE /admin/tags/:name/aliases/:alias": {Summary: "Remove an alias of a tag", Tags: []string{"admin"}, Security: openapi.SecurityRequired},
+
+	"GET /webhooks/":               {Summary: "List webhook endpoints", Tags: []string{"webhooks"}, Security: openapi.SecurityRequired, Response: []models.WebhookEndpoint{}},
+	"POST /webhooks/":              {Summary: "Register a webhook endpoint", Description: "The signing secret is only returned in this response. Every delivery is signed with it in the `X-Dp-Signature` header.", Tags: []string{"webhooks"}, Security: openapi.SecurityRequired, Request: schemas.SchemaWebhookEndpoint{}, Response: schemas.SelectWebhookEndpointWithSecret{}},
+	"PUT /webhooks/:Id":            {Summary: "Update a webhook endpoint", Description: "An empty secret keeps the current one.", Tags: []string{"webhooks"}, Security: openapi.SecurityRequired, Request: schemas.SchemaWebhookEndpoint{}, Response: models.WebhookEndpoint{}},
+	"DELETE /webhooks/:Id":         {Summary: "Delete a webhook endpoint and its delivery log", Tags: []string{"webhooks"}, Security: openapi.SecurityRequired},
+	"GET /webhooks/:Id/deliveries": {Summary: "List an endpoint's deliveries, newest first", Tags: []string{"webhooks"}, Security: openapi.SecurityRequired, Query: pageQuery, Response: schemas.Page[models.WebhookDelivery]{}},
+	"POST /webhooks/:Id/deliveries/:DeliveryId/redeliver": {Summary: "Send a delivery again", Tags: []string{"webhooks"}, Security: openapi.SecurityRequired, Response: models.WebhookDelivery{}},
+
+	"POST /users/presigned-urls":             {Summary: "Create presigned upload URLs", Tags: []string{"users"}, Request: schemas.SchemaPresignedURL{}},
+	"GET /users/profile/":                    {Summary: "Get the signed in user's profile", Tags: []string{"users"}, Security: openapi.SecurityRequired, Response: models.UserProfile{}},
+	"PUT /users/profile/setup":               {Summary: "Complete the initial profile setup", Tags: []string{"users"}, Security: openapi.SecurityRequired, Request: schemas.SchemaProfileBasic{}},
+	"PUT /users/profile/":                    {Summary: "Update the profile", Tags: []string{"users"}, Security: openapi.SecurityRequired, Request: schemas.SchemaProfileBasic{}},
+	"PUT /users/profile/privacy":             {Summary: "Choose whether portfolio owners see your visits", Description: "When enabled, your visits to portfolios while signed in are listed in their owners' analytics.", Tags: []string{"users"}, Security: openapi.SecurityRequired, Request: schemas.SchemaProfilePrivacy{}},
+	"GET /users/profile/followers":           {Summary: "List followers", Tags: []string{"users"}, Security: openapi.SecurityRequired, Query: pageQuery, Response: schemas.Page[schemas.SelectFollowers]{}},
+	"GET /users/profile/following":           {Summary: "List followed users", Tags: []string{"users"}, Security: openapi.SecurityRequired, Query: pageQuery, Response: schemas.Page[schemas.SelectFollowing]{}},
+	"POST /users/profile/:slug/follow":       {Summary: "Follow a user", Tags: []string{"users"}, Security: openapi.SecurityRequired},
+	"DELETE /users/profile/:slug/follow":     {Summary: "Unfollow a user", Tags: []string{"users"}, Security: openapi.SecurityRequired},
+	"GET /users/profile/:slug/follow-status": {Summary: "Check whether the user is followed", Tags: []string{"users"}, Security: openapi.SecurityRequired},
+	"GET /users/tokens/":                     {Summary: "List personal access tokens", Tags: []string{"users"}, Security: openapi.SecurityRequired, Response: []models.PersonalAccessToken{}},
+	"POST /users/tokens/":                    {Summary: "Create a personal access token", Description: "The token is only returned in this response. It is sent as a Bearer token and limited to its scopes.", Tags: []string{"users"}, Security: openapi.SecurityRequired, Request: schemas.SchemaPersonalAccessToken{}, Response: schemas.SelectPersonalAccessTokenCreated{}},
+	"DELETE /users/tokens/:Id":               {Summary: "Revoke a personal access token", Tags: []string{"users"}, Security: openapi.SecurityRequired},
+
+	"GET /portfolio/":               {Summary: "List active portfolios", Description: "`facets` counts the portfolios having each value of every filter, among those matching the other filters.", Tags: []string{"portfolio"}, Security: openapi.SecurityOptional, Query: portfolioListQuery, Response: schemas.PortfolioPage{}},
+	"GET /portfolio/user":           {Summary: "Get the signed in user's portfolio", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired, Response: schemas.SelectGetPortfolio{}},
+	"GET /portfolio/analytics":      {Summary: "Get the visits, visitors and top modules, referrers and countries of your portfolio", Description: "Visitors are counted once per day. `viewers` lists signed in visitors who chose to share their visits.", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired, Query: analyticsQuery, Response: schemas.PortfolioAnalytics{}},
+	"GET /portfolio/:slug/:module":  {Summary: "Get one portfolio module", Tags: []string{"portfolio"}, Security: openapi.SecurityOptional},
+	"GET /portfolio/:slug/similar":  {Summary: "Suggest portfolios like this one", Description: "Ranked by shared work domains, skills and project technologies.", Tags: []string{"portfolio"}, Query: recommendationQuery, Response: []schemas.SelectSimilarPortfolio{}},
+	"GET /portfolio/:slug":          {Summary: "Get a portfolio", Description: "Narrow the response with `fields` and embed modules with `include`. Each included module is returned under its own name.", Tags: []string{"portfolio"}, Security: openapi.SecurityOptional, Query: portfolioQuery, Response: schemas.SelectGetPortfolio{}},
+	"GET /portfolio/skills":         {Summary: "List the user's skills", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired, Response: models.Skills{}},
+	"PUT /portfolio/skills":         {Summary: "Replace the user's skills", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired, Request: schemas.SchemaSkills{}},
+	"PUT /portfolio/resume":         {Summary: "Set the resume URL", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired, Request: schemas.SchemaResume{}},
+	"PUT /portfolio/attachments":    {Summary: "Set a profile attachment", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired, Request: schemas.SchemaProfileAttachment{}},
+	"GET /portfolio/status/:Status": {Summary: "Publish or take down the portfolio", Tags: []string{"portfolio"}, Security: openapi.SecurityRequired},
+
+	"GET /portfolio/educations/":              {Summary: "List educations", Tags: []string{"educations"}, Security: openapi.SecurityRequired, Response: models.Educations{}},
+	"POST /portfolio/educations/":             {Summary: "Create an education", Tags: []string{"educations"}, Security: openapi.SecurityRequired, Request: schemas.SchemaEducation{}, Response: models.Education{}},
+	"PUT /portfolio/educations/:Id":           {Summary: "Update an education", Tags: []string{"educations"}, Security: openapi.SecurityRequired, Request: schemas.SchemaEducation{}, Response: models.Education{}},
+	"PATCH /portfolio/educations/:Id/reorder": {Summary: "Move an education", Tags: []string{"educations"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReorderEducation{}},
+	"DELETE /portfolio/educations/:Id":        {Summary: "Delete an education", Tags: []string{"educations"}, Security: openapi.SecurityRequired},
+	"GET /portfolio/educations/metadata":      {Summary: "Get the educations section metadata", Tags: []string{"educations"}, Security: openapi.SecurityRequired},
+	"PUT /portfolio/educations/metadata":      {Summary: "Update the educations section metadata", Tags: []string{"educations"}, Security: openapi.SecurityRequired, Request: schemas.SchemaEducationMetadata{}},
+
+	"GET /portfolio/experiences/":              {Summary: "List work experiences", Tags: []string{"experiences"}, Security: openapi.SecurityRequired, Response: models.WorkExperiences{}},
+	"POST /portfolio/experiences/":             {Summary: "Create a work experience", Tags: []string{"experiences"}, Security: openapi.SecurityRequired, Request: schemas.SchemaWorkExperience{}, Response: models.WorkExperience{}},
+	"PUT /portfolio/experiences/:Id":           {Summary: "Update a work experience", Tags: []string{"experiences"}, Security: openapi.SecurityRequired, Request: schemas.SchemaWorkExperience{}, Response: models.WorkExperience{}},
+	"PATCH /portfolio/experiences/:Id/reorder": {Summary: "Move a work experience", Tags: []string{"experiences"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReorderItem{}},
+	"DELETE /portfolio/experiences/:Id":        {Summary: "Delete a work experience", Tags: []string{"experiences"}, Security: openapi.SecurityRequired},
+	"GET /portfolio/experiences/metadata":      {Summary: "Get the experiences section metadata", Tags: []string{"experiences"}, Security: openapi.SecurityRequired},
+	"PUT /portfolio/experiences/metadata":      {Summary: "Update the experiences section metadata", Tags: []string{"experiences"}, Security: openapi.SecurityRequired, Request: schemas.SchemaWorkExperienceMetadata{}},
+
+	"GET /portfolio/certifications/":              {Summary: "List certifications", Tags: []string{"certifications"}, Security: openapi.SecurityRequired, Response: models.Certifications{}},
+	"POST /portfolio/certifications/":             {Summary: "Create a certification", Tags: []string{"certifications"}, Security: openapi.SecurityRequired, Request: schemas.SchemaCertification{}, Response: models.Certification{}},
+	"PUT /portfolio/certifications/:Id":           {Summary: "Update a certification", Tags: []string{"certifications"}, Security: openapi.SecurityRequired, Request: schemas.SchemaCertification{}, Response: models.Certification{}},
+	"PATCH /portfolio/certifications/:Id/reorder": {Summary: "Move a certification", Tags: []string{"certifications"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReorderItem{}},
+	"DELETE /portfolio/certifications/:Id":        {Summary: "Delete a certification", Tags: []string{"certifications"}, Security: openapi.SecurityRequired},
+	"GET /portfolio/certifications/metadata":      {Summary: "Get the certifications section metadata", Tags: []string{"certifications"}, Security: openapi.SecurityRequired},
+	"PUT /portfolio/certifications/metadata":      {Summary: "Update the certifications section metadata", Tags: []string{"certifications"}, Security: openapi.SecurityRequired, Request: schemas.SchemaCertificationMetadata{}},
+
+	"GET /portfolio/hackathons/":              {Summary: "List hackathons", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired, Response: models.Hackathons{}},
+	"POST /portfolio/hackathons/":             {Summary: "Create a hackathon", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired, Request: schemas.SchemaHackathon{}, Response: models.Hackathon{}},
+	"PUT /portfolio/hackathons/:Id":           {Summary: "Update a hackathon", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired, Request: schemas.SchemaHackathon{}, Response: models.Hackathon{}},
+	"PATCH /portfolio/hackathons/:Id/reorder": {Summary: "Move a hackathon", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReorderItem{}},
+	"DELETE /portfolio/hackathons/:Id":        {Summary: "Delete a hackathon", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired},
+	"GET /portfolio/hackathons/metadata":      {Summary: "Get the hackathons section metadata", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired},
+	"PUT /portfolio/hackathons/metadata":      {Summary: "Update the hackathons section metadata", Tags: []string{"hackathons"}, Security: openapi.SecurityRequired, Request: schemas.SchemaHackathonMetadata{}},
+
+	"GET /work-gallery/":              {Summary: "List projects from every user", Tags: []string{"work-gallery"}, Security: openapi.SecurityOptional, Query: searchQuery, Response: schemas.Page[schemas.SelectUserTechProject]{}},
+	"GET /work-gallery/user":          {Summary: "List the user's projects", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired, Query: searchQuery, Response: schemas.Page[schemas.SelectUserTechProject]{}},
+	"GET /work-gallery/user/:Id":      {Summary: "Get one of the user's projects", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired, Response: schemas.SelectUserTechProject{}},
+	"POST /work-gallery/":             {Summary: "Create a project", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired, Request: schemas.SchemaTechProject{}},
+	"PUT /work-gallery/:Id":           {Summary: "Update a project", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired, Request: schemas.SchemaTechProject{}},
+	"PATCH /work-gallery/:Id/reorder": {Summary: "Move a project", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReorderItem{}},
+	"DELETE /work-gallery/:Id":        {Summary: "Delete a project", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired},
+	"GET /work-gallery/metadata":      {Summary: "Get the work gallery section metadata", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired},
+	"PUT /work-gallery/metadata":      {Summary: "Update the work gallery section metadata", Tags: []string{"work-gallery"}, Security: openapi.SecurityRequired, Request: schemas.SchemaTechProjectMetadata{}},
+
+	"GET /blogs/":                {Summary: "List published blogs", Tags: []string{"blogs"}, Security: openapi.SecurityOptional, Query: rankedQuery, Response: schemas.Page[schemas.SelectBlog]{}},
+	"GET /blogs/user":            {Summary: "List the user's blogs", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Query: searchQuery, Response: schemas.Page[schemas.SelectBlog]{}},
+	"GET /blogs/user/:Id":        {Summary: "Get one of the user's blogs", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Response: schemas.SelectBlog{}},
+	"GET /blogs/:slug":           {Summary: "Get a published blog", Description: "Counts a view, once per reader per day.", Tags: []string{"blogs"}, Security: openapi.SecurityOptional, Response: schemas.SelectBlog{}},
+	"GET /blogs/:slug/analytics": {Summary: "Get the views, readers, referrers, reactions and comments of one of your blogs", Description: "Works for drafts as well as published blogs.", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Query: analyticsQuery, Response: schemas.BlogAnalytics{}},
+	"GET /blogs/:slug/related":   {Summary: "Suggest blogs to read next", Description: "Ranked by shared tags, readers who bookmarked both and text similarity.", Tags: []string{"blogs"}, Query: recommendationQuery, Response: []schemas.SelectRelatedBlog{}},
+	"PUT /blogs/:Id/unpublish":   {Summary: "Unpublish a blog", Tags: []string{"blogs"}, Security: openapi.SecurityRequired},
+	"POST /blogs/":               {Summary: "Create a blog", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Query: []openapi.Parameter{statusParam}, Request: schemas.SchemaBlog{}, Response: models.Blog{}},
+	"PUT /blogs/:Id":             {Summary: "Update a blog", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Query: []openapi.Parameter{statusParam}, Request: schemas.SchemaBlog{}, Response: models.Blog{}},
+	"DELETE /blogs/:Id":          {Summary: "Delete a blog", Tags: []string{"blogs"}, Security: openapi.SecurityRequired},
+	"GET /blogs/metadata":        {Summary: "Get the blogs section metadata", Tags: []string{"blogs"}, Security: openapi.SecurityRequired},
+	"PUT /blogs/metadata":        {Summary: "Update the blogs section metadata", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Request: schemas.SchemaBlogMetadata{}},
+	"PUT /blogs/:Id/reaction":    {Summary: "Add or remove a reaction", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReaction{}},
+	"PUT /blogs/:Id/bookmark":    {Summary: "Bookmark a blog", Tags: []string{"blogs"}, Security: openapi.SecurityRequired, Response: models.BlogBookmark{}},
+	"DELETE /blogs/:Id/bookmark": {Summary: "Remove a bookmark", Tags: []string{"blogs"}, Security: openapi.SecurityRequired},
+
+	"GET /comments/": {Summary: "List comments", Tags: []string{"comments"}, Security: openapi.SecurityOptional, Query: []openapi.Parameter{
+		{Name: "module", In: "query", Required: true, Schema: &openapi.Schema{Type: "string", Enum: []any{"blog"}}},
+		{Name: "slug", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		{Name: "parent_id", In: "query", Description: "List the replies of this comment.", Schema: &openapi.Schema{Type: "integer"}},
+		limitParam,
+		cursorParam,
+		includeTotalParam,
+	}, Response: schemas.Page[schemas.SelectComment]{}},
+	"POST /comments/":            {Summary: "Create a comment", Tags: []string{"comments"}, Security: openapi.SecurityRequired, Request: schemas.SchemaCreateComment{}},
+	"PUT /comments/:Id/reaction": {Summary: "Add or remove a reaction", Tags: []string{"comments"}, Security: openapi.SecurityRequired, Request: schemas.SchemaReaction{}},
+	"PUT /comments/:Id/reply":    {Summary: "Reply to a comment", Tags: []string{"comments"}, Security: openapi.SecurityRequired, Request: schemas.SchemaCommentReply{}},
+
+	"GET /feed": {Summary: "Get the blogs and projects of the users and tags you follow", Description: "Ranked by publication time, boosted by engagement. Falls back to trending content, with `source` set to `trending`, when you follow nobody.", Tags: []string{"feed"}, Security: openapi.SecurityRequired, Query: feedQuery, Response: schemas.Feed{}},
+
+	"GET /tags/popular":         {Summary: "List the most used tags", Tags: []string{"tags"}, Query: popularTagsQuery, Response: []schemas.SelectTag{}},
+	"GET /tags/following":       {Summary: "List the tags you follow", Tags: []string{"tags"}, Security: openapi.SecurityRequired, Query: pageQuery, Response: schemas.Page[schemas.SelectTag]{}},
+	"GET /tags/:name":           {Summary: "Get a tag and its published blogs", Description: "Aliases resolve to their tag, whose canonical name is returned.", Tags: []string{"tags"}, Security: openapi.SecurityOptional, Query: tagQuery, Response: schemas.TagPage{}},
+	"POST /tags/:name/follow":   {Summary: "Follow a tag", Tags: []string{"tags"}, Security: openapi.SecurityRequired},
+	"DELETE /tags/:name/follow": {Summary: "Unfollow a tag", Tags: []string{"tags"}, Security: openapi.SecurityRequired},
+
+	"GET /search": {Summary: "Search portfolios, blogs, projects and skills", Description: "Results are ranked by relevance. `snippet` is HTML escaped with the matched words wrapped in `<mark>`.", Tags: []string{"search"}, Query: unifiedSearchQuery, Response: schemas.SearchResults{}},
+
+	"GET /metadata/skills":          {Summary: "Search the skills catalogue", Description: "Most used skills first.", Tags: []string{"metadata"}, Query: searchQuery, Response: schemas.Page[models.Skill]{}},
+	"GET /metadata/typeahead/:kind": {Summary: "Complete a skill, tag, institute, company or user", Description: "`kind` is `skills`, `tags`, `institutes`, `companies` or `users`. Names starting with `q` come first, then names with a word close to it, each most popular first.", Tags: []string{"metadata"}, Query: typeaheadQuery, Response: []schemas.SelectSuggestion{}},
+}
+
+func buildOpenAPIDocument(routes gin.RoutesInfo, globalConfig *config.GlobalConfiguration, version string) *openapi.Document {
+	var servers []openapi.Server
+	if globalConfig.API.ExternalURL != "" {
+		servers = append(servers, openapi.Server{URL: globalConfig.API.ExternalURL})
+	}
+
+	g := openapi.NewGenerator(openapi.Info{
+		Title:       "Dynamic Portfolio REST API",
+		Description: "Dynamic Portfolio REST API",
+		Version:     version,
+	}, servers...)
+	g.AddSchema(HTTPError{})
+
+	for _, route := range routes {
+		spec := routeDocs[route.Method+" "+route.Path]
+		if len(spec.Tags) == 0 {
+			spec.Tags = []string{strings.Split(strings.TrimPrefix(route.Path, "/"), "/")[0]}
+		}
+		spec.Query = append(append([]openapi.Parameter{}, spec.Query...), apiVersionParam)
+		g.AddRoute(route.Method, route.Path, route.Handler, spec)
+	}
+
+	return g.Document()
+}
+
+func (a *API) OpenAPISpec(ctx *gin.Context) {
+	sendJSON(ctx, http.StatusOK, a.openAPI)
+}
+
+func (a *API) OpenAPIDocs(ctx *gin.Context) {
+	page, err := openAPIDocs.ReadFile("openapi_docs/index.html")
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+func (a *API) OpenAPIDocsAsset(ctx *gin.Context) {
+	assets, err := fs.Sub(openAPIDocs, "openapi_docs")
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	ctx.FileFromFS(ctx.Param("file"), http.FS(assets))
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func intPtr(i int) *int {
+	return &i
+}
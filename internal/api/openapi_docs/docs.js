@@ -0,0 +1,9 @@
+window.addEventListener("load", function () {
+  window.ui = SwaggerUIBundle({
+    url: "openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    // Don't send the document to validator.swagger.io.
+    validatorUrl: null,
+  });
+});
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+type handlerPersonalAccessToken struct {
+	service services.ServicePersonalAccessToken
+}
+
+func (h *handlerPersonalAccessToken) GetAll(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	res, err := h.service.GetAll(ctx, userId)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerPersonalAccessToken) Create(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	var data schemas.SchemaPersonalAccessToken
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Create(ctx, userId, &data)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerPersonalAccessToken) Delete(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+	id := ctx.Param("Id")
+
+	err := h.service.Delete(ctx, userId, id)
+
+	if errors.Is(err, services.ErrPersonalAccessTokenNotFound) {
+		HandleResponseError(ctx, CotFoundError(ErrorCodeAccessTokenNotFound, "Personal access token not found"))
+		return
+	} else if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func NewPersonalAccessTokenHandler(service services.ServicePersonalAccessToken) *handlerPersonalAccessToken {
+	return &handlerPersonalAccessToken{
+		service: service,
+	}
+}
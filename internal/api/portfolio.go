@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -18,7 +21,7 @@ type handlerPortfolio struct {
 func (h *handlerPortfolio) GetUserDetail(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetUserPortfolio(userId)
+	res, err := h.service.GetUserPortfolio(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -34,34 +37,37 @@ func (h *handlerPortfolio) GetAll(ctx *gin.Context) {
 	if claims != nil {
 		userId = &claims.Subject
 	}
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	filters, err := parsePortfolioFilters(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
-	var query *string
-	queryStr := ctx.Query("query")
-	if len(queryStr) > 0 {
-		query = &queryStr
+	if err := filters.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
 	}
 
-	res, err := h.service.GetAll(userId, query, cursor, limit)
+	sort, err := parseSort(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetAll(ctx, userId, filters, sort, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	sendPage(ctx, res)
 }
 
 func (h *handlerPortfolio) GetPortfolio(ctx *gin.Context) {
@@ -71,7 +77,18 @@ func (h *handlerPortfolio) GetPortfolio(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.GetPortfolio(slug)
+	query, err := parsePortfolioQuery(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := query.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetPortfolio(ctx, slug, query)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -81,6 +98,92 @@ func (h *handlerPortfolio) GetPortfolio(ctx *gin.Context) {
 	sendJSON(ctx, http.StatusOK, res)
 }
 
+// parsePortfolioFilters reads the filters of GET /portfolio. Lists take one
+// value per repeated parameter, e.g. ?skills=Go&skills=React.
+func parsePortfolioFilters(ctx *gin.Context) (*schemas.SchemaPortfolioFilters, error) {
+	filters := schemas.SchemaPortfolioFilters{
+		Skills:      ctx.QueryArray("skills"),
+		SkillsMatch: ctx.DefaultQuery("skills_match", "any"),
+		WorkDomains: ctx.QueryArray("work_domains"),
+		Colleges:    ctx.QueryArray("colleges"),
+		JobTypes:    ctx.QueryArray("job_types"),
+	}
+
+	if query := ctx.Query("query"); query != "" {
+		filters.Query = &query
+	}
+
+	for _, param := range []struct {
+		name  string
+		value **int
+	}{
+		{"graduation_year_min", &filters.GraduationYearMin},
+		{"graduation_year_max", &filters.GraduationYearMax},
+	} {
+		if raw := ctx.Query(param.name); raw != "" {
+			year, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, ValidationError(fmt.Sprintf("Invalid %s value. %s must be a year.", param.name, param.name), err)
+			}
+			*param.value = &year
+		}
+	}
+	if filters.GraduationYearMin != nil && filters.GraduationYearMax != nil && *filters.GraduationYearMin > *filters.GraduationYearMax {
+		return nil, ValidationError("Invalid graduation_year_max value. graduation_year_max must not be before graduation_year_min.", nil)
+	}
+
+	for _, param := range []struct {
+		name  string
+		value **bool
+	}{
+		{"has_hackathons", &filters.HasHackathons},
+		{"has_certifications", &filters.HasCertifications},
+	} {
+		if raw := ctx.Query(param.name); raw != "" {
+			has, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, ValidationError(fmt.Sprintf("Invalid %s value. %s must be a boolean.", param.name, param.name), err)
+			}
+			*param.value = &has
+		}
+	}
+
+	return &filters, nil
+}
+
+// parsePortfolioQuery reads ?fields=basic_details,skills and
+// ?include=educations,blogs:5.
+func parsePortfolioQuery(ctx *gin.Context) (*schemas.SchemaPortfolioQuery, error) {
+	var query schemas.SchemaPortfolioQuery
+
+	for _, field := range strings.Split(ctx.Query("fields"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			query.Fields = append(query.Fields, field)
+		}
+	}
+
+	for _, include := range strings.Split(ctx.Query("include"), ",") {
+		include = strings.TrimSpace(include)
+		if include == "" {
+			continue
+		}
+
+		module, limitStr, found := strings.Cut(include, ":")
+		var limit int
+		if found {
+			var err error
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil {
+				return nil, ValidationError("Invalid include value. Use module or module:limit, e.g. blogs:5.", err)
+			}
+		}
+
+		query.Include = append(query.Include, schemas.PortfolioInclude{Module: module, Limit: limit})
+	}
+
+	return &query, nil
+}
+
 func (h *handlerPortfolio) GetSubModule(ctx *gin.Context) {
 	slug := ctx.Param("slug")
 	if slug == "" {
@@ -93,14 +196,14 @@ func (h *handlerPortfolio) GetSubModule(ctx *gin.Context) {
 		HandleResponseError(ctx, ValidationError("Invalid module value. Module must be a non-empty string.", nil))
 		return
 	}
-	modules := []string{"educations", "work_experiences", "certifications", "hackathons", "works", "skills"}
+	modules := []string{"educations", "work_experiences", "certifications", "hackathons", "works", "skills", "blogs"}
 
 	if !slices.Contains(modules, module) {
 		HandleResponseError(ctx, ValidationError("Invalid module value. Module must be one of educations, experiences, certifications, hackathons.", nil))
 		return
 	}
 
-	res, err := h.service.GetSubModule(slug, module)
+	res, err := h.service.GetSubModule(ctx, slug, module)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -113,7 +216,7 @@ func (h *handlerPortfolio) GetSubModule(ctx *gin.Context) {
 func (h *handlerPortfolio) GetUserSkills(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetSkills(userId)
+	res, err := h.service.GetSkills(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -137,7 +240,7 @@ func (h *handlerPortfolio) UpsertSkills(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpsertSkills(userId, &data)
+	err := h.service.UpsertSkills(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -162,7 +265,7 @@ func (h *handlerPortfolio) UpsertResume(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpsertResume(userId, &data.ResumeUrl)
+	err := h.service.UpsertResume(ctx, userId, &data.ResumeUrl)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -186,7 +289,7 @@ func (h *handlerPortfolio) UpdateProfileAttachment(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpdateProfileAttachment(userId, &data)
+	err := h.service.UpdateProfileAttachment(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -200,7 +303,7 @@ func (h *handlerPortfolio) UpdateStatus(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	status := ctx.Param("Status")
 
-	err := h.service.UpdateStatus(userId, status)
+	err := h.service.UpdateStatus(ctx, userId, status)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -210,6 +313,33 @@ func (h *handlerPortfolio) UpdateStatus(ctx *gin.Context) {
 	sendJSON(ctx, http.StatusOK, nil)
 }
 
+func (h *handlerPortfolio) GetSimilar(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	limit, err := parseLimit(ctx, 5, maxRecommendations)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetSimilar(ctx, slug, limit)
+
+	if err != nil {
+		HandleResponseError(ctx, portfolioError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func portfolioError(err error) error {
+	if errors.Is(err, services.ErrPortfolioNotFound) {
+		return CotFoundError(ErrorCodePortfolioNotFound, "Portfolio not found")
+	}
+
+	return err
+}
+
 func NewPortfolioHandler(service services.ServicePortfolio) *handlerPortfolio {
 	return &handlerPortfolio{service: service}
 }
@@ -1,70 +1,117 @@
 package api
 
 import (
-	"context"
-
 	"github.com/gin-gonic/gin"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/pkg"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-func setupRoutes(router *gin.RouterGroup, db *gorm.DB, api *API, globalConfig *config.GlobalConfiguration) {
-	presigner := pkg.NewPresigner(context.TODO(), &globalConfig.AWS)
-
-	userService := services.NewUserService(db, presigner)
+func setupRoutes(router *gin.RouterGroup, db *gorm.DB, presigner *pkg.Presigner, api *API, globalConfig *config.GlobalConfiguration) {
+	userService := services.NewUserService(db, presigner, api.cache)
 	userHandler := NewUserHandler(userService)
 
-	portfolioService := services.NewPortfolioService(db)
+	portfolioService := services.NewPortfolioService(db, api.cache)
 	portfolioHandler := NewPortfolioHandler(portfolioService)
 
-	userEducationService := services.NewUserEducationService(db)
+	userEducationService := services.NewUserEducationService(db, api.cache)
 	userEducationHandler := NewUserEducationHandler(userEducationService)
 
-	userExperienceService := services.NewUserExperienceService(db)
+	userExperienceService := services.NewUserExperienceService(db, api.cache)
 	userExperienceHandler := NewUserExperienceHandler(userExperienceService)
 
-	userCertificationService := services.NewUserCertificationService(db)
+	userCertificationService := services.NewUserCertificationService(db, api.cache)
 	userCertificationHandler := NewUserCertificationHandler(userCertificationService)
 
-	userHackathonService := services.NewUserHackathonService(db)
+	userHackathonService := services.NewUserHackathonService(db, api.cache)
 	userHackathonHandler := NewUserHackathonHandler(userHackathonService)
 
-	userWorkGalleryService := services.NewWorkGalleryService(db)
+	userWorkGalleryService := services.NewWorkGalleryService(db, api.cache)
 	userWorkGalleryHandler := NewWorkGalleryHandler(userWorkGalleryService)
 
-	blogService := services.NewBlogService(db)
+	blogService := services.NewBlogService(db, api.cache)
 	blogHandler := NewBlogHandler(blogService)
 
 	metadataService := services.NewMetadataService(db)
 	metadataHandler := NewMetadataHandler(metadataService)
 
-	commentService := services.NewServiceComment(db)
+	analyticsService := services.NewAnalyticsService(db)
+	analyticsHandler := NewAnalyticsHandler(analyticsService)
+
+	feedService := services.NewFeedService(db)
+	feedHandler := NewFeedHandler(feedService)
+
+	tagService := services.NewTagService(db, api.cache)
+	tagHandler := NewTagHandler(tagService)
+
+	searchService := services.NewSearchService(db)
+	searchHandler := NewSearchHandler(searchService)
+
+	commentService := services.NewServiceComment(db, api.cache)
 	commentHandler := NewCommentHandler(commentService)
 
+	personalAccessTokenHandler := NewPersonalAccessTokenHandler(api.personalAccessTokens)
+
+	webhookService := services.NewWebhookService(db)
+	webhookHandler := NewWebhookHandler(webhookService)
+
+	adminHandler := NewAdminHandler(api.revocations)
+
+	if globalConfig.Auth.Enabled {
+		mailer, err := pkg.NewMailer(&globalConfig.SMTP)
+		if err != nil {
+			logrus.WithError(err).Fatal("unable to load email templates")
+		}
+
+		authService := services.NewAuthService(db, globalConfig, mailer, api.revocations)
+		authHandler := NewAuthHandler(authService)
+
+		authRouter := router.Group("/auth")
+		{
+			authRouter.POST("/signup", authHandler.Signup)
+			authRouter.POST("/verify", authHandler.Verify)
+			authRouter.POST("/login", authHandler.Login)
+			authRouter.POST("/refresh", authHandler.Refresh)
+			authRouter.POST("/recover", authHandler.Recover)
+			authRouter.POST("/logout", api.requireAuthentication(), authHandler.Logout)
+			authRouter.PUT("/password", api.requireAuthentication(), authHandler.UpdatePassword)
+		}
+	}
+
 	userRouter := router.Group("/users")
 	{
 		userRouter.POST("/presigned-urls", userHandler.GetPresignedURLs)
-		profileRouter := userRouter.Group("/profile").Use(api.requireAuthentication())
+		profileRouter := userRouter.Group("/profile", api.tokenScope(scopeProfile)).Use(api.requireAuthentication())
 		{
 			profileRouter.GET("/", userHandler.GetProfile)
 			profileRouter.PUT("/setup", userHandler.ProfileSetup)
 			profileRouter.PUT("/", userHandler.UpsertProfile)
+			profileRouter.PUT("/privacy", userHandler.UpdatePrivacy)
 			profileRouter.GET("/followers", userHandler.GetFollowers)
 			profileRouter.GET("/following", userHandler.GetFollowing)
 			profileRouter.POST("/:slug/follow", userHandler.Follow)
 			profileRouter.DELETE("/:slug/follow", userHandler.Unfollow)
 			profileRouter.GET("/:slug/follow-status", userHandler.FollowStatus)
 		}
+		// Tokens can only be managed with a JWT, never with another token.
+		tokenRouter := userRouter.Group("/tokens").Use(api.requireAuthentication())
+		{
+			tokenRouter.GET("/", personalAccessTokenHandler.GetAll)
+			tokenRouter.POST("/", personalAccessTokenHandler.Create)
+			tokenRouter.DELETE("/:Id", personalAccessTokenHandler.Delete)
+		}
 	}
 
-	portfolioRouter := router.Group("/portfolio")
+	portfolioRouter := router.Group("/portfolio", api.tokenScope(scopePortfolio))
 	{
 		portfolioRouter.GET("/", api.authenticateIfSessionPresent(), portfolioHandler.GetAll)
 		portfolioRouter.GET("/user", api.requireAuthentication(), portfolioHandler.GetUserDetail)
-		portfolioRouter.GET("/:slug/:module", api.authenticateIfSessionPresent(), portfolioHandler.GetSubModule)
-		portfolioRouter.GET("/:slug", api.authenticateIfSessionPresent(), portfolioHandler.GetPortfolio)
+		portfolioRouter.GET("/analytics", api.requireAuthentication(), analyticsHandler.GetPortfolioAnalytics)
+		portfolioRouter.GET("/:slug/:module", api.authenticateIfSessionPresent(), api.recordPortfolioVisit(), api.cacheResponse(api.portfolioCacheTags), portfolioHandler.GetSubModule)
+		portfolioRouter.GET("/:slug/similar", portfolioHandler.GetSimilar)
+		portfolioRouter.GET("/:slug", api.authenticateIfSessionPresent(), api.recordPortfolioVisit(), api.cacheResponse(api.portfolioCacheTags), portfolioHandler.GetPortfolio)
 		portfolioRouter.GET("/skills", api.requireAuthentication(), portfolioHandler.GetUserSkills)
 		portfolioRouter.PUT("/skills", api.requireAuthentication(), portfolioHandler.UpsertSkills)
 		portfolioRouter.PUT("/resume", api.requireAuthentication(), portfolioHandler.UpsertResume)
@@ -115,7 +162,7 @@ func setupRoutes(router *gin.RouterGroup, db *gorm.DB, api *API, globalConfig *c
 		}
 	}
 
-	workGalleryRouter := router.Group("/work-gallery")
+	workGalleryRouter := router.Group("/work-gallery", api.tokenScope(scopeWorkGallery))
 	{
 		workGalleryRouter.GET("/", api.authenticateIfSessionPresent(), userWorkGalleryHandler.GetAll)
 		workGalleryRouter.GET("/user", api.requireAuthentication(), userWorkGalleryHandler.GetUserWorkGallery)
@@ -129,13 +176,15 @@ func setupRoutes(router *gin.RouterGroup, db *gorm.DB, api *API, globalConfig *c
 
 	}
 
-	blogRouter := router.Group("/blogs")
+	blogRouter := router.Group("/blogs", api.tokenScope(scopeBlogs))
 	{
 		blogRouter.GET("/", api.authenticateIfSessionPresent(), blogHandler.GetAll)
 		blogRouter.GET("/user", api.requireAuthentication(), blogHandler.GetUserBlogs)
 		blogRouter.GET("/user/:Id", api.requireAuthentication(), blogHandler.Get)
-		blogRouter.GET("/:slug", api.authenticateIfSessionPresent(), blogHandler.GetBlogBySlug)
-		blogRouter.PUT("/:Id/unpublish", blogHandler.Unpublish)
+		blogRouter.GET("/:slug", api.authenticateIfSessionPresent(), api.recordBlogView(), api.cacheResponse(api.blogCacheTags), blogHandler.GetBlogBySlug)
+		blogRouter.GET("/:slug/analytics", api.requireAuthentication(), analyticsHandler.GetBlogAnalytics)
+		blogRouter.GET("/:slug/related", blogHandler.GetRelated)
+		blogRouter.PUT("/:Id/unpublish", api.requireAuthentication(), blogHandler.Unpublish)
 		blogRouter.POST("/", api.requireAuthentication(), blogHandler.Create)
 		blogRouter.PUT("/:Id", api.requireAuthentication(), blogHandler.Update)
 		blogRouter.DELETE("/:Id", api.requireAuthentication(), blogHandler.Delete)
@@ -146,7 +195,20 @@ func setupRoutes(router *gin.RouterGroup, db *gorm.DB, api *API, globalConfig *c
 		blogRouter.DELETE("/:Id/bookmark", api.requireAuthentication(), blogHandler.RemoveBookmark)
 	}
 
-	commentRouter := router.Group("/comments")
+	tagRouter := router.Group("/tags", api.tokenScope(scopeBlogs))
+	{
+		tagRouter.GET("/popular", tagHandler.GetPopular)
+		tagRouter.GET("/following", api.requireAuthentication(), tagHandler.GetFollowed)
+		tagRouter.GET("/:name", api.authenticateIfSessionPresent(), tagHandler.GetTag)
+		tagRouter.POST("/:name/follow", api.requireAuthentication(), tagHandler.Follow)
+		tagRouter.DELETE("/:name/follow", api.requireAuthentication(), tagHandler.Unfollow)
+	}
+
+	router.GET("/feed", api.requireAuthentication(), feedHandler.GetFeed)
+
+	router.GET("/search", searchHandler.Search)
+
+	commentRouter := router.Group("/comments", api.tokenScope(scopeComments))
 	{
 		commentRouter.GET("/", api.authenticateIfSessionPresent(), commentHandler.GetAll)
 		commentRouter.POST("/", api.requireAuthentication(), commentHandler.Create)
@@ -154,8 +216,31 @@ func setupRoutes(router *gin.RouterGroup, db *gorm.DB, api *API, globalConfig *c
 		commentRouter.PUT("/:Id/reply", api.requireAuthentication(), commentHandler.Reply)
 	}
 
+	// Like tokens, webhooks can only be managed with a JWT.
+	webhookRouter := router.Group("/webhooks").Use(api.requireAuthentication())
+	{
+		webhookRouter.GET("/", webhookHandler.GetAll)
+		webhookRouter.POST("/", webhookHandler.Create)
+		webhookRouter.PUT("/:Id", webhookHandler.Update)
+		webhookRouter.DELETE("/:Id", webhookHandler.Delete)
+		webhookRouter.GET("/:Id/deliveries", webhookHandler.GetDeliveries)
+		webhookRouter.POST("/:Id/deliveries/:DeliveryId/redeliver", webhookHandler.Redeliver)
+	}
+
+	adminRouter := router.Group("/admin").Use(api.requireAuthentication(), api.requireAdmin())
+	{
+		adminRouter.POST("/users/:Id/ban", adminHandler.Ban)
+		adminRouter.DELETE("/users/:Id/ban", adminHandler.Unban)
+		adminRouter.POST("/users/:Id/revoke", adminHandler.RevokeSessions)
+		adminRouter.PUT("/tags/:name", tagHandler.Update)
+		adminRouter.POST("/tags/:name/merge", tagHandler.Merge)
+		adminRouter.POST("/tags/:name/aliases", tagHandler.AddAlias)
+		adminRouter.DELETE("/tags/:name/aliases/:alias", tagHandler.RemoveAlias)
+	}
+
 	metadataRouter := router.Group("/metadata")
 	{
-		metadataRouter.GET("/skills", metadataHandler.GetAllSkills)
+		metadataRouter.GET("/skills", api.cacheResponse(skillsCacheTags), metadataHandler.GetAllSkills)
+		metadataRouter.GET("/typeahead/:kind", metadataHandler.Suggest)
 	}
 }
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+)
+
+// maxSearchLength bounds the length of a search, in characters.
+const maxSearchLength = 200
+
+type handlerSearch struct {
+	service services.ServiceSearch
+}
+
+func (h *handlerSearch) Search(ctx *gin.Context) {
+	query, err := parseSearchQuery(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Search(ctx, query)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	setPageLinks(ctx, res)
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func parseSearchQuery(ctx *gin.Context) (schemas.SearchQuery, error) {
+	var query schemas.SearchQuery
+	var err error
+
+	query.Q = strings.TrimSpace(ctx.Query("q"))
+	if query.Q == "" || utf8.RuneCountInString(query.Q) > maxSearchLength {
+		return query, ValidationError("Invalid q value. q must be between 1 and 200 characters.", nil)
+	}
+
+	query.Type = ctx.DefaultQuery("type", schemas.SearchTypeAll)
+	if !slices.Contains(schemas.SearchTypes, query.Type) {
+		return query, ValidationError("Invalid type value. Type must be one of "+strings.Join(schemas.SearchTypes, ", ")+".", nil)
+	}
+
+	query.PageQuery, err = parsePageQuery(ctx, 20)
+	return query, err
+}
+
+func NewSearchHandler(service services.ServiceSearch) *handlerSearch {
+	return &handlerSearch{
+		service: service,
+	}
+}
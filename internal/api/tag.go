@@ -0,0 +1,218 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+// maxPopularTags bounds the tags returned by GET /tags/popular.
+const maxPopularTags = 100
+
+type handlerTag struct {
+	service services.ServiceTag
+}
+
+// GetTag returns the tag with a page of the published blogs carrying it.
+// Aliases resolve to their tag, whose canonical name is returned.
+func (h *handlerTag) GetTag(ctx *gin.Context) {
+	claims := utilities.GetClaims(ctx)
+	var userId *string
+	if claims != nil {
+		userId = &claims.Subject
+	}
+
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sort, err := parseSort(ctx)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetTag(ctx, userId, ctx.Param("name"), sort, page)
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	setPageLinks(ctx, res)
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerTag) GetPopular(ctx *gin.Context) {
+	limit, err := parseLimit(ctx, 20, maxPopularTags)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetPopular(ctx, limit)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerTag) GetFollowed(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetFollowed(ctx, userId, page)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	setPageLinks(ctx, res)
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerTag) Follow(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	err := h.service.Follow(ctx, userId, ctx.Param("name"))
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func (h *handlerTag) Unfollow(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	err := h.service.Unfollow(ctx, userId, ctx.Param("name"))
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+// Update renames, describes or deprecates a tag. Admin only.
+func (h *handlerTag) Update(ctx *gin.Context) {
+	var data schemas.SchemaUpdateTag
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Update(ctx, ctx.Param("name"), &data)
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+// Merge merges a tag into another. Admin only.
+func (h *handlerTag) Merge(ctx *gin.Context) {
+	var data schemas.SchemaMergeTag
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Merge(ctx, ctx.Param("name"), &data)
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+// AddAlias adds another name to a tag. Admin only.
+func (h *handlerTag) AddAlias(ctx *gin.Context) {
+	var data schemas.SchemaTagAlias
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.AddAlias(ctx, ctx.Param("name"), &data)
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerTag) RemoveAlias(ctx *gin.Context) {
+	err := h.service.RemoveAlias(ctx, ctx.Param("name"), ctx.Param("alias"))
+
+	if err != nil {
+		HandleResponseError(ctx, tagError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func tagError(err error) error {
+	var deprecated *services.TagDeprecatedError
+
+	switch {
+	case errors.Is(err, services.ErrTagNotFound):
+		return CotFoundError(ErrorCodeTagNotFound, "Tag not found")
+	case errors.Is(err, services.ErrTagAliasNotFound):
+		return CotFoundError(ErrorCodeTagAliasNotFound, "Tag alias not found")
+	case errors.Is(err, services.ErrTagExists):
+		return ConflictError("A tag or alias with this name already exists")
+	case errors.Is(err, services.ErrTagMergeSelf):
+		return ValidationError("A tag can't be merged into itself", nil)
+	case errors.As(err, &deprecated):
+		return UnprocessableEntityError(ErrorCodeTagDeprecated, "Tag %s is deprecated", deprecated.Name)
+	}
+
+	return err
+}
+
+func NewTagHandler(service services.ServiceTag) *handlerTag {
+	return &handlerTag{
+		service: service,
+	}
+}
@@ -2,7 +2,6 @@ package api
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -17,7 +16,7 @@ type handlerUser struct {
 func (h *handlerUser) GetProfile(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetProfile(userId)
+	res, err := h.service.GetProfile(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -41,7 +40,7 @@ func (h *handlerUser) ProfileSetup(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.ProfileSetup(userId, &data)
+	err := h.service.ProfileSetup(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -66,7 +65,7 @@ func (h *handlerUser) UpsertProfile(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpsertProfile(userId, &data)
+	err := h.service.UpsertProfile(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -77,6 +76,30 @@ func (h *handlerUser) UpsertProfile(ctx *gin.Context) {
 
 }
 
+func (h *handlerUser) UpdatePrivacy(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	var data schemas.SchemaProfilePrivacy
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	err := h.service.UpdatePrivacy(ctx, userId, &data)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
 func (h *handlerUser) GetPresignedURLs(ctx *gin.Context) {
 	// userId := utilities.GetClaims(ctx).Subject
 
@@ -104,55 +127,53 @@ func (h *handlerUser) GetPresignedURLs(ctx *gin.Context) {
 func (h *handlerUser) GetFollowers(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
-	res, err := h.service.GetFollowers(userId, cursor, limit)
+	res, err := h.service.GetFollowers(ctx, userId, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	if getAPIVersion(ctx).Before(APIVersion20261019) {
+		// follows were served as a bare array before pagination
+		setPageLinks(ctx, res)
+		sendJSON(ctx, http.StatusOK, res.Items)
+		return
+	}
+
+	sendPage(ctx, res)
 }
 
 func (h *handlerUser) GetFollowing(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
-	res, err := h.service.GetFollowing(userId, cursor, limit)
+	res, err := h.service.GetFollowing(ctx, userId, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	if getAPIVersion(ctx).Before(APIVersion20261019) {
+		// follows were served as a bare array before pagination
+		setPageLinks(ctx, res)
+		sendJSON(ctx, http.StatusOK, res.Items)
+		return
+	}
+
+	sendPage(ctx, res)
 }
 
 func (h *handlerUser) Follow(ctx *gin.Context) {
@@ -160,7 +181,7 @@ func (h *handlerUser) Follow(ctx *gin.Context) {
 
 	slug := ctx.Param("slug")
 
-	err := h.service.FollowUser(userId, slug)
+	err := h.service.FollowUser(ctx, userId, slug)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -175,7 +196,7 @@ func (h *handlerUser) Unfollow(ctx *gin.Context) {
 
 	slug := ctx.Param("slug")
 
-	err := h.service.UnfollowUser(userId, slug)
+	err := h.service.UnfollowUser(ctx, userId, slug)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -190,7 +211,7 @@ func (h *handlerUser) FollowStatus(ctx *gin.Context) {
 
 	slug := ctx.Param("slug")
 
-	res, err := h.service.FollowStatus(userId, slug)
+	res, err := h.service.FollowStatus(ctx, userId, slug)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/services"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+type handlerWebhook struct {
+	service services.ServiceWebhook
+}
+
+func (h *handlerWebhook) GetAll(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	res, err := h.service.GetAll(ctx, userId)
+
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerWebhook) Create(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+
+	var data schemas.SchemaWebhookEndpoint
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Create(ctx, userId, &data)
+
+	if err != nil {
+		HandleResponseError(ctx, webhookError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerWebhook) Update(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+	id := ctx.Param("Id")
+
+	var data schemas.SchemaWebhookEndpoint
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	if err := data.Validate(); err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.Update(ctx, userId, id, &data)
+
+	if err != nil {
+		HandleResponseError(ctx, webhookError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func (h *handlerWebhook) Delete(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+	id := ctx.Param("Id")
+
+	err := h.service.Delete(ctx, userId, id)
+
+	if err != nil {
+		HandleResponseError(ctx, webhookError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, nil)
+}
+
+func (h *handlerWebhook) GetDeliveries(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+	id := ctx.Param("Id")
+
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
+		return
+	}
+
+	res, err := h.service.GetDeliveries(ctx, userId, id, page)
+
+	if err != nil {
+		HandleResponseError(ctx, webhookError(err))
+		return
+	}
+
+	sendPage(ctx, res)
+}
+
+func (h *handlerWebhook) Redeliver(ctx *gin.Context) {
+	userId := utilities.GetClaims(ctx).Subject
+	id := ctx.Param("Id")
+	deliveryId := ctx.Param("DeliveryId")
+
+	res, err := h.service.Redeliver(ctx, userId, id, deliveryId)
+
+	if err != nil {
+		HandleResponseError(ctx, webhookError(err))
+		return
+	}
+
+	sendJSON(ctx, http.StatusOK, res)
+}
+
+func webhookError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		return CotFoundError(ErrorCodeWebhookNotFound, "Webhook endpoint not found")
+	case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		return CotFoundError(ErrorCodeDeliveryNotFound, "Webhook delivery not found")
+	case errors.Is(err, services.ErrWebhookLimitReached):
+		return UnprocessableEntityError(ErrorCodeWebhookLimitReached, "Webhook endpoint limit reached")
+	}
+
+	return err
+}
+
+func NewWebhookHandler(service services.ServiceWebhook) *handlerWebhook {
+	return &handlerWebhook{
+		service: service,
+	}
+}
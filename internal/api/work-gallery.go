@@ -2,7 +2,6 @@ package api
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -20,17 +19,9 @@ func (h *handlerWorkGallery) GetAll(ctx *gin.Context) {
 	if claims != nil {
 		userId = &claims.Subject
 	}
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
@@ -40,29 +31,21 @@ func (h *handlerWorkGallery) GetAll(ctx *gin.Context) {
 		query = &queryStr
 	}
 
-	res, err := h.service.GetAll(userId, query, cursor, limit)
+	res, err := h.service.GetAll(ctx, userId, query, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	sendPage(ctx, res)
 }
 
 func (h *handlerWorkGallery) GetUserWorkGallery(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
-	limitStr := ctx.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		HandleResponseError(ctx, ValidationError("Invalid limit value. Limit must be a positive integer.", err))
-		return
-	}
-
-	cursorStr := ctx.DefaultQuery("cursor", "0")
-	cursor, err := strconv.Atoi(cursorStr)
-	if err != nil || cursor < 0 {
-		HandleResponseError(ctx, ValidationError("Invalid cursor value. Cursor must be a non-negative integer.", err))
+	page, err := parsePageQuery(ctx, 20)
+	if err != nil {
+		HandleResponseError(ctx, err)
 		return
 	}
 
@@ -72,21 +55,21 @@ func (h *handlerWorkGallery) GetUserWorkGallery(ctx *gin.Context) {
 		query = &queryStr
 	}
 
-	res, err := h.service.GetUserWorkGallery(userId, query, cursor, limit)
+	res, err := h.service.GetUserWorkGallery(ctx, userId, query, page)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
 		return
 	}
 
-	sendJSON(ctx, http.StatusOK, res)
+	sendPage(ctx, res)
 }
 
 func (h *handlerWorkGallery) Get(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	res, err := h.service.Get(userId, id)
+	res, err := h.service.Get(ctx, userId, id)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -110,7 +93,7 @@ func (h *handlerWorkGallery) Create(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Create(userId, &data)
+	res, err := h.service.Create(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -136,7 +119,7 @@ func (h *handlerWorkGallery) Update(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Update(userId, id, &data)
+	res, err := h.service.Update(ctx, userId, id, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -162,7 +145,7 @@ func (h *handlerWorkGallery) Reorder(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.Reorder(userId, id, int(data.NewIndex))
+	err := h.service.Reorder(ctx, userId, id, int(data.NewIndex))
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -177,7 +160,7 @@ func (h *handlerWorkGallery) Delete(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 	id := ctx.Param("Id")
 
-	err := h.service.Delete(userId, id)
+	err := h.service.Delete(ctx, userId, id)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -190,7 +173,7 @@ func (h *handlerWorkGallery) Delete(ctx *gin.Context) {
 func (h *handlerWorkGallery) GetMetadata(ctx *gin.Context) {
 	userId := utilities.GetClaims(ctx).Subject
 
-	res, err := h.service.GetMetadata(userId)
+	res, err := h.service.GetMetadata(ctx, userId)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -214,7 +197,7 @@ func (h *handlerWorkGallery) UpdateMetadata(ctx *gin.Context) {
 		return
 	}
 
-	err := h.service.UpdateMetadata(userId, &data)
+	err := h.service.UpdateMetadata(ctx, userId, &data)
 
 	if err != nil {
 		HandleResponseError(ctx, err)
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+)
+
+// Store caches serialized responses. Every entry carries tags naming what it
+// was built from so writers can drop all entries depending on something
+// without knowing their keys. Implementations must be safe for concurrent
+// use; an external store such as Redis only has to implement this interface.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, tags []string, ttl time.Duration)
+	Invalidate(tags ...string)
+}
+
+// UserTag is the tag of every cached response built from content owned by
+// the user, such as their portfolio or blogs.
+func UserTag(userId string) string {
+	return "user:" + userId
+}
+
+const SkillsTag = "skills"
+
+// NewStore returns the store described by the configuration, a no-op store
+// when caching is disabled.
+func NewStore(conf *config.CacheConfiguration) Store {
+	if !conf.Enabled {
+		return NewNoop()
+	}
+	return NewLRU(conf.Size)
+}
+
+type noop struct{}
+
+func NewNoop() *noop {
+	return &noop{}
+}
+
+func (noop) Get(key string) ([]byte, bool) {
+	return nil, false
+}
+
+func (noop) Set(key string, value []byte, tags []string, ttl time.Duration) {}
+
+func (noop) Invalidate(tags ...string) {}
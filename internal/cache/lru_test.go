@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", []byte("1"), nil, 0)
+	c.Set("b", []byte("2"), nil, 0)
+
+	// Reading a makes b the least recently used entry.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	c.Set("c", []byte("3"), nil, 0)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b kept, want it evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s evicted, want it kept", key)
+		}
+	}
+}
+
+func TestLRUReplacesEntry(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", []byte("1"), []string{"user:1"}, 0)
+	c.Set("a", []byte("2"), []string{"user:2"}, 0)
+
+	if got, _ := c.Get("a"); string(got) != "2" {
+		t.Errorf("Get(a) = %q, want %q", got, "2")
+	}
+
+	// The replaced entry's tags no longer apply.
+	c.Invalidate("user:1")
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a invalidated by the tag of the value it replaced")
+	}
+	if len(c.tags) != 1 {
+		t.Errorf("%d tags indexed, want 1", len(c.tags))
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", []byte("1"), []string{"user:1"}, time.Nanosecond)
+	c.Set("b", []byte("2"), nil, 0)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("a served after its ttl")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("b expired without a ttl")
+	}
+	if len(c.entries) != 1 || len(c.tags) != 0 {
+		t.Errorf("%d entries and %d tags left, want 1 and 0", len(c.entries), len(c.tags))
+	}
+}
+
+func TestLRUInvalidate(t *testing.T) {
+	c := NewLRU(10)
+	c.Set("portfolio", []byte("1"), []string{UserTag("1")}, 0)
+	c.Set("blog", []byte("2"), []string{UserTag("1")}, 0)
+	c.Set("other", []byte("3"), []string{UserTag("2")}, 0)
+	c.Set("skills", []byte("4"), []string{SkillsTag}, 0)
+	c.Set("shared", []byte("5"), []string{UserTag("2"), SkillsTag}, 0)
+
+	c.Invalidate(UserTag("1"), SkillsTag)
+
+	for _, key := range []string{"portfolio", "blog", "skills", "shared"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("%s kept, want it invalidated", key)
+		}
+	}
+	if _, ok := c.Get("other"); !ok {
+		t.Error("other invalidated, want it kept")
+	}
+
+	// The entry dropped through the skills tag is gone from the user tag too.
+	c.Invalidate(UserTag("2"))
+	if len(c.entries) != 0 || len(c.tags) != 0 || c.order.Len() != 0 {
+		t.Errorf("%d entries, %d tags and %d list elements left, want none", len(c.entries), len(c.tags), c.order.Len())
+	}
+}
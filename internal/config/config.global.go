@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -19,6 +20,14 @@ type APIConfiguration struct {
 	RequestIDHeader    string        `envconfig:"REQUEST_ID_HEADER"`
 	ExternalURL        string        `json:"external_url" envconfig:"API_EXTERNAL_URL" required:"true"`
 	MaxRequestDuration time.Duration `json:"max_request_duration" split_words:"true" default:"10s"`
+	// MetricsPort is the port /metrics is served on, kept off the public
+	// port. Leave empty to disable the endpoint.
+	MetricsPort string `json:"metrics_port" split_words:"true" default:"9100"`
+	// HealthCheckTimeout bounds each readiness check.
+	HealthCheckTimeout time.Duration `json:"health_check_timeout" split_words:"true" default:"2s"`
+	// ShutdownDelay is how long serve keeps answering, not-ready, after a
+	// shutdown signal so load balancers stop routing to it first.
+	ShutdownDelay time.Duration `json:"shutdown_delay" split_words:"true" default:"0s"`
 }
 
 type AWSConfiguration struct {
@@ -39,14 +48,170 @@ func (a *APIConfiguration) Validate() error {
 
 type DBConfiguration struct {
 	URL string `json:"url" required:"true"`
+	// RequireMigrated makes serve refuse to start while a migration embedded
+	// in the binary is pending, dirty or modified.
+	RequireMigrated bool `json:"require_migrated" split_words:"true"`
 }
 
 func (c *DBConfiguration) Validate() error {
 	return nil
 }
 
+// JWTConfiguration selects the keys access tokens are verified with: HS256
+// secrets, a JWKS for asymmetric algorithms, or both.
 type JWTConfiguration struct {
-	Secret string `json:"secret" required:"true"`
+	Secret string `json:"secret"`
+	// Secrets are previous HS256 secrets still accepted while rotating Secret.
+	Secrets             []string      `json:"secrets"`
+	JWKSURL             string        `json:"jwks_url" envconfig:"JWKS_URL"`
+	JWKSFile            string        `json:"jwks_file" envconfig:"JWKS_FILE"`
+	JWKSRefreshInterval time.Duration `json:"jwks_refresh_interval" envconfig:"JWKS_REFRESH_INTERVAL" default:"10m"`
+	Audience            string        `json:"audience"`
+	Issuer              string        `json:"issuer"`
+	// RevocationCacheTTL is how long a token is trusted after its revocation
+	// status was last looked up, so revocations on other replicas take up to
+	// this long to apply.
+	RevocationCacheTTL time.Duration `json:"revocation_cache_ttl" envconfig:"REVOCATION_CACHE_TTL" default:"30s"`
+	// RevocationRetention must exceed the longest access token lifetime.
+	RevocationRetention time.Duration `json:"revocation_retention" envconfig:"REVOCATION_RETENTION" default:"24h"`
+}
+
+func (c *JWTConfiguration) Validate() error {
+	if c.Secret == "" && len(c.Secrets) == 0 && c.JWKSURL == "" && c.JWKSFile == "" {
+		return errors.New("a JWT secret, JWKS URL or JWKS file is required")
+	}
+
+	if c.JWKSURL != "" && c.JWKSFile != "" {
+		return errors.New("only one of JWT JWKS URL and JWKS file can be set")
+	}
+
+	if c.JWKSRefreshInterval <= 0 {
+		return errors.New("JWKS refresh interval must be positive")
+	}
+
+	if c.RevocationRetention <= 0 {
+		return errors.New("JWT revocation retention must be positive")
+	}
+
+	return nil
+}
+
+// AuthConfiguration enables the built-in email/password auth, for running
+// without Supabase. Tokens are signed with JWT.Secret.
+type AuthConfiguration struct {
+	Enabled         bool          `json:"enabled"`
+	AutoConfirm     bool          `json:"auto_confirm" split_words:"true"`
+	AccessTokenTTL  time.Duration `json:"access_token_ttl" envconfig:"ACCESS_TOKEN_TTL" default:"1h"`
+	RefreshTokenTTL time.Duration `json:"refresh_token_ttl" envconfig:"REFRESH_TOKEN_TTL" default:"720h"`
+	ConfirmationTTL time.Duration `json:"confirmation_ttl" envconfig:"CONFIRMATION_TTL" default:"24h"`
+	RecoveryTTL     time.Duration `json:"recovery_ttl" envconfig:"RECOVERY_TTL" default:"1h"`
+}
+
+func (c *AuthConfiguration) Validate() error {
+	if c.Enabled && (c.AccessTokenTTL <= 0 || c.RefreshTokenTTL <= 0) {
+		return errors.New("auth token lifetimes must be positive")
+	}
+
+	return nil
+}
+
+// SMTPConfiguration is used to send auth emails. Without a host, emails are
+// not sent, and only logged in full when LogEmails is set for local
+// development: they carry confirmation and recovery tokens.
+type SMTPConfiguration struct {
+	Host      string `json:"host"`
+	Port      string `json:"port" default:"587"`
+	User      string `json:"user"`
+	Pass      string `json:"pass"`
+	Sender    string `json:"sender" default:"no-reply@localhost"`
+	LogEmails bool   `json:"log_emails" split_words:"true"`
+}
+
+// WebhookConfiguration controls delivery of outbound webhooks by the workers.
+type WebhookConfiguration struct {
+	Enabled      bool          `json:"enabled" default:"true"`
+	PollInterval time.Duration `json:"poll_interval" split_words:"true" default:"5s"`
+	BatchSize    int           `json:"batch_size" split_words:"true" default:"20"`
+	Timeout      time.Duration `json:"timeout" default:"10s"`
+	MaxAttempts  int           `json:"max_attempts" split_words:"true" default:"8"`
+	BackoffBase  time.Duration `json:"backoff_base" split_words:"true" default:"30s"`
+	BackoffMax   time.Duration `json:"backoff_max" split_words:"true" default:"6h"`
+	// AllowPrivateTargets lets endpoints resolve to loopback and private
+	// addresses, which is only safe for local development.
+	AllowPrivateTargets bool `json:"allow_private_targets" split_words:"true"`
+	// Retention is how long finished deliveries stay in the delivery log.
+	Retention time.Duration `json:"retention" default:"720h"`
+}
+
+// JobsConfiguration controls the background job workers. Enabled only
+// affects serve; dp worker always runs them.
+type JobsConfiguration struct {
+	Enabled      bool          `json:"enabled" default:"true"`
+	Concurrency  int           `json:"concurrency" default:"4"`
+	PollInterval time.Duration `json:"poll_interval" split_words:"true" default:"1s"`
+	// LockTimeout bounds how long a job may run. A job still locked after it,
+	// e.g. because its worker died, is picked up again.
+	LockTimeout time.Duration `json:"lock_timeout" split_words:"true" default:"5m"`
+	MaxAttempts int           `json:"max_attempts" split_words:"true" default:"10"`
+	BackoffBase time.Duration `json:"backoff_base" split_words:"true" default:"10s"`
+	BackoffMax  time.Duration `json:"backoff_max" split_words:"true" default:"1h"`
+	// Retention is how long succeeded and dead jobs are kept.
+	Retention time.Duration `json:"retention" default:"168h"`
+	// ScoreInterval is how often the trending and top scores of blogs and
+	// portfolios are recomputed.
+	ScoreInterval time.Duration `json:"score_interval" split_words:"true" default:"15m"`
+}
+
+func (c *JobsConfiguration) Validate() error {
+	if c.Concurrency <= 0 || c.PollInterval <= 0 || c.LockTimeout <= 0 || c.MaxAttempts <= 0 || c.ScoreInterval <= 0 {
+		return errors.New("jobs concurrency, poll interval, lock timeout, max attempts and score interval must be positive")
+	}
+
+	return nil
+}
+
+// AnalyticsConfiguration controls view counting. Views are de-duplicated
+// and buffered in memory, then written every FlushInterval.
+type AnalyticsConfiguration struct {
+	Enabled       bool          `json:"enabled" default:"true"`
+	FlushInterval time.Duration `json:"flush_interval" split_words:"true" default:"10s"`
+	// MaxBuffered bounds the views held between flushes; more are dropped.
+	MaxBuffered int `json:"max_buffered" split_words:"true" default:"10000"`
+	// Retention is how long individual views are kept.
+	Retention time.Duration `json:"retention" default:"8760h"`
+	// GeoIPDatabase is the path of a MaxMind DB file, such as
+	// GeoLite2-Country.mmdb, to record the country of portfolio visits.
+	GeoIPDatabase string `json:"geoip_database" envconfig:"GEOIP_DATABASE"`
+}
+
+func (c *AnalyticsConfiguration) Validate() error {
+	if c.Enabled && (c.FlushInterval <= 0 || c.MaxBuffered <= 0) {
+		return errors.New("analytics flush interval and max buffered must be positive")
+	}
+
+	return nil
+}
+
+func (c *WebhookConfiguration) Validate() error {
+	if c.Enabled && (c.PollInterval <= 0 || c.BatchSize <= 0 || c.MaxAttempts <= 0) {
+		return errors.New("webhook poll interval, batch size and max attempts must be positive")
+	}
+
+	return nil
+}
+
+type CacheConfiguration struct {
+	Enabled bool          `json:"enabled" default:"true"`
+	Size    int           `json:"size" default:"1000"`
+	TTL     time.Duration `json:"ttl" default:"60s"`
+}
+
+func (c *CacheConfiguration) Validate() error {
+	if c.Enabled && c.Size <= 0 {
+		return errors.New("cache size must be positive when the cache is enabled")
+	}
+
+	return nil
 }
 
 type CORSConfiguration struct {
@@ -74,12 +239,19 @@ func (c *CORSConfiguration) AllAllowedHeaders(defaults []string) []string {
 }
 
 type GlobalConfiguration struct {
-	API     APIConfiguration
-	DB      DBConfiguration   `json:"db"`
-	CORS    CORSConfiguration `json:"cors"`
-	JWT     JWTConfiguration  `json:"jwt" envconfig:"JWT"`
-	LOGGING LoggingConfig     `envconfig:"LOG"`
-	AWS     AWSConfiguration
+	API       APIConfiguration
+	DB        DBConfiguration   `json:"db"`
+	CORS      CORSConfiguration `json:"cors"`
+	JWT       JWTConfiguration  `json:"jwt" envconfig:"JWT"`
+	LOGGING   LoggingConfig     `envconfig:"LOG"`
+	Tracing   TracingConfig     `json:"tracing"`
+	AWS       AWSConfiguration
+	Cache     CacheConfiguration     `json:"cache"`
+	Auth      AuthConfiguration      `json:"auth"`
+	SMTP      SMTPConfiguration      `json:"smtp" envconfig:"SMTP"`
+	Webhook   WebhookConfiguration   `json:"webhook"`
+	Jobs      JobsConfiguration      `json:"jobs"`
+	Analytics AnalyticsConfiguration `json:"analytics"`
 
 	SiteURL         string   `json:"site_url" split_words:"true" required:"true"`
 	URIAllowList    []string `json:"uri_allow_list" split_words:"true"`
@@ -156,7 +328,14 @@ func (c *GlobalConfiguration) Validate() error {
 	}{
 		&c.API,
 		&c.DB,
+		&c.JWT,
 		&c.LOGGING,
+		&c.Tracing,
+		&c.Cache,
+		&c.Auth,
+		&c.Webhook,
+		&c.Jobs,
+		&c.Analytics,
 	}
 
 	for _, validatable := range validatables {
@@ -165,6 +344,10 @@ func (c *GlobalConfiguration) Validate() error {
 		}
 	}
 
+	if c.Auth.Enabled && c.JWT.Secret == "" {
+		return errors.New("the built-in auth signs tokens with the JWT secret, which is not set")
+	}
+
 	return nil
 }
 
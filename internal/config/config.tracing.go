@@ -0,0 +1,31 @@
+package config
+
+import "fmt"
+
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// TracingConfig configures OpenTelemetry tracing. The OTLP/HTTP exporter also
+// honours the standard OTEL_EXPORTER_OTLP_* variables when Endpoint is empty.
+type TracingConfig struct {
+	Enabled     bool   `json:"enabled"`
+	Exporter    string `json:"exporter" default:"otlp"`
+	Endpoint    string `json:"endpoint"`
+	Insecure    bool   `json:"insecure"`
+	ServiceName string `json:"service_name" split_words:"true" default:"dynamic-portfolio-api"`
+}
+
+func (c *TracingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch c.Exporter {
+	case TracingExporterOTLP, TracingExporterStdout:
+		return nil
+	default:
+		return fmt.Errorf("unsupported tracing exporter %q", c.Exporter)
+	}
+}
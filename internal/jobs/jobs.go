@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// HandlerFunc runs one attempt of a job. Returning an error retries the job
+// with backoff until it runs out of attempts.
+type HandlerFunc func(ctx context.Context, job *models.Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not worth retrying, so the job is moved to the
+// dead-letter state right away.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type schedule struct {
+	jobType string
+	every   time.Duration
+}
+
+// Registry maps job types to their handlers and holds the recurring jobs.
+type Registry struct {
+	handlers  map[string]HandlerFunc
+	schedules []schedule
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers: map[string]HandlerFunc{},
+	}
+}
+
+// Handle registers fn for jobType, decoding the job payload into T. A
+// payload that doesn't decode is never retried.
+func Handle[T any](r *Registry, jobType string, fn func(ctx context.Context, payload T) error) {
+	r.handlers[jobType] = func(ctx context.Context, job *models.Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("invalid payload: %w", err))
+		}
+
+		return fn(ctx, payload)
+	}
+}
+
+// Schedule enqueues jobType, with an empty payload, once every interval
+// across all workers.
+func (r *Registry) Schedule(jobType string, every time.Duration) {
+	r.schedules = append(r.schedules, schedule{jobType: jobType, every: every})
+}
+
+type Option func(job *models.Job)
+
+// RunAt delays the job until t.
+func RunAt(t time.Time) Option {
+	return func(job *models.Job) {
+		job.RunAt = t
+	}
+}
+
+func MaxAttempts(n int) Option {
+	return func(job *models.Job) {
+		job.MaxAttempts = &n
+	}
+}
+
+// UniqueKey drops the job if a job with the same key was ever enqueued and
+// hasn't been cleaned up yet.
+func UniqueKey(key string) Option {
+	return func(job *models.Job) {
+		job.UniqueKey = &key
+	}
+}
+
+// Enqueue adds a job. Pass the transaction of the write the job belongs to,
+// so the job is only run if that write is committed.
+func Enqueue(tx *gorm.DB, jobType string, payload any, opts ...Option) error {
+	if jobType == "" {
+		return errors.New("job type is required")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	job := models.Job{
+		Type:    jobType,
+		Payload: data,
+		Status:  models.JobPending,
+		RunAt:   time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	return repositories.NewJobRepository(tx).Create(&job)
+}
@@ -0,0 +1,206 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// scheduleInterval is how often the scheduled jobs are checked for being
+// due, which bounds how late they can start.
+const scheduleInterval = time.Minute
+
+// Runner runs jobs with Concurrency workers. Jobs are claimed with SKIP
+// LOCKED, so any number of runners can share the database.
+type Runner struct {
+	db       *gorm.DB
+	config   *config.JobsConfiguration
+	registry *Registry
+}
+
+func NewRunner(db *gorm.DB, config *config.JobsConfiguration, registry *Registry) *Runner {
+	return &Runner{
+		db:       db,
+		config:   config,
+		registry: registry,
+	}
+}
+
+// Run blocks, running jobs until ctx is done and the jobs in progress have
+// returned.
+func (r *Runner) Run(ctx context.Context) {
+	log := logrus.WithField("component", "jobs")
+	log.WithField("concurrency", r.config.Concurrency).Info("job workers started")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < r.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r.work(ctx)
+		}()
+	}
+
+	if len(r.registry.schedules) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r.schedule(ctx)
+		}()
+	}
+
+	wg.Wait()
+	log.Info("job workers stopped")
+}
+
+func (r *Runner) work(ctx context.Context) {
+	for {
+		ran, err := r.RunNext(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logrus.WithError(err).WithField("component", "jobs").Error("unable to claim job")
+		}
+
+		// Keep going while there is work, otherwise wait for more.
+		if ran && err == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// RunNext claims and runs one due job, and reports whether there was one.
+func (r *Runner) RunNext(ctx context.Context) (bool, error) {
+	repository := repositories.NewJobRepository(r.db.WithContext(ctx))
+
+	jobs, err := repository.Claim(1, r.config.LockTimeout)
+	if err != nil || len(jobs) == 0 {
+		return false, err
+	}
+	job := &jobs[0]
+
+	log := logrus.WithFields(logrus.Fields{
+		"component": "jobs",
+		"job_id":    job.ID,
+		"job_type":  job.Type,
+		"attempt":   job.Attempts,
+	})
+
+	// The outcome is recorded even when shutting down, so the job isn't
+	// left locked until its lease runs out.
+	recordCtx := context.WithoutCancel(ctx)
+	repository = repositories.NewJobRepository(r.db.WithContext(recordCtx))
+
+	start := time.Now()
+	err = r.run(ctx, job)
+	observability.JobDuration.WithLabelValues(job.Type).Observe(time.Since(start).Seconds())
+
+	var status models.JobStatus
+	var recorded int64
+	switch {
+	case err == nil:
+		status = models.JobSucceeded
+		recorded, err = repository.Complete(job)
+
+	case r.exhausted(job, err):
+		status = models.JobDead
+		log.WithError(err).Error("job failed, moving it to the dead-letter state")
+		recorded, err = repository.Bury(job, err.Error())
+
+	default:
+		status = models.JobPending
+		runAt := time.Now().Add(utilities.Backoff(r.config.BackoffBase, r.config.BackoffMax, job.Attempts))
+		log.WithError(err).WithField("retry_at", runAt).Warn("job failed, retrying")
+		recorded, err = repository.Retry(job, runAt, err.Error())
+	}
+	if err != nil {
+		return true, err
+	}
+
+	// The job ran past its lease and another worker claimed it, so the
+	// outcome is left to that worker.
+	if recorded == 0 {
+		log.WithField("outcome", status).Warn("job lease expired before it finished, discarding its outcome")
+		return true, nil
+	}
+
+	observability.JobsProcessed.WithLabelValues(job.Type, string(status)).Inc()
+	return true, nil
+}
+
+func (r *Runner) run(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := r.registry.handlers[job.Type]
+	if !ok {
+		// Possibly enqueued by a newer release, so let it be retried
+		// instead of burying it.
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.LockTimeout)
+	defer cancel()
+
+	return handler(ctx, job)
+}
+
+func (r *Runner) exhausted(job *models.Job, err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+
+	maxAttempts := r.config.MaxAttempts
+	if job.MaxAttempts != nil {
+		maxAttempts = *job.MaxAttempts
+	}
+
+	return job.Attempts >= maxAttempts
+}
+
+// schedule enqueues every scheduled job once per interval. The unique key
+// names the interval, so runners racing for the same one enqueue it once.
+func (r *Runner) schedule(ctx context.Context) {
+	for {
+		now := time.Now()
+		for _, s := range r.registry.schedules {
+			slot := now.Truncate(s.every)
+			key := s.jobType + "@" + strconv.FormatInt(slot.Unix(), 10)
+
+			if err := Enqueue(r.db.WithContext(ctx), s.jobType, struct{}{}, RunAt(slot), UniqueKey(key)); err != nil && !errors.Is(err, context.Canceled) {
+				logrus.WithError(err).WithField("job_type", s.jobType).Error("unable to schedule job")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(scheduleInterval):
+		}
+	}
+}
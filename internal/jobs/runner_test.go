@@ -0,0 +1,392 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var testConfig = config.JobsConfiguration{
+	LockTimeout: time.Minute,
+	MaxAttempts: 3,
+	BackoffBase: 10 * time.Second,
+	BackoffMax:  time.Hour,
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestRunNext(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		job       models.Job
+		handler   HandlerFunc
+		status    models.JobStatus
+		lastError string
+		retried   bool
+	}{
+		{
+			name:    "succeeds",
+			job:     models.Job{Type: "test"},
+			handler: func(ctx context.Context, job *models.Job) error { return nil },
+			status:  models.JobSucceeded,
+		},
+		{
+			name:      "fails and is retried",
+			job:       models.Job{Type: "test"},
+			handler:   func(ctx context.Context, job *models.Job) error { return errFailed },
+			status:    models.JobPending,
+			lastError: "failed",
+			retried:   true,
+		},
+		{
+			name:      "fails its last attempt",
+			job:       models.Job{Type: "test", Attempts: 2},
+			handler:   func(ctx context.Context, job *models.Job) error { return errFailed },
+			status:    models.JobDead,
+			lastError: "failed",
+		},
+		{
+			name:      "fails with more attempts of its own",
+			job:       models.Job{Type: "test", Attempts: 2, MaxAttempts: intPtr(5)},
+			handler:   func(ctx context.Context, job *models.Job) error { return errFailed },
+			status:    models.JobPending,
+			lastError: "failed",
+			retried:   true,
+		},
+		{
+			name:      "fails permanently",
+			job:       models.Job{Type: "test"},
+			handler:   func(ctx context.Context, job *models.Job) error { return Permanent(errFailed) },
+			status:    models.JobDead,
+			lastError: "failed",
+		},
+		{
+			name:      "panics",
+			job:       models.Job{Type: "test"},
+			handler:   func(ctx context.Context, job *models.Job) error { panic("boom") },
+			status:    models.JobPending,
+			lastError: "job panicked: boom",
+			retried:   true,
+		},
+		{
+			name:      "has no handler",
+			job:       models.Job{Type: "unknown"},
+			status:    models.JobPending,
+			lastError: `no handler for job type "unknown"`,
+			retried:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, state := openFakeDB(t)
+			state.add(tt.job)
+
+			registry := NewRegistry()
+			if tt.handler != nil {
+				registry.handlers["test"] = tt.handler
+			}
+			runner := NewRunner(db, &testConfig, registry)
+
+			start := time.Now()
+			ran, err := runner.RunNext(context.Background())
+			if !ran || err != nil {
+				t.Fatalf("RunNext() = %v, %v, want true, nil", ran, err)
+			}
+
+			job := state.jobs[0]
+			if job.Status != tt.status {
+				t.Errorf("status = %s, want %s", job.Status, tt.status)
+			}
+			if job.Attempts != tt.job.Attempts+1 {
+				t.Errorf("attempts = %d, want %d", job.Attempts, tt.job.Attempts+1)
+			}
+			if job.LockedUntil != nil {
+				t.Errorf("locked until %v, want unlocked", job.LockedUntil)
+			}
+
+			var lastError string
+			if job.LastError != nil {
+				lastError = *job.LastError
+			}
+			if lastError != tt.lastError {
+				t.Errorf("last error = %q, want %q", lastError, tt.lastError)
+			}
+
+			if tt.retried {
+				backoff := utilities.Backoff(testConfig.BackoffBase, testConfig.BackoffMax, job.Attempts)
+				if job.RunAt.Before(start.Add(backoff)) || job.RunAt.After(time.Now().Add(backoff)) {
+					t.Errorf("retried at %v, want %v after the attempt", job.RunAt, backoff)
+				}
+			}
+
+			if finished := job.FinishedAt != nil; finished != (tt.status != models.JobPending) {
+				t.Errorf("finished at %v with status %s", job.FinishedAt, job.Status)
+			}
+		})
+	}
+}
+
+func TestRunNextInvalidPayload(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.add(models.Job{Type: "test", Payload: []byte(`"not an object"`)})
+
+	registry := NewRegistry()
+	Handle(registry, "test", func(ctx context.Context, payload struct{ ID int }) error {
+		t.Error("handler called with an invalid payload")
+		return nil
+	})
+
+	if _, err := NewRunner(db, &testConfig, registry).RunNext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if job := state.jobs[0]; job.Status != models.JobDead {
+		t.Errorf("status = %s, want %s", job.Status, models.JobDead)
+	}
+}
+
+func TestRunNextLostLease(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.add(models.Job{Type: "test"})
+
+	registry := NewRegistry()
+	registry.handlers["test"] = func(ctx context.Context, job *models.Job) error {
+		// The lease ran out and another worker claimed the job.
+		state.mu.Lock()
+		state.jobs[0].Attempts++
+		state.mu.Unlock()
+
+		return errors.New("failed")
+	}
+
+	ran, err := NewRunner(db, &testConfig, registry).RunNext(context.Background())
+	if !ran || err != nil {
+		t.Fatalf("RunNext() = %v, %v, want true, nil", ran, err)
+	}
+
+	job := state.jobs[0]
+	if job.Status != models.JobRunning || job.LastError != nil || job.LockedUntil == nil {
+		t.Errorf("job = %+v, want it left to the newer claim", job)
+	}
+}
+
+func TestRunNextNoJob(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.add(models.Job{Type: "test", RunAt: time.Now().Add(time.Hour)})
+	state.add(models.Job{Type: "test", Status: models.JobDead})
+
+	ran, err := NewRunner(db, &testConfig, NewRegistry()).RunNext(context.Background())
+	if ran || err != nil {
+		t.Errorf("RunNext() = %v, %v, want false, nil", ran, err)
+	}
+}
+
+var (
+	fakeRegister sync.Once
+	fakeStates   sync.Map
+)
+
+// fakeState is a jobs table answering the statements of the job repository.
+type fakeState struct {
+	mu   sync.Mutex
+	jobs []models.Job
+}
+
+// add inserts a job, pending and due unless set otherwise.
+func (s *fakeState) add(job models.Job) {
+	job.ID = uint(len(s.jobs) + 1)
+	if job.Status == "" {
+		job.Status = models.JobPending
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if job.Payload == nil {
+		job.Payload = []byte("{}")
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+func openFakeDB(t *testing.T) (*gorm.DB, *fakeState) {
+	fakeRegister.Do(func() { sql.Register("jobsfake", fakeDriver{}) })
+
+	state := &fakeState{}
+	fakeStates.Store(t.Name(), state)
+	t.Cleanup(func() { fakeStates.Delete(t.Name()) })
+
+	sqlDB, err := sql.Open("jobsfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, state
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	state, ok := fakeStates.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("no fake database %q", name)
+	}
+	return &fakeConn{state: state.(*fakeState)}, nil
+}
+
+type fakeConn struct {
+	state *fakeState
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error { return nil }
+
+func (c *fakeConn) Rollback() error { return nil }
+
+var (
+	finishPattern     = regexp.MustCompile(`^UPDATE "jobs" SET (.+) WHERE id = \$(\d+) and status = \$(\d+) and attempts = \$(\d+)$`)
+	assignmentPattern = regexp.MustCompile(`"(\w+)"=(\$\d+|now\(\))`)
+)
+
+func arg(args []driver.NamedValue, placeholder string) driver.Value {
+	n, _ := strconv.Atoi(strings.TrimPrefix(placeholder, "$"))
+	return args[n-1].Value
+}
+
+// ExecContext records the outcome of a job, as Complete, Retry and Bury do.
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match := finishPattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+
+	id, status, attempts := arg(args, "$"+match[2]), arg(args, "$"+match[3]), arg(args, "$"+match[4])
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		if int64(job.ID) != id.(int64) || string(job.Status) != status.(string) || int64(job.Attempts) != attempts.(int64) {
+			continue
+		}
+
+		for _, assignment := range assignmentPattern.FindAllStringSubmatch(match[1], -1) {
+			var value driver.Value = time.Now()
+			if assignment[2] != "now()" {
+				value = arg(args, assignment[2])
+			}
+
+			switch assignment[1] {
+			case "status":
+				job.Status = models.JobStatus(value.(string))
+			case "run_at":
+				job.RunAt = value.(time.Time)
+			case "locked_until":
+				job.LockedUntil = nil
+			case "last_error":
+				lastError := value.(string)
+				job.LastError = &lastError
+			case "finished_at":
+				finishedAt := value.(time.Time)
+				job.FinishedAt = &finishedAt
+			case "updated_at":
+				job.UpdatedAt = value.(time.Time)
+			default:
+				return nil, fmt.Errorf("unexpected column %q", assignment[1])
+			}
+		}
+		return driver.RowsAffected(1), nil
+	}
+
+	return driver.RowsAffected(0), nil
+}
+
+// QueryContext claims jobs, as Claim does.
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.Contains(query, "SET status = 'running'") {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+
+	lease := time.Duration(args[0].Value.(float64) * float64(time.Second))
+	limit := args[1].Value.(int64)
+
+	rows := &fakeRows{columns: []string{"id", "type", "payload", "status", "attempts", "max_attempts", "run_at", "locked_until", "last_error"}}
+	now := time.Now()
+	for i := range s.jobs {
+		job := &s.jobs[i]
+		due := job.Status == models.JobPending && !job.RunAt.After(now)
+		expired := job.Status == models.JobRunning && job.LockedUntil != nil && job.LockedUntil.Before(now)
+		if int64(len(rows.values)) == limit || !(due || expired) {
+			continue
+		}
+
+		lockedUntil := now.Add(lease)
+		job.Status = models.JobRunning
+		job.Attempts++
+		job.LockedUntil = &lockedUntil
+
+		var maxAttempts, lastError driver.Value
+		if job.MaxAttempts != nil {
+			maxAttempts = int64(*job.MaxAttempts)
+		}
+		if job.LastError != nil {
+			lastError = *job.LastError
+		}
+		rows.values = append(rows.values, []driver.Value{int64(job.ID), job.Type, []byte(job.Payload), string(job.Status), int64(job.Attempts), maxAttempts, job.RunAt, lockedUntil, lastError})
+	}
+
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
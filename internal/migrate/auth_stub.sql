@@ -0,0 +1,43 @@
+-- Minimal stand-in for the Supabase auth schema, for local databases only.
+
+create schema if not exists auth;
+
+create table if not exists auth.users (
+    id uuid not null default gen_random_uuid(),
+    instance_id uuid null,
+    aud text null,
+    role text null,
+    email text null,
+    encrypted_password text null,
+    confirmation_token text null,
+    recovery_token text null,
+    last_sign_in_at timestamp with time zone null,
+    email_change text null,
+    email_change_token_new text null,
+    email_confirmed_at timestamp with time zone null,
+    phone_change text null,
+    phone_change_token text null,
+    email_change_token_current text null,
+    email_change_confirm_status smallint null default 0,
+    reauthentication_token text null,
+    raw_app_meta_data jsonb null,
+    raw_user_meta_data jsonb null,
+    created_at timestamp with time zone null default now(),
+    updated_at timestamp with time zone null default now(),
+    deleted_at timestamp with time zone null,
+    constraint users_pkey primary key (id)
+);
+
+create table if not exists auth.identities (
+    id uuid not null default gen_random_uuid(),
+    user_id uuid not null,
+    provider_id text not null,
+    provider text not null,
+    identity_data jsonb not null,
+    last_sign_in_at timestamp with time zone null,
+    created_at timestamp with time zone null default now(),
+    updated_at timestamp with time zone null default now(),
+    constraint identities_pkey primary key (id),
+    constraint identities_provider_id_provider_unique unique (provider_id, provider),
+    constraint identities_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade
+);
@@ -0,0 +1,457 @@
+// Package migrate applies the versioned SQL migrations embedded in the binary
+// and records them, with checksums, in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockKey identifies the advisory lock held while migrating so that pods
+// starting together don't apply the same migration twice.
+const lockKey int64 = 7_384_201_556
+
+var (
+	fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	nameRegexp     = regexp.MustCompile(`^\w+$`)
+)
+
+//go:embed auth_stub.sql
+var authStubSQL string
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+	// Replaced lists earlier checksums of the up file that are still
+	// accepted for databases that applied it before it was corrected.
+	Replaced []string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	Dirty     bool
+	// Modified is set when the applied checksum differs from the file's.
+	Modified bool
+}
+
+// Load reads the migrations in the root of fsys, ordered by version.
+// replaced maps a version to the checksums its up file had before it was
+// corrected, which applied databases may still record.
+func Load(fsys fs.FS, replaced map[int64][]string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migration.Replaced = replaced[migration.Version]
+		migrations = append(migrations, *migration)
+	}
+	for version := range replaced {
+		if _, ok := byVersion[version]; !ok {
+			return nil, fmt.Errorf("replaced checksums listed for unknown migration %d", version)
+		}
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// accepts reports whether checksum, as recorded when the migration was
+// applied, matches the file or one of its replaced versions.
+func (m Migration) accepts(checksum string) bool {
+	if checksum == m.Checksum {
+		return true
+	}
+	for _, replaced := range m.Replaced {
+		if checksum == replaced {
+			return true
+		}
+	}
+	return false
+}
+
+// Create writes an empty up and down migration named name to dir.
+func Create(dir string, name string) ([]string, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q, use letters, digits and underscores", name)
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+	}
+
+	return files, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+type appliedMigration struct {
+	dirty     bool
+	checksum  sql.NullString
+	appliedAt time.Time
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func loadApplied(ctx context.Context, q querier) (map[int64]appliedMigration, error) {
+	var table sql.NullString
+	if err := q.QueryRowContext(ctx, "select to_regclass('schema_migrations')::text").Scan(&table); err != nil {
+		return nil, err
+	}
+	if !table.Valid {
+		return map[int64]appliedMigration{}, nil
+	}
+
+	rows, err := q.QueryContext(ctx, "select version, dirty, checksum, applied_at from schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var version int64
+		var m appliedMigration
+		if err := rows.Scan(&version, &m.dirty, &m.checksum, &m.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = m
+	}
+
+	return applied, rows.Err()
+}
+
+// Status lists every known migration with whether it has been applied.
+// Migrations recorded in the database but unknown to this binary are
+// included too, with an empty Name.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := loadApplied(ctx, m.db)
+	if err != nil {
+		if isUndefinedColumn(err) {
+			return nil, errors.New("schema_migrations uses the legacy layout, run dp migrate up to upgrade it")
+		}
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if a, ok := applied[migration.Version]; ok {
+			appliedAt := a.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Dirty = a.dirty
+			status.Modified = a.checksum.Valid && !migration.accepts(a.checksum.String)
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, a := range applied {
+		appliedAt := a.appliedAt
+		statuses = append(statuses, MigrationStatus{Migration: Migration{Version: version}, Applied: true, AppliedAt: &appliedAt, Dirty: a.dirty})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Check returns an error unless the database is at the known migrations:
+// when a migration is dirty, was modified after it was applied, or is
+// pending.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		switch {
+		case status.Dirty:
+			return fmt.Errorf("migration %d is dirty, fix the database by hand and clear the flag", status.Version)
+		case status.Modified:
+			return fmt.Errorf("migration %d_%s was modified after it was applied", status.Version, status.Name)
+		case !status.Applied:
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("%d migrations are pending, run dp migrate up", pending)
+	}
+
+	return nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the steps most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.rollback(ctx, conn, m.migrations[i]); err != nil {
+				return err
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// To applies or rolls back migrations until version is the latest applied
+// one. Version 0 rolls back everything.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.rollback(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// CreateAuthStub creates a minimal auth schema with the users and identities
+// tables the migrations and seeds reference, for databases not provisioned by
+// Supabase. It does nothing to tables that already exist.
+func (m *Migrator) CreateAuthStub(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, authStubSQL)
+	return err
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory
+// lock, after making sure schema_migrations exists and is consistent.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]appliedMigration) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "select pg_advisory_lock($1)", lockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "select pg_advisory_unlock($1)", lockKey)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for version, a := range applied {
+		if a.dirty {
+			return fmt.Errorf("migration %d is dirty, fix the database by hand and clear the flag", version)
+		}
+		if migration := m.find(version); migration != nil && !migration.accepts(a.checksum.String) {
+			return fmt.Errorf("migration %d_%s was modified after it was applied", version, migration.Name)
+		}
+	}
+
+	return fn(conn, applied)
+}
+
+// ensureTable creates schema_migrations, or upgrades the single row table
+// golang-migrate keeps to one row per applied migration.
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	statements := []string{
+		`create table if not exists schema_migrations (
+			version bigint primary key,
+			dirty boolean not null default false
+		)`,
+		`alter table schema_migrations
+			add column if not exists checksum text,
+			add column if not exists applied_at timestamp with time zone not null default now()`,
+	}
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	// Rows written by golang-migrate have no checksum and only record the
+	// latest version, which implies every earlier one.
+	var legacyVersion sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "select max(version) from schema_migrations where checksum is null").Scan(&legacyVersion); err != nil {
+		return err
+	}
+	if !legacyVersion.Valid {
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, migration := range m.migrations {
+		if migration.Version > legacyVersion.Int64 {
+			break
+		}
+		if _, err := tx.ExecContext(ctx, `
+			insert into schema_migrations (version, checksum) values ($1, $2)
+			on conflict (version) do update set checksum = excluded.checksum where schema_migrations.checksum is null
+		`, migration.Version, migration.Checksum); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("applying %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "insert into schema_migrations (version, checksum) values ($1, $2)", migration.Version, migration.Checksum); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) rollback(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("rolling back %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "delete from schema_migrations where version = $1", migration.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isUndefinedColumn(err error) bool {
+	var sqlState interface{ SQLState() string }
+	return errors.As(err, &sqlState) && sqlState.SQLState() == "42703"
+}
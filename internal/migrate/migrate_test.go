@@ -0,0 +1,604 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"2_second.up.sql":   {Data: []byte("create table b ();")},
+		"2_second.down.sql": {Data: []byte("drop table b;")},
+		"1_first.up.sql":    {Data: []byte("create table a ();")},
+		"1_first.down.sql":  {Data: []byte("drop table a;")},
+		"README.md":         {Data: []byte("not a migration")},
+		"migrations.go":     {Data: []byte("package migrations")},
+	}
+
+	migrations, err := Load(fsys, map[int64][]string{2: {"old"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "first", Up: "create table a ();", Down: "drop table a;", Checksum: checksum("create table a ();")},
+		{Version: 2, Name: "second", Up: "create table b ();", Down: "drop table b;", Checksum: checksum("create table b ();"), Replaced: []string{"old"}},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("got %d migrations, want %d", len(migrations), len(want))
+	}
+	for i := range want {
+		got := migrations[i]
+		if got.Version != want[i].Version || got.Name != want[i].Name || got.Up != want[i].Up || got.Down != want[i].Down || got.Checksum != want[i].Checksum || !slices.Equal(got.Replaced, want[i].Replaced) {
+			t.Errorf("migration %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		replaced map[int64][]string
+		want     string
+	}{
+		{
+			name: "no up file",
+			fsys: fstest.MapFS{"1_first.down.sql": {Data: []byte("drop table a;")}},
+			want: "migration 1_first has no up file",
+		},
+		{
+			name: "two names",
+			fsys: fstest.MapFS{
+				"1_first.up.sql":   {Data: []byte("create table a ();")},
+				"1_other.down.sql": {Data: []byte("drop table a;")},
+			},
+			want: "migration 1 has two names",
+		},
+		{
+			name:     "unknown replaced version",
+			fsys:     fstest.MapFS{"1_first.up.sql": {Data: []byte("create table a ();")}},
+			replaced: map[int64][]string{2: {"old"}},
+			want:     "replaced checksums listed for unknown migration 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys, tt.replaced)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationAccepts(t *testing.T) {
+	migration := Migration{Checksum: "current", Replaced: []string{"first", "second"}}
+
+	tests := []struct {
+		checksum string
+		want     bool
+	}{
+		{"current", true},
+		{"first", true},
+		{"second", true},
+		{"other", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := migration.accepts(tt.checksum); got != tt.want {
+			t.Errorf("accepts(%q) = %v, want %v", tt.checksum, got, tt.want)
+		}
+	}
+}
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "first", Up: "up 1", Down: "down 1", Checksum: checksum("up 1")},
+	{Version: 2, Name: "second", Up: "up 2", Down: "down 2", Checksum: checksum("up 2"), Replaced: []string{checksum("old up 2")}},
+	{Version: 3, Name: "third", Up: "up 3", Down: "down 3", Checksum: checksum("up 3")},
+}
+
+func TestMigratorUp(t *testing.T) {
+	db, state := openFakeDB(t)
+
+	if err := NewMigrator(db, testMigrations).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"up 1", "up 2", "up 3"}; !slices.Equal(state.executed, want) {
+		t.Errorf("executed %v, want %v", state.executed, want)
+	}
+	for _, migration := range testMigrations {
+		if row, ok := state.rows[migration.Version]; !ok || row.checksum == nil || *row.checksum != migration.Checksum {
+			t.Errorf("migration %d recorded as %+v", migration.Version, row)
+		}
+	}
+	if state.locks != 0 {
+		t.Errorf("advisory lock held %d times after Up", state.locks)
+	}
+
+	// Everything is applied, so running it again does nothing.
+	state.executed = nil
+	if err := NewMigrator(db, testMigrations).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.executed) != 0 {
+		t.Errorf("second Up executed %v", state.executed)
+	}
+}
+
+func TestMigratorUpFailure(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.failOn = "up 2"
+
+	err := NewMigrator(db, testMigrations).Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "applying 2_second") {
+		t.Fatalf("Up() error = %v, want the failure of 2_second", err)
+	}
+
+	if _, ok := state.rows[1]; !ok {
+		t.Error("migration 1 was not recorded")
+	}
+	if _, ok := state.rows[2]; ok {
+		t.Error("failed migration 2 was recorded")
+	}
+	if _, ok := state.rows[3]; ok {
+		t.Error("migration 3 was applied after a failure")
+	}
+}
+
+func TestMigratorRefusesInconsistentTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		dirty bool
+		sum   string
+		want  string
+	}{
+		{name: "dirty", dirty: true, sum: checksum("up 2"), want: "migration 2 is dirty"},
+		{name: "modified", sum: checksum("edited up 2"), want: "migration 2_second was modified after it was applied"},
+		{name: "replaced", sum: checksum("old up 2")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, state := openFakeDB(t)
+			state.table = true
+			state.rows[1] = &fakeRow{checksum: strPtr(checksum("up 1"))}
+			state.rows[2] = &fakeRow{dirty: tt.dirty, checksum: strPtr(tt.sum)}
+
+			err := NewMigrator(db, testMigrations).Up(context.Background())
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Up() error = %v", err)
+				}
+				if !slices.Equal(state.executed, []string{"up 3"}) {
+					t.Errorf("executed %v, want only up 3", state.executed)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Up() error = %v, want %q", err, tt.want)
+			}
+			if len(state.executed) != 0 {
+				t.Errorf("executed %v on an inconsistent table", state.executed)
+			}
+		})
+	}
+}
+
+func TestMigratorUpgradesLegacyTable(t *testing.T) {
+	db, state := openFakeDB(t)
+	// golang-migrate keeps a single row with the latest applied version.
+	state.table = true
+	state.legacy = true
+	state.rows[2] = &fakeRow{}
+
+	m := NewMigrator(db, testMigrations)
+
+	if _, err := m.Status(context.Background()); err == nil || !strings.Contains(err.Error(), "legacy layout") {
+		t.Fatalf("Status() error = %v, want the legacy layout error", err)
+	}
+
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(state.executed, []string{"up 3"}) {
+		t.Errorf("executed %v, want only up 3", state.executed)
+	}
+	for _, migration := range testMigrations {
+		if row, ok := state.rows[migration.Version]; !ok || row.checksum == nil || *row.checksum != migration.Checksum {
+			t.Errorf("migration %d recorded as %+v", migration.Version, row)
+		}
+	}
+}
+
+func TestMigratorToAndDown(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(m *Migrator) error
+		executed []string
+		applied  []int64
+	}{
+		{
+			name:     "to an earlier version",
+			run:      func(m *Migrator) error { return m.To(context.Background(), 1) },
+			executed: []string{"down 3", "down 2"},
+			applied:  []int64{1},
+		},
+		{
+			name:     "to zero",
+			run:      func(m *Migrator) error { return m.To(context.Background(), 0) },
+			executed: []string{"down 3", "down 2", "down 1"},
+		},
+		{
+			name:    "to the latest version",
+			run:     func(m *Migrator) error { return m.To(context.Background(), 3) },
+			applied: []int64{1, 2, 3},
+		},
+		{
+			name:     "down one step",
+			run:      func(m *Migrator) error { return m.Down(context.Background(), 1) },
+			executed: []string{"down 3"},
+			applied:  []int64{1, 2},
+		},
+		{
+			name:     "down more steps than applied",
+			run:      func(m *Migrator) error { return m.Down(context.Background(), 5) },
+			executed: []string{"down 3", "down 2", "down 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, state := openFakeDB(t)
+			m := NewMigrator(db, testMigrations)
+			if err := m.Up(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			state.executed = nil
+
+			if err := tt.run(m); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(state.executed, tt.executed) {
+				t.Errorf("executed %v, want %v", state.executed, tt.executed)
+			}
+			if applied := slices.Sorted(maps.Keys(state.rows)); !slices.Equal(applied, tt.applied) {
+				t.Errorf("applied %v, want %v", applied, tt.applied)
+			}
+		})
+	}
+}
+
+func TestMigratorToUnknownVersion(t *testing.T) {
+	db, _ := openFakeDB(t)
+
+	err := NewMigrator(db, testMigrations).To(context.Background(), 4)
+	if err == nil || !strings.Contains(err.Error(), "unknown migration version 4") {
+		t.Errorf("To(4) error = %v", err)
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	db, state := openFakeDB(t)
+	state.table = true
+	state.rows[1] = &fakeRow{checksum: strPtr(checksum("up 1"))}
+	state.rows[2] = &fakeRow{checksum: strPtr(checksum("old up 2"))}
+	state.rows[3] = &fakeRow{checksum: strPtr(checksum("edited up 3")), dirty: true}
+	state.rows[9] = &fakeRow{checksum: strPtr("removed")}
+
+	statuses, err := NewMigrator(db, testMigrations[:3]).Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type status struct {
+		version  int64
+		name     string
+		applied  bool
+		dirty    bool
+		modified bool
+	}
+	want := []status{
+		{version: 1, name: "first", applied: true},
+		{version: 2, name: "second", applied: true},
+		{version: 3, name: "third", applied: true, dirty: true, modified: true},
+		{version: 9, applied: true},
+	}
+
+	got := make([]status, 0, len(statuses))
+	for _, s := range statuses {
+		got = append(got, status{s.Version, s.Name, s.Applied, s.Dirty, s.Modified})
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Status() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMigratorCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		rows map[int64]*fakeRow
+		want string
+	}{
+		{
+			name: "migrated",
+			rows: map[int64]*fakeRow{
+				1: {checksum: strPtr(checksum("up 1"))},
+				2: {checksum: strPtr(checksum("old up 2"))},
+				3: {checksum: strPtr(checksum("up 3"))},
+			},
+		},
+		{
+			name: "pending",
+			rows: map[int64]*fakeRow{1: {checksum: strPtr(checksum("up 1"))}},
+			want: "2 migrations are pending",
+		},
+		{
+			name: "dirty",
+			rows: map[int64]*fakeRow{
+				1: {checksum: strPtr(checksum("up 1"))},
+				2: {checksum: strPtr(checksum("up 2")), dirty: true},
+				3: {checksum: strPtr(checksum("up 3"))},
+			},
+			want: "migration 2 is dirty",
+		},
+		{
+			name: "modified",
+			rows: map[int64]*fakeRow{
+				1: {checksum: strPtr(checksum("up 1"))},
+				2: {checksum: strPtr(checksum("up 2"))},
+				3: {checksum: strPtr(checksum("edited up 3"))},
+			},
+			want: "migration 3_third was modified after it was applied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, state := openFakeDB(t)
+			state.table = true
+			state.rows = tt.rows
+
+			err := NewMigrator(db, testMigrations).Check(context.Background())
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Check() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// The fake driver below understands the statements the migrator issues and
+// records every other one as a migration body.
+
+type fakeRow struct {
+	dirty     bool
+	checksum  *string
+	appliedAt time.Time
+}
+
+type fakeState struct {
+	mu       sync.Mutex
+	table    bool
+	legacy   bool
+	rows     map[int64]*fakeRow
+	executed []string
+	failOn   string
+	locks    int
+}
+
+var (
+	fakeStates   sync.Map
+	fakeRegister sync.Once
+)
+
+func openFakeDB(t *testing.T) (*sql.DB, *fakeState) {
+	fakeRegister.Do(func() { sql.Register("migratefake", fakeDriver{}) })
+
+	state := &fakeState{rows: map[int64]*fakeRow{}}
+	fakeStates.Store(t.Name(), state)
+	t.Cleanup(func() { fakeStates.Delete(t.Name()) })
+
+	db, err := sql.Open("migratefake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db, state
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	state, ok := fakeStates.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("no fake database %q", name)
+	}
+	return &fakeConn{state: state.(*fakeState)}, nil
+}
+
+type fakeSQLError struct{ code string }
+
+func (e fakeSQLError) Error() string    { return "sql error " + e.code }
+func (e fakeSQLError) SQLState() string { return e.code }
+
+type fakeConn struct {
+	state *fakeState
+	// snapshot holds the rows at the start of the open transaction.
+	snapshot map[int64]*fakeRow
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	c.snapshot = map[int64]*fakeRow{}
+	for version, row := range c.state.rows {
+		copied := *row
+		c.snapshot[version] = &copied
+	}
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.snapshot = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if c.snapshot != nil {
+		c.state.rows = c.snapshot
+		c.snapshot = nil
+	}
+	return nil
+}
+
+func normalize(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query = normalize(query)
+	switch {
+	case strings.HasPrefix(query, "select pg_advisory_lock("):
+		s.locks++
+	case strings.HasPrefix(query, "select pg_advisory_unlock("):
+		s.locks--
+	case strings.HasPrefix(query, "create table if not exists schema_migrations"):
+		s.table = true
+	case strings.HasPrefix(query, "alter table schema_migrations add column"):
+		if s.legacy {
+			for _, row := range s.rows {
+				row.appliedAt = time.Now()
+			}
+			s.legacy = false
+		}
+	case strings.HasPrefix(query, "insert into schema_migrations (version, checksum) values ($1, $2) on conflict"):
+		version, sum := args[0].Value.(int64), args[1].Value.(string)
+		if row, ok := s.rows[version]; !ok {
+			s.rows[version] = &fakeRow{checksum: &sum, appliedAt: time.Now()}
+		} else if row.checksum == nil {
+			row.checksum = &sum
+		}
+	case strings.HasPrefix(query, "insert into schema_migrations"):
+		version, sum := args[0].Value.(int64), args[1].Value.(string)
+		if _, ok := s.rows[version]; ok {
+			return nil, fmt.Errorf("duplicate version %d", version)
+		}
+		s.rows[version] = &fakeRow{checksum: &sum, appliedAt: time.Now()}
+	case strings.HasPrefix(query, "delete from schema_migrations where version = $1"):
+		delete(s.rows, args[0].Value.(int64))
+	default:
+		if query == s.failOn {
+			return nil, errors.New("syntax error")
+		}
+		s.executed = append(s.executed, query)
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch query = normalize(query); query {
+	case "select to_regclass('schema_migrations')::text":
+		if !s.table {
+			return &fakeRows{columns: []string{"to_regclass"}, values: [][]driver.Value{{nil}}}, nil
+		}
+		return &fakeRows{columns: []string{"to_regclass"}, values: [][]driver.Value{{"schema_migrations"}}}, nil
+
+	case "select version, dirty, checksum, applied_at from schema_migrations":
+		if s.legacy {
+			return nil, fakeSQLError{code: "42703"}
+		}
+		rows := &fakeRows{columns: []string{"version", "dirty", "checksum", "applied_at"}}
+		for _, version := range slices.Sorted(maps.Keys(s.rows)) {
+			row := s.rows[version]
+			var sum driver.Value
+			if row.checksum != nil {
+				sum = *row.checksum
+			}
+			rows.values = append(rows.values, []driver.Value{version, row.dirty, sum, row.appliedAt})
+		}
+		return rows, nil
+
+	case "select max(version) from schema_migrations where checksum is null":
+		var latest driver.Value
+		for version, row := range s.rows {
+			if row.checksum == nil && (latest == nil || version > latest.(int64)) {
+				latest = version
+			}
+		}
+		return &fakeRows{columns: []string{"max"}, values: [][]driver.Value{{latest}}}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// AuthUser is a row of auth.users, shared with Supabase when it is used.
+type AuthUser struct {
+	ID                uuid.UUID      `json:"id" gorm:"primaryKey"`
+	Aud               string         `json:"aud"`
+	Role              string         `json:"role"`
+	Email             string         `json:"email"`
+	EncryptedPassword string         `json:"-"`
+	EmailConfirmedAt  *time.Time     `json:"email_confirmed_at"`
+	LastSignInAt      *time.Time     `json:"last_sign_in_at"`
+	RawAppMetaData    datatypes.JSON `json:"app_metadata"`
+	RawUserMetaData   datatypes.JSON `json:"user_metadata"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+func (AuthUser) TableName() string {
+	return "auth.users"
+}
+
+type AuthSession struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserId      uuid.UUID  `json:"user_id"`
+	UserAgent   *string    `json:"user_agent"`
+	IP          *string    `json:"ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RefreshedAt *time.Time `json:"refreshed_at"`
+}
+
+func (AuthSession) TableName() string {
+	return "auth_sessions"
+}
+
+type AuthRefreshToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SessionId uuid.UUID `json:"session_id"`
+	TokenHash string    `json:"-"`
+	Revoked   bool      `json:"revoked"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (AuthRefreshToken) TableName() string {
+	return "auth_refresh_tokens"
+}
+
+type AuthOneTimeTokenType string
+
+const (
+	AuthTokenSignup   AuthOneTimeTokenType = "signup"
+	AuthTokenRecovery AuthOneTimeTokenType = "recovery"
+)
+
+type AuthOneTimeToken struct {
+	ID        uint                 `json:"id" gorm:"primaryKey"`
+	UserId    uuid.UUID            `json:"user_id"`
+	TokenType AuthOneTimeTokenType `json:"token_type"`
+	TokenHash string               `json:"-"`
+	CreatedAt time.Time            `json:"created_at"`
+	ExpiresAt time.Time            `json:"expires_at"`
+}
+
+func (AuthOneTimeToken) TableName() string {
+	return "auth_one_time_tokens"
+}
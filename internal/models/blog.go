@@ -17,6 +17,7 @@ type Blog struct {
 	Slug        string         `json:"slug"`
 	Attributes  datatypes.JSON `json:"attributes"`
 	PublishedAt *time.Time     `json:"published_at"`
+	RankAt      *time.Time     `json:"-"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -52,10 +53,11 @@ func (BlogComment) TableName() string {
 type BlogComments []BlogComment
 
 type BlogReaction struct {
-	ID     uint      `json:"id" gorm:"primaryKey"`
-	BlogId uint      `json:"blog_id"`
-	UserId uuid.UUID `json:"user_id"`
-	Type   string    `json:"type" gorm:"type:user_reaction_type_enum"`
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlogId    uint      `json:"blog_id"`
+	UserId    uuid.UUID `json:"user_id"`
+	Type      string    `json:"type" gorm:"type:user_reaction_type_enum"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func (BlogReaction) TableName() string {
@@ -65,9 +67,10 @@ func (BlogReaction) TableName() string {
 type BlogReactions []BlogReaction
 
 type BlogBookmark struct {
-	ID     uint      `json:"id" gorm:"primaryKey"`
-	BlogId uint      `json:"blog_id"`
-	UserId uuid.UUID `json:"user_id"`
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlogId    uint      `json:"blog_id"`
+	UserId    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func (BlogBookmark) TableName() string {
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	// JobDead is a job that failed its last attempt and won't be retried.
+	JobDead JobStatus = "dead"
+)
+
+// Job is a unit of background work. MaxAttempts overrides the workers'
+// default when set.
+type Job struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Type        string         `json:"type"`
+	Payload     datatypes.JSON `json:"payload"`
+	Status      JobStatus      `json:"status"`
+	Attempts    int            `json:"attempts"`
+	MaxAttempts *int           `json:"max_attempts"`
+	RunAt       time.Time      `json:"run_at"`
+	LockedUntil *time.Time     `json:"locked_until"`
+	LastError   *string        `json:"last_error"`
+	UniqueKey   *string        `json:"unique_key"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	FinishedAt  *time.Time     `json:"finished_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
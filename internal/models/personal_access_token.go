@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PersonalAccessToken struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserId      uuid.UUID      `json:"user_id"`
+	Name        string         `json:"name"`
+	TokenPrefix string         `json:"token_prefix"`
+	TokenHash   string         `json:"-"`
+	Scopes      pq.StringArray `json:"scopes" gorm:"type:text[]"`
+	ExpiresAt   *time.Time     `json:"expires_at"`
+	LastUsedAt  *time.Time     `json:"last_used_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenRevocationKind string
+
+const (
+	RevokeSession TokenRevocationKind = "session"
+	RevokeJTI     TokenRevocationKind = "jti"
+	// RevokeUser revokes every token issued to the user before RevokedAt.
+	RevokeUser TokenRevocationKind = "user"
+)
+
+type TokenRevocation struct {
+	Kind      TokenRevocationKind `json:"kind" gorm:"primaryKey"`
+	Key       string              `json:"key" gorm:"primaryKey"`
+	RevokedAt time.Time           `json:"revoked_at"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+}
+
+func (TokenRevocation) TableName() string {
+	return "token_revocations"
+}
+
+type UserBan struct {
+	UserId      uuid.UUID  `json:"user_id" gorm:"primaryKey"`
+	Reason      *string    `json:"reason"`
+	BannedUntil *time.Time `json:"banned_until"`
+	CreatedBy   *uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (UserBan) TableName() string {
+	return "user_bans"
+}
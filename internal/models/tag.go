@@ -9,14 +9,15 @@ import (
 )
 
 type Tag struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	UserId      *uuid.UUID     `json:"user_id"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Attributes  datatypes.JSON `json:"attributes"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	UserId       *uuid.UUID     `json:"user_id"`
+	Name         string         `json:"name"`
+	Description  *string        `json:"description"`
+	Attributes   datatypes.JSON `json:"attributes"`
+	DeprecatedAt *time.Time     `json:"deprecated_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 func (Tag) TableName() string {
@@ -24,3 +25,27 @@ func (Tag) TableName() string {
 }
 
 type Tags []Tag
+
+// TagAlias is another name of a tag, such as golang for go. Names are stored
+// lowercase.
+type TagAlias struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	TagId     uint      `json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TagAlias) TableName() string {
+	return "tag_aliases"
+}
+
+type TagAliases []TagAlias
+
+type TagFollow struct {
+	UserId    uuid.UUID `json:"user_id" gorm:"primaryKey"`
+	TagId     uint      `json:"tag_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TagFollow) TableName() string {
+	return "tag_follows"
+}
@@ -17,16 +17,17 @@ const (
 )
 
 type UserProfile struct {
-	UserId          uuid.UUID       `json:"user_id" gorm:"primaryKey"`
-	Email           string          `json:"email" gorm:"uniqueIndex"`
-	FullName        *string         `json:"full_name"`
-	AvatarUrl       *string         `json:"avatar_url"`
-	Slug            string          `json:"slug" gorm:"uniqueIndex"`
-	PortfolioStatus PortfolioStatus `json:"status" gorm:"type:user_profiles_portfolio_status_enum"`
-	Attributes      *datatypes.JSON `json:"attributes"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt  `json:"deleted_at" gorm:"index"`
+	UserId              uuid.UUID       `json:"user_id" gorm:"primaryKey"`
+	Email               string          `json:"email" gorm:"uniqueIndex"`
+	FullName            *string         `json:"full_name"`
+	AvatarUrl           *string         `json:"avatar_url"`
+	Slug                string          `json:"slug" gorm:"uniqueIndex"`
+	PortfolioStatus     PortfolioStatus `json:"status" gorm:"type:user_profiles_portfolio_status_enum"`
+	Attributes          *datatypes.JSON `json:"attributes"`
+	SharePortfolioViews bool            `json:"share_portfolio_views"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt  `json:"deleted_at" gorm:"index"`
 }
 
 func (UserProfile) TableName() string {
@@ -39,6 +40,7 @@ type UserFollow struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	FollowerId  uuid.UUID `json:"follower_id"`
 	FollowingId uuid.UUID `json:"following_id"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 func (UserFollow) TableName() string {
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+const (
+	WebhookEventBlogPublished          = "blog.published"
+	WebhookEventBlogUnpublished        = "blog.unpublished"
+	WebhookEventCommentCreated         = "comment.created"
+	WebhookEventFollowCreated          = "follow.created"
+	WebhookEventPortfolioStatusChanged = "portfolio.status_changed"
+	WebhookEventPortfolioModuleUpdated = "portfolio.module_updated"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookEndpoint struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserId      uuid.UUID      `json:"user_id"`
+	URL         string         `json:"url"`
+	Secret      string         `json:"-"`
+	EventTypes  pq.StringArray `json:"event_types" gorm:"type:text[]"`
+	Active      bool           `json:"active"`
+	Description *string        `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	EndpointId     uint                  `json:"endpoint_id"`
+	EventId        uuid.UUID             `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	Payload        datatypes.JSON        `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at"`
+	ResponseStatus *int                  `json:"response_status"`
+	ResponseBody   *string               `json:"response_body"`
+	Error          *string               `json:"error"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
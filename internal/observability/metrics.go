@@ -0,0 +1,172 @@
+package observability
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const metricsNamespace = "dp"
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route template and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of queries run through GORM, by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	// StorageErrors counts failed S3 operations, by operation such as
+	// "presign_put" or "upload".
+	StorageErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "storage_errors_total",
+		Help:      "Failed object storage presign and upload operations.",
+	}, []string{"operation"})
+
+	BlogsPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "blogs_published_total",
+		Help:      "Blogs created or updated with publishing requested.",
+	})
+
+	CommentsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "comments_created_total",
+		Help:      "Comments and replies created.",
+	})
+
+	Follows = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "follows_total",
+		Help:      "Users followed.",
+	})
+
+	// WebhookDeliveries counts attempts to send a webhook, by the status the
+	// delivery was left in: succeeded, pending for a retry, or failed.
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook delivery attempts, by resulting delivery status.",
+	}, []string{"status"})
+
+	// JobsProcessed counts job attempts, by type and the status the job was
+	// left in.
+	JobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "jobs_processed_total",
+		Help:      "Background job attempts, by job type and resulting status.",
+	}, []string{"type", "status"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of background job attempts, by job type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type"})
+
+	// Views counts views by kind, such as "blog", and by what became of
+	// them: recorded, ignored as a duplicate or an author's own view, or
+	// dropped because the buffer was full or could not be written.
+	Views = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "views_total",
+		Help:      "Views counted by the analytics recorder, by kind and result.",
+	}, []string{"kind", "result"})
+)
+
+// RequestMetrics records the count and latency of every request under the
+// route template it matched, so /blogs/:slug is one series for all blogs.
+func RequestMetrics() gin.HandlerFunc {
+	return gin.HandlerFunc(func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+
+		httpRequestsTotal.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+const queryStartKey = "observability:query_start"
+
+// InstrumentDB registers GORM callbacks timing and tracing every query and
+// exports the connection pool stats of db.
+func InstrumentDB(db *gorm.DB) error {
+	before := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			tx.InstanceSet(queryStartKey, time.Now())
+			startQuerySpan(tx, operation)
+		}
+	}
+	after := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			endQuerySpan(tx)
+
+			value, ok := tx.InstanceGet(queryStartKey)
+			if !ok {
+				return
+			}
+			dbQueryDuration.WithLabelValues(operation).Observe(time.Since(value.(time.Time)).Seconds())
+		}
+	}
+
+	cb := db.Callback()
+
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("metrics:before_create", before("create")),
+		cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", before("query")),
+		cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", before("update")),
+		cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before("delete")),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", before("row")),
+		cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before("raw")),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, "postgres"))
+}
+
+// MetricsHandler serves every registered metric in the Prometheus text format.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
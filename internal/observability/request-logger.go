@@ -3,6 +3,7 @@ package observability
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,7 +29,7 @@ func AddRequestID(globalConfig *config.GlobalConfiguration) gin.HandlerFunc {
 func NewStructuredLogger(logger *logrus.Logger, config *config.GlobalConfiguration) gin.HandlerFunc {
 	loggerInstance := structuredLogger{Logger: logger, Config: config}
 	return gin.HandlerFunc(func(ctx *gin.Context) {
-		if ctx.Request.URL.Path == "/health" {
+		if strings.HasPrefix(ctx.Request.URL.Path, "/health") {
 			ctx.Next()
 		} else {
 			start := time.Now()
@@ -70,6 +71,10 @@ func (l *structuredLogger) NewLogEntry(ctx *gin.Context) *logEntry {
 		logFields["request_id"] = reqID
 	}
 
+	for k, v := range traceFields(ctx.Request.Context()) {
+		logFields[k] = v
+	}
+
 	e.Entry = e.Entry.WithFields(logFields)
 	return e
 }
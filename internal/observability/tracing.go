@@ -0,0 +1,197 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracerName = "github.com/hiumesh/dynamic-portfolio-REST-API"
+
+var (
+	tracingOnce sync.Once
+)
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// ConfigureTracing installs the global tracer provider and the W3C trace
+// context propagator. Spans are flushed when ctx is done; use
+// WaitForCleanup to wait for that to finish.
+func ConfigureTracing(ctx context.Context, conf *config.TracingConfig) error {
+	var err error
+
+	tracingOnce.Do(func() {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+		if !conf.Enabled {
+			return
+		}
+
+		var exporter sdktrace.SpanExporter
+		exporter, err = newSpanExporter(ctx, conf)
+		if err != nil {
+			return
+		}
+
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(resource.NewSchemaless(
+				semconv.ServiceName(conf.ServiceName),
+				semconv.ServiceVersion(utilities.Version),
+			)),
+		)
+		otel.SetTracerProvider(provider)
+
+		cleanupWaitGroup.Add(1)
+		go func() {
+			defer cleanupWaitGroup.Done()
+
+			<-ctx.Done()
+
+			if shutdownErr := provider.Shutdown(context.Background()); shutdownErr != nil {
+				logrus.WithError(shutdownErr).Error("unable to shutdown tracer provider")
+			}
+		}()
+	})
+
+	return err
+}
+
+func newSpanExporter(ctx context.Context, conf *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch conf.Exporter {
+	case config.TracingExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingExporterOTLP:
+		var opts []otlptracehttp.Option
+		if conf.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(conf.Endpoint))
+		}
+		if conf.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", conf.Exporter)
+	}
+}
+
+// TraceRequests starts a server span for every request, continuing the trace
+// of an incoming traceparent header and returning the span's own traceparent
+// in the response.
+func TraceRequests(serviceName string) gin.HandlersChain {
+	return gin.HandlersChain{
+		otelgin.Middleware(serviceName),
+		func(ctx *gin.Context) {
+			span := trace.SpanFromContext(ctx.Request.Context())
+			if reqID := utilities.GetRequestID(ctx); reqID != "" {
+				span.SetAttributes(attribute.String("request.id", reqID))
+			}
+
+			otel.GetTextMapPropagator().Inject(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Writer.Header()))
+
+			ctx.Next()
+		},
+	}
+}
+
+// traceFields returns the log fields identifying the span active in ctx.
+func traceFields(ctx context.Context) logrus.Fields {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	return logrus.Fields{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}
+}
+
+const querySpanKey = "observability:query_span"
+
+func startQuerySpan(tx *gorm.DB, operation string) {
+	ctx := tx.Statement.Context
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		// Only queries made on behalf of a traced request get a span.
+		return
+	}
+
+	_, span := tracer().Start(ctx, "db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+		),
+	)
+	tx.InstanceSet(querySpanKey, span)
+}
+
+func endQuerySpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+
+	span := value.(trace.Span)
+	if tx.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(tx.Statement.Table))
+	}
+	span.SetAttributes(semconv.DBQueryText(tx.Statement.SQL.String()))
+	if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+	span.End()
+}
+
+// InstrumentAWS adds a client span around every AWS operation, including
+// presigning, made with cfg.
+func InstrumentAWS(cfg *aws.Config) {
+	cfg.APIOptions = append(cfg.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("observability:aws_span", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			service := awsmiddleware.GetServiceID(ctx)
+			operation := awsmiddleware.GetOperationName(ctx)
+
+			ctx, span := tracer().Start(ctx, service+"."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.RPCSystemKey.String("aws-api"),
+					semconv.RPCService(service),
+					semconv.RPCMethod(operation),
+				),
+			)
+			defer span.End()
+
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return out, metadata, err
+		}), middleware.Before)
+	})
+}
@@ -0,0 +1,206 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const BearerAuth = "bearerAuth"
+
+// Security describes how an operation authenticates its caller.
+type Security int
+
+const (
+	SecurityNone Security = iota
+	SecurityOptional
+	SecurityRequired
+)
+
+// OperationSpec documents a single route. Everything apart from the method
+// and path, which come from the router, is described here.
+type OperationSpec struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Security    Security
+	Query       []Parameter
+	Request     any
+	Response    any
+}
+
+var pathParamRegexp = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+type Generator struct {
+	doc         *Document
+	registry    *SchemaRegistry
+	operationID map[string]int
+}
+
+func NewGenerator(info Info, servers ...Server) *Generator {
+	return &Generator{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Servers: servers,
+			Paths:   map[string]*PathItem{},
+			Components: Components{
+				SecuritySchemes: map[string]*SecurityScheme{
+					BearerAuth: {
+						Type:         "http",
+						Scheme:       "bearer",
+						BearerFormat: "JWT",
+					},
+				},
+			},
+		},
+		registry:    NewSchemaRegistry(),
+		operationID: map[string]int{},
+	}
+}
+
+// ConvertPath turns a gin route pattern such as /blogs/:slug into the
+// OpenAPI template /blogs/{slug}.
+func ConvertPath(path string) string {
+	return pathParamRegexp.ReplaceAllString(path, "{$1}")
+}
+
+// AddRoute documents one registered route. handlerName is the name gin
+// reports for the final handler and is only used to derive an operationId.
+func (g *Generator) AddRoute(method string, path string, handlerName string, spec OperationSpec) {
+	op := &Operation{
+		OperationID: g.uniqueOperationID(operationIDFromHandler(handlerName, method, path)),
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		Tags:        spec.Tags,
+		Responses:   map[string]*Response{},
+	}
+
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	op.Parameters = append(op.Parameters, spec.Query...)
+
+	if spec.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: g.registry.SchemaFor(spec.Request)},
+			},
+		}
+	}
+
+	success := &Response{Description: "Successful response"}
+	if spec.Response != nil {
+		success.Content = map[string]MediaType{
+			"application/json": {Schema: g.registry.SchemaFor(spec.Response)},
+		}
+	}
+	op.Responses["200"] = success
+	op.Responses["default"] = &Response{
+		Description: "Error response",
+		Content: map[string]MediaType{
+			"application/json": {Schema: RefTo("HTTPError")},
+		},
+	}
+
+	switch spec.Security {
+	case SecurityRequired:
+		op.Security = []map[string][]string{{BearerAuth: {}}}
+	case SecurityOptional:
+		op.Security = []map[string][]string{{BearerAuth: {}}, {}}
+	}
+
+	path = ConvertPath(path)
+	item, ok := g.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		g.doc.Paths[path] = item
+	}
+
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodHead:
+		item.Head = op
+	}
+
+	for _, tag := range spec.Tags {
+		g.addTag(tag)
+	}
+}
+
+// AddSchema registers a named component that operations can reference with
+// RefTo, such as the shared error envelope.
+func (g *Generator) AddSchema(v any) *Schema {
+	return g.registry.SchemaFor(v)
+}
+
+func (g *Generator) Document() *Document {
+	g.doc.Components.Schemas = g.registry.Schemas()
+	sort.Slice(g.doc.Tags, func(i, j int) bool {
+		return g.doc.Tags[i].Name < g.doc.Tags[j].Name
+	})
+	return g.doc
+}
+
+func (g *Generator) addTag(name string) {
+	for _, tag := range g.doc.Tags {
+		if tag.Name == name {
+			return
+		}
+	}
+	g.doc.Tags = append(g.doc.Tags, Tag{Name: name})
+}
+
+func (g *Generator) uniqueOperationID(id string) string {
+	g.operationID[id]++
+	if n := g.operationID[id]; n > 1 {
+		return id + strings.Repeat("_", n-1)
+	}
+	return id
+}
+
+// operationIDFromHandler turns
+// ".../internal/api.(*handlerBlog).GetAll-fm" into "blogGetAll", falling back
+// to the method and path for anything that is not a handler method.
+func operationIDFromHandler(handlerName string, method string, path string) string {
+	name := handlerName[strings.LastIndex(handlerName, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+
+	if start := strings.Index(name, "(*handler"); start >= 0 {
+		rest := name[start+len("(*handler"):]
+		if end := strings.Index(rest, ")."); end >= 0 {
+			return lowerFirst(rest[:end]) + rest[end+2:]
+		}
+	}
+
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '.' || r == '_'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
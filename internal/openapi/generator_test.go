@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestConvertPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/blogs", want: "/blogs"},
+		{path: "/blogs/:slug", want: "/blogs/{slug}"},
+		{path: "/portfolio/:slug/:module", want: "/portfolio/{slug}/{module}"},
+		{path: "/comments/:Id/reaction", want: "/comments/{Id}/reaction"},
+		{path: "/docs/*file_path", want: "/docs/{file_path}"},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertPath(tt.path); got != tt.want {
+			t.Errorf("ConvertPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOperationIDFromHandler(t *testing.T) {
+	tests := []struct {
+		handler string
+		method  string
+		path    string
+		want    string
+	}{
+		{
+			handler: "github.com/hiumesh/dynamic-portfolio-REST-API/internal/api.(*handlerBlog).GetAll-fm",
+			method:  http.MethodGet,
+			path:    "/blogs",
+			want:    "blogGetAll",
+		},
+		{
+			handler: "github.com/hiumesh/dynamic-portfolio-REST-API/internal/api.(*handlerWorkGallery).Reorder-fm",
+			method:  http.MethodPut,
+			path:    "/work-gallery/reorder",
+			want:    "workGalleryReorder",
+		},
+		{
+			handler: "github.com/hiumesh/dynamic-portfolio-REST-API/internal/api.(*API).OpenAPIDocs-fm",
+			method:  http.MethodGet,
+			path:    "/docs",
+			want:    "getDocs",
+		},
+		{
+			handler: "github.com/hiumesh/dynamic-portfolio-REST-API/internal/api.NewAPIWithVersion.func1",
+			method:  http.MethodGet,
+			path:    "/portfolio/:slug/similar_users",
+			want:    "getPortfolioSlugSimilarUsers",
+		},
+		{
+			handler: "github.com/hiumesh/dynamic-portfolio-REST-API/internal/api.(*API).Health-fm",
+			method:  http.MethodGet,
+			path:    "/openapi.json",
+			want:    "getOpenapiJson",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := operationIDFromHandler(tt.handler, tt.method, tt.path); got != tt.want {
+			t.Errorf("operationIDFromHandler(%q, %q, %q) = %q, want %q", tt.handler, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+type testRequest struct {
+	Title  string   `json:"title" validate:"required,min=3,max=100"`
+	Tags   []string `json:"tags" validate:"max=5,dive,min=2"`
+	Status string   `json:"status" validate:"oneof=draft published"`
+	Notes  string   `json:"-"`
+}
+
+type testResponse struct {
+	ID      uint         `json:"id"`
+	Request *testRequest `json:"request"`
+}
+
+func TestGeneratorAddRoute(t *testing.T) {
+	g := NewGenerator(Info{Title: "test", Version: "1"})
+	g.AddRoute(http.MethodPut, "/blogs/:Id", "api.(*handlerBlog).Update-fm", OperationSpec{
+		Tags:     []string{"Blogs"},
+		Security: SecurityRequired,
+		Request:  testRequest{},
+		Response: testResponse{},
+	})
+	g.AddRoute(http.MethodGet, "/blogs/:slug", "api.(*handlerBlog).GetBlogBySlug-fm", OperationSpec{
+		Tags:     []string{"Blogs"},
+		Security: SecurityOptional,
+		Query:    []Parameter{{Name: "preview", In: "query", Schema: &Schema{Type: "boolean"}}},
+	})
+	g.AddRoute(http.MethodPost, "/archive/:Id", "api.(*handlerBlog).Update-fm", OperationSpec{
+		Tags: []string{"Archive"},
+	})
+	doc := g.Document()
+
+	update := doc.Paths["/blogs/{Id}"].Put
+	if update == nil {
+		t.Fatal("PUT /blogs/{Id} not documented")
+	}
+	if update.OperationID != "blogUpdate" {
+		t.Errorf("OperationID = %q, want %q", update.OperationID, "blogUpdate")
+	}
+	wantParams := []Parameter{{Name: "Id", In: "path", Required: true, Schema: &Schema{Type: "string"}}}
+	if !reflect.DeepEqual(update.Parameters, wantParams) {
+		t.Errorf("Parameters = %+v, want %+v", update.Parameters, wantParams)
+	}
+	if !reflect.DeepEqual(update.Security, []map[string][]string{{BearerAuth: {}}}) {
+		t.Errorf("Security = %v, want the bearer scheme", update.Security)
+	}
+	if ref := update.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/testRequest" {
+		t.Errorf("request schema = %q, want a reference to testRequest", ref)
+	}
+	if ref := update.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/testResponse" {
+		t.Errorf("response schema = %q, want a reference to testResponse", ref)
+	}
+
+	get := doc.Paths["/blogs/{slug}"].Get
+	if get == nil {
+		t.Fatal("GET /blogs/{slug} not documented")
+	}
+	if len(get.Parameters) != 2 || get.Parameters[0].In != "path" || get.Parameters[1].Name != "preview" {
+		t.Errorf("Parameters = %+v, want the slug then the query", get.Parameters)
+	}
+	if !reflect.DeepEqual(get.Security, []map[string][]string{{BearerAuth: {}}, {}}) {
+		t.Errorf("Security = %v, want the bearer scheme or none", get.Security)
+	}
+	if get.RequestBody != nil {
+		t.Errorf("RequestBody = %+v, want none", get.RequestBody)
+	}
+
+	// Handlers shared by routes still get distinct operation ids.
+	if id := doc.Paths["/archive/{Id}"].Post.OperationID; id != "blogUpdate_" {
+		t.Errorf("OperationID = %q, want %q", id, "blogUpdate_")
+	}
+
+	if len(doc.Tags) != 2 || doc.Tags[0].Name != "Archive" || doc.Tags[1].Name != "Blogs" {
+		t.Errorf("Tags = %+v, want Archive and Blogs", doc.Tags)
+	}
+
+	request := doc.Components.Schemas["testRequest"]
+	if request == nil {
+		t.Fatal("testRequest not registered")
+	}
+	if !reflect.DeepEqual(request.Required, []string{"title"}) {
+		t.Errorf("Required = %v, want [title]", request.Required)
+	}
+	if _, ok := request.Properties["Notes"]; ok {
+		t.Error("field tagged json:\"-\" documented")
+	}
+	title := request.Properties["title"]
+	if title.MinLength == nil || *title.MinLength != 3 || title.MaxLength == nil || *title.MaxLength != 100 {
+		t.Errorf("title = %+v, want a length of 3 to 100", title)
+	}
+	tags := request.Properties["tags"]
+	if tags.MaxItems == nil || *tags.MaxItems != 5 || tags.Items.MinLength == nil || *tags.Items.MinLength != 2 {
+		t.Errorf("tags = %+v, want at most 5 items of at least 2 characters", tags)
+	}
+	if !reflect.DeepEqual(request.Properties["status"].Enum, []any{"draft", "published"}) {
+		t.Errorf("status enum = %v, want [draft published]", request.Properties["status"].Enum)
+	}
+
+	response := doc.Components.Schemas["testResponse"]
+	if response == nil || response.Properties["request"].Ref != "#/components/schemas/testRequest" {
+		t.Errorf("testResponse = %+v, want request to reference testRequest", response)
+	}
+}
@@ -0,0 +1,308 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	jsonType       = reflect.TypeOf(datatypes.JSON{})
+	stringArrayTyp = reflect.TypeOf(pq.StringArray{})
+)
+
+// SchemaRegistry converts Go types into JSON schemas, registering every named
+// struct it meets as a reusable component.
+type SchemaRegistry struct {
+	schemas map[string]*Schema
+	// types are the Go types components were registered for.
+	types map[string]reflect.Type
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: map[string]*Schema{}, types: map[string]reflect.Type{}}
+}
+
+func (r *SchemaRegistry) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// SchemaFor returns the schema for the type of v, usually a reference to a
+// component. Components are named after their type without its package, so
+// SchemaFor panics when two types would share a name.
+func (r *SchemaRegistry) SchemaFor(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return r.schemaForType(reflect.TypeOf(v))
+}
+
+func (r *SchemaRegistry) schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case jsonType:
+		return &Schema{}
+	case stringArrayTyp:
+		return &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaForType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := componentName(t)
+		if registered, ok := r.types[name]; ok && registered != t {
+			panic(fmt.Sprintf("openapi: %s and %s are both named %s", qualifiedName(registered), qualifiedName(t), name))
+		}
+		if _, ok := r.schemas[name]; !ok {
+			// reserve the name first so self-referencing types terminate
+			r.types[name] = t
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.structSchema(t)
+		}
+		return RefTo(name)
+	}
+
+	return &Schema{}
+}
+
+func (r *SchemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	// required_if rules grouped by the condition they depend on
+	conditions := map[[2]string][]string{}
+	var conditionOrder [][2]string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := r.schemaForType(field.Type)
+			if embedded.Ref != "" {
+				embedded = r.schemas[strings.TrimPrefix(embedded.Ref, "#/components/schemas/")]
+			}
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		prop := r.schemaForType(field.Type)
+		if prop.Ref == "" {
+			required, rules := applyValidateTag(prop, field.Tag.Get("validate"))
+			if required || strings.Contains(field.Tag.Get("binding"), "required") {
+				s.Required = append(s.Required, name)
+			}
+
+			for _, rule := range rules {
+				otherField, value := rule[0], rule[1]
+				if f, ok := t.FieldByName(otherField); ok {
+					otherField, _ = jsonName(f)
+				}
+				key := [2]string{otherField, value}
+				if _, ok := conditions[key]; !ok {
+					conditionOrder = append(conditionOrder, key)
+				}
+				conditions[key] = append(conditions[key], name)
+			}
+		} else if tagHas(field.Tag.Get("validate"), "required") || strings.Contains(field.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+		}
+
+		s.Properties[name] = prop
+	}
+
+	for _, key := range conditionOrder {
+		s.AllOf = append(s.AllOf, &Schema{
+			If: &Schema{
+				Properties: map[string]*Schema{key[0]: {Const: key[1]}},
+				Required:   []string{key[0]},
+			},
+			Then: &Schema{Required: conditions[key]},
+		})
+	}
+
+	return s
+}
+
+// componentName turns generic instantiations such as
+// Page[github.com/.../schemas.SelectBlog] into PageSelectBlog.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	start := strings.Index(name, "[")
+	if start < 0 {
+		return name
+	}
+
+	component := name[:start]
+	for _, arg := range strings.Split(strings.TrimSuffix(name[start+1:], "]"), ",") {
+		component += arg[strings.LastIndex(arg, ".")+1:]
+	}
+	return component
+}
+
+func qualifiedName(t reflect.Type) string {
+	return t.PkgPath() + "." + t.Name()
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+func tagHas(tag string, rule string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if part == rule {
+			return true
+		}
+		if part == "dive" {
+			return false
+		}
+	}
+	return false
+}
+
+// applyValidateTag maps go-playground/validator rules onto the schema. It
+// returns whether the field is unconditionally required and the
+// required_if=<Field> <value> pairs it found.
+func applyValidateTag(s *Schema, tag string) (bool, [][2]string) {
+	if tag == "" {
+		return false, nil
+	}
+
+	var required bool
+	var conditions [][2]string
+
+	target := s
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		param = strings.TrimSpace(param)
+
+		switch name {
+		case "":
+		case "dive":
+			if target.Items == nil {
+				return required, conditions
+			}
+			target = target.Items
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "required_if":
+			parts := strings.Fields(param)
+			for i := 0; i+1 < len(parts); i += 2 {
+				conditions = append(conditions, [2]string{parts[i], parts[i+1]})
+			}
+		case "omitempty":
+		case "min", "max", "len":
+			applyBound(target, name, param)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, v)
+			}
+		case "unique":
+			target.UniqueItems = true
+		case "url", "uri":
+			target.Format = "uri"
+		case "email":
+			target.Format = "email"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "datetime":
+			if param == "2006-01-02" {
+				target.Format = "date"
+			} else {
+				target.Format = "date-time"
+			}
+		case "number", "numeric":
+			if target.Type == "string" {
+				target.Pattern = `^-?[0-9]+(\.[0-9]+)?$`
+			}
+		default:
+			target.Rules = append(target.Rules, rule)
+		}
+	}
+
+	return required, conditions
+}
+
+func applyBound(s *Schema, rule string, param string) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		if rule == "min" || rule == "len" {
+			s.MinLength = &n
+		}
+		if rule == "max" || rule == "len" {
+			s.MaxLength = &n
+		}
+	case "array":
+		if rule == "min" || rule == "len" {
+			s.MinItems = &n
+		}
+		if rule == "max" || rule == "len" {
+			s.MaxItems = &n
+		}
+	case "integer", "number":
+		f := float64(n)
+		if rule == "min" || rule == "len" {
+			s.Minimum = &f
+		}
+		if rule == "max" || rule == "len" {
+			s.Maximum = &f
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type duplicate struct {
+	A string `json:"a"`
+}
+
+type duplicateHolder struct {
+	Second duplicate `json:"second"`
+}
+
+func TestSchemaForNameCollision(t *testing.T) {
+	// Stands in for a type of the same name in another package.
+	type duplicate struct {
+		B string `json:"b"`
+	}
+
+	registry := NewSchemaRegistry()
+	registry.SchemaFor(struct {
+		First duplicate `json:"first"`
+	}{})
+
+	defer func() {
+		if p := recover(); p == nil || !strings.Contains(fmt.Sprint(p), "are both named duplicate") {
+			t.Errorf("SchemaFor() panic = %v, want a name collision", p)
+		}
+	}()
+
+	registry.SchemaFor(duplicateHolder{})
+	t.Error("SchemaFor() registered two types as the same component")
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
 	"github.com/sirupsen/logrus"
 )
 
@@ -115,6 +116,7 @@ func (basics BucketBasics) UploadFile(ctx context.Context, bucketName string, ob
 			Body:   file,
 		})
 		if err != nil {
+			observability.StorageErrors.WithLabelValues("upload").Inc()
 			var apiErr smithy.APIError
 			if errors.As(err, &apiErr) && apiErr.ErrorCode() == "EntityTooLarge" {
 				logrus.Errorf("Error while uploading object to %s. The object is too large.\n"+
@@ -149,6 +151,7 @@ func (basics BucketBasics) UploadLargeObject(ctx context.Context, bucketName str
 		Body:   largeBuffer,
 	})
 	if err != nil {
+		observability.StorageErrors.WithLabelValues("upload").Inc()
 		var apiErr smithy.APIError
 		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "EntityTooLarge" {
 			logrus.Errorf("Error while uploading object to %s. The object is too large.\n"+
@@ -362,6 +365,7 @@ func NewBucketBasics(ctx context.Context, config *config.AWSConfiguration) *Buck
 	if err != nil {
 		logrus.Fatal(err)
 	}
+	observability.InstrumentAWS(&cfg)
 
 	s3Client := s3.NewFromConfig(cfg)
 	return &BucketBasics{S3Client: s3Client}
@@ -369,9 +373,19 @@ func NewBucketBasics(ctx context.Context, config *config.AWSConfiguration) *Buck
 
 type Presigner struct {
 	bucketName    string
+	client        *s3.Client
 	presignClient *s3.PresignClient
 }
 
+// HeadBucket checks that the bucket is reachable with the configured
+// credentials.
+func (presigner Presigner) HeadBucket(ctx context.Context) error {
+	_, err := presigner.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(presigner.bucketName),
+	})
+	return err
+}
+
 func (presigner Presigner) GetObject(
 	ctx context.Context, objectKey string, lifetimeSecs int64) (*v4.PresignedHTTPRequest, error) {
 	request, err := presigner.presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
@@ -381,6 +395,7 @@ func (presigner Presigner) GetObject(
 		opts.Expires = time.Duration(lifetimeSecs * int64(time.Second))
 	})
 	if err != nil {
+		observability.StorageErrors.WithLabelValues("presign_get").Inc()
 		logrus.Errorf("Couldn't get a presigned request to get %v:%v. Here's why: %v\n",
 			presigner.bucketName, objectKey, err)
 	}
@@ -399,6 +414,7 @@ func (presigner Presigner) PutObject(
 	})
 
 	if err != nil {
+		observability.StorageErrors.WithLabelValues("presign_put").Inc()
 		logrus.Errorf("Couldn't get a presigned request to put %v:%v. Here's why: %v\n",
 			presigner.bucketName, objectKey, err)
 	}
@@ -412,6 +428,7 @@ func (presigner Presigner) DeleteObject(ctx context.Context, objectKey string) (
 		Key:    aws.String(objectKey),
 	})
 	if err != nil {
+		observability.StorageErrors.WithLabelValues("presign_delete").Inc()
 		logrus.Errorf("Couldn't get a presigned request to delete object %v. Here's why: %v\n", objectKey, err)
 	}
 	return request, err
@@ -428,6 +445,7 @@ func (presigner Presigner) PresignPostObject(ctx context.Context, objectKey stri
 
 	logrus.Info(request)
 	if err != nil {
+		observability.StorageErrors.WithLabelValues("presign_post").Inc()
 		logrus.Errorf("Couldn't get a presigned post request to put %v:%v. Here's why: %v\n", presigner.bucketName, objectKey, err)
 	}
 	return request, nil
@@ -440,6 +458,7 @@ func NewPresigner(ctx context.Context, config *config.AWSConfiguration) *Presign
 	if err != nil {
 		logrus.Fatal(err)
 	}
+	observability.InstrumentAWS(&cfg)
 
 	s3Client := s3.NewFromConfig(cfg)
 	bucketBasics := BucketBasics{S3Client: s3Client}
@@ -459,6 +478,6 @@ func NewPresigner(ctx context.Context, config *config.AWSConfiguration) *Presign
 	}
 
 	presignClient := s3.NewPresignClient(s3Client)
-	return &Presigner{presignClient: presignClient, bucketName: config.BucketName}
+	return &Presigner{client: s3Client, presignClient: presignClient, bucketName: config.BucketName}
 
 }
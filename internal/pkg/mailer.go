@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net"
+	"net/smtp"
+	"strings"
+
+	emailtemplates "github.com/hiumesh/dynamic-portfolio-REST-API/email-templates"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	TemplateConfirmSignup = "confirm-signup.html"
+	TemplatePasswordReset = "password-reset.html"
+	TemplateAccountExists = "account-exists.html"
+)
+
+// TemplateData is what the templates in email-templates are rendered with.
+type TemplateData struct {
+	SiteURL   string
+	Email     string
+	TokenHash string
+}
+
+type Mailer struct {
+	config    *config.SMTPConfiguration
+	templates *template.Template
+}
+
+func NewMailer(config *config.SMTPConfiguration) (*Mailer, error) {
+	templates, err := template.ParseFS(emailtemplates.FS, "*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mailer{config: config, templates: templates}, nil
+}
+
+// Send renders the named template and mails it to the recipient. Without an
+// SMTP host the message is dropped, or logged when LogEmails is set for local
+// use.
+func (m *Mailer) Send(to string, subject string, templateName string, data TemplateData) error {
+	var body bytes.Buffer
+	if err := m.templates.ExecuteTemplate(&body, templateName, data); err != nil {
+		return err
+	}
+
+	if m.config.Host == "" {
+		log := logrus.WithFields(logrus.Fields{"component": "mailer", "to": to, "subject": subject})
+		if m.config.LogEmails {
+			log.Info(body.String())
+		} else {
+			// The body holds the token link, so it is never logged unless
+			// asked for.
+			log.Warn("no SMTP host configured, email not sent")
+		}
+		return nil
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.config.Sender)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.ReplaceAll(subject, "\n", " "))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n\r\n")
+	msg.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if m.config.User != "" {
+		auth = smtp.PlainAuth("", m.config.User, m.config.Pass, m.config.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.config.Host, m.config.Port), auth, m.config.Sender, []string{to}, msg.Bytes())
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoIP looks up countries in a MaxMind DB (.mmdb) file such as
+// GeoLite2-Country, held in memory.
+type GeoIP struct {
+	reader *maxminddb.Reader
+}
+
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// OpenGeoIP reads the database at path.
+func OpenGeoIP(path string) (*GeoIP, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGeoIP(buf)
+}
+
+func NewGeoIP(buf []byte) (*GeoIP, error) {
+	reader, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GeoIP{reader: reader}, nil
+}
+
+// Country returns the ISO 3166-1 code of the country ip is in, or "" when
+// unknown.
+func (g *GeoIP) Country(ip net.IP) string {
+	if g == nil || ip == nil {
+		return ""
+	}
+
+	var record geoIPRecord
+	if err := g.reader.Lookup(ip, &record); err != nil {
+		return ""
+	}
+
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode
+	}
+	return record.RegisteredCountry.ISOCode
+}
@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+// mmdbString, mmdbMap and mmdbUint encode MaxMind DB data section values.
+func mmdbString(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
+
+func mmdbMap(pairs ...[]byte) []byte {
+	b := []byte{0xe0 | byte(len(pairs)/2)}
+	for _, pair := range pairs {
+		b = append(b, pair...)
+	}
+	return b
+}
+
+func mmdbUint16(n uint16) []byte {
+	return []byte{0xa0 | 2, byte(n >> 8), byte(n)}
+}
+
+func mmdbUint32(n uint32) []byte {
+	return []byte{0xc0 | 4, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+// buildGeoIP returns an IPv4 database with 24 bit records mapping prefix/8 to
+// record, and every other address to nothing.
+func buildGeoIP(prefix byte, record []byte) []byte {
+	const nodeCount = 8
+
+	var tree []byte
+	for i := range nodeCount {
+		next := uint32(i + 1)
+		if i == nodeCount-1 {
+			// Data pointers are offset by the node count and the 16 byte
+			// separator.
+			next = nodeCount + 16
+		}
+		records := [2]uint32{nodeCount, nodeCount}
+		records[prefix>>(7-i)&1] = next
+		for _, r := range records {
+			tree = append(tree, byte(r>>16), byte(r>>8), byte(r))
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(tree)
+	buf.Write(make([]byte, 16))
+	buf.Write(record)
+	buf.WriteString("\xab\xcd\xefMaxMind.com")
+	buf.Write(mmdbMap(
+		mmdbString("binary_format_major_version"), mmdbUint16(2),
+		mmdbString("binary_format_minor_version"), mmdbUint16(0),
+		mmdbString("database_type"), mmdbString("Test-Country"),
+		mmdbString("ip_version"), mmdbUint16(4),
+		mmdbString("node_count"), mmdbUint32(nodeCount),
+		mmdbString("record_size"), mmdbUint16(24),
+	))
+
+	return buf.Bytes()
+}
+
+func TestGeoIPCountry(t *testing.T) {
+	tests := []struct {
+		name   string
+		record []byte
+		ip     string
+		want   string
+	}{
+		{
+			name:   "country",
+			record: mmdbMap(mmdbString("country"), mmdbMap(mmdbString("iso_code"), mmdbString("AU"))),
+			ip:     "1.2.3.4",
+			want:   "AU",
+		},
+		{
+			name:   "registered country only",
+			record: mmdbMap(mmdbString("registered_country"), mmdbMap(mmdbString("iso_code"), mmdbString("JP"))),
+			ip:     "1.255.0.1",
+			want:   "JP",
+		},
+		{
+			name:   "address outside the database",
+			record: mmdbMap(mmdbString("country"), mmdbMap(mmdbString("iso_code"), mmdbString("AU"))),
+			ip:     "2.2.3.4",
+		},
+		{
+			name:   "record without a country",
+			record: mmdbMap(mmdbString("continent"), mmdbMap(mmdbString("code"), mmdbString("OC"))),
+			ip:     "1.2.3.4",
+		},
+		{
+			// A pointer to itself must fail the lookup, not recurse.
+			name:   "self referencing pointer",
+			record: []byte{0x20, 0x00},
+			ip:     "1.2.3.4",
+		},
+		{
+			name:   "pointer past the data section",
+			record: []byte{0x27, 0xff},
+			ip:     "1.2.3.4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			geoip, err := NewGeoIP(buildGeoIP(1, tt.record))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := geoip.Country(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("Country(%s) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoIPInvalid(t *testing.T) {
+	valid := buildGeoIP(1, mmdbMap())
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{name: "empty", buf: nil},
+		{name: "no metadata", buf: valid[:bytes.Index(valid, []byte("\xab\xcd\xefMaxMind.com"))]},
+		{name: "truncated search tree", buf: valid[20:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGeoIP(tt.buf); err == nil {
+				t.Error("NewGeoIP() accepted an invalid database")
+			}
+		})
+	}
+}
+
+func TestGeoIPNil(t *testing.T) {
+	var geoip *GeoIP
+	if got := geoip.Country(net.ParseIP("1.2.3.4")); got != "" {
+		t.Errorf("Country() = %q without a database", got)
+	}
+}
@@ -0,0 +1,266 @@
+package pkg
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksMinRefreshInterval bounds how often a token with an unknown kid can
+// make us refetch the key set.
+const jwksMinRefreshInterval = 30 * time.Second
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// KeySet holds the public keys of a JWKS loaded from a URL or a file. Keys
+// are reloaded lazily once they are older than the refresh interval, or
+// sooner when a token names a kid we don't know yet. Concurrent reloads share
+// one fetch, made without holding the lock, so verifying tokens with known
+// keys never waits on the JWKS endpoint.
+type KeySet struct {
+	config *config.JWTConfiguration
+	client *http.Client
+	group  singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewKeySet(config *config.JWTConfiguration) *KeySet {
+	return &KeySet{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the public key with the given kid. An empty kid matches the
+// only key in the set.
+func (k *KeySet) Key(ctx context.Context, kid string) (any, error) {
+	k.mu.RLock()
+	key, known := k.lookup(kid)
+	stale := time.Since(k.fetchedAt) > k.config.JWKSRefreshInterval
+	k.mu.RUnlock()
+
+	// The fetch outlives the request that triggered it, and is shared with
+	// every caller waiting on it.
+	ctx = context.WithoutCancel(ctx)
+
+	if known {
+		if stale {
+			go k.tryRefresh(ctx)
+		}
+		return key, nil
+	}
+
+	k.tryRefresh(ctx)
+
+	k.mu.RLock()
+	key, known = k.lookup(kid)
+	k.mu.RUnlock()
+	if !known {
+		return nil, fmt.Errorf("no key with kid %q", kid)
+	}
+
+	return key, nil
+}
+
+// tryRefresh reloads the keys unless it was tried less than
+// jwksMinRefreshInterval ago, joining the reload in progress if there is one.
+func (k *KeySet) tryRefresh(ctx context.Context) {
+	_, err, _ := k.group.Do("jwks", func() (any, error) {
+		k.mu.Lock()
+		now := time.Now()
+		if now.Sub(k.attemptedAt) <= jwksMinRefreshInterval {
+			k.mu.Unlock()
+			return nil, nil
+		}
+		k.attemptedAt = now
+		k.mu.Unlock()
+
+		return nil, k.refresh(ctx)
+	})
+	if err != nil {
+		// Keep verifying with the keys we have until the JWKS is back.
+		logrus.WithError(err).WithField("component", "jwks").Warn("unable to refresh JWKS")
+	}
+}
+
+// lookup must be called with k.mu held.
+func (k *KeySet) lookup(kid string) (any, bool) {
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+func (k *KeySet) refresh(ctx context.Context) error {
+	data, err := k.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// parseJWKS returns the usable signing keys of a JWKS document by kid.
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		public, err := key.publicKey()
+		if err != nil {
+			logrus.WithError(err).WithField("kid", key.Kid).Warn("skipping JWKS key")
+			continue
+		}
+		keys[key.Kid] = public
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k *KeySet) read(ctx context.Context) ([]byte, error) {
+	if k.config.JWKSFile != "" {
+		return os.ReadFile(k.config.JWKSFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.config.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: unexpected status %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+func (j *jwk) publicKey() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,238 @@
+package pkg
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+)
+
+func TestParseJWKS(t *testing.T) {
+	keys := newTestKeys(t).jwks().Keys
+	rsaKey, ecKey, edKey := keys[0], keys[1], keys[2]
+
+	encryption := rsaKey
+	encryption.Kid = "enc"
+	encryption.Use = "enc"
+
+	offCurve := ecKey
+	offCurve.Kid = "off"
+	offCurve.Y = encodeBigInt(big.NewInt(1))
+
+	unsupportedCurve := ecKey
+	unsupportedCurve.Kid = "p224"
+	unsupportedCurve.Crv = "P-224"
+
+	shortEd := edKey
+	shortEd.Kid = "short"
+	shortEd.X = base64.RawURLEncoding.EncodeToString([]byte("short"))
+
+	tests := []struct {
+		name string
+		keys []jwk
+		kids []string
+		err  string
+	}{
+		{name: "every key type", keys: []jwk{rsaKey, ecKey, edKey}, kids: []string{"rsa", "ec", "ed"}},
+		{name: "encryption keys are skipped", keys: []jwk{rsaKey, encryption}, kids: []string{"rsa"}},
+		{name: "invalid keys are skipped", keys: []jwk{edKey, offCurve, unsupportedCurve, shortEd, {Kty: "oct", Kid: "oct"}}, kids: []string{"ed"}},
+		{name: "no usable keys", keys: []jwk{encryption, offCurve}, err: "no usable signing keys"},
+		{name: "empty", err: "no usable signing keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(jwks{Keys: tt.keys})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := parseJWKS(data)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("parseJWKS() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(parsed) != len(tt.kids) {
+				t.Errorf("parsed %d keys, want %v", len(parsed), tt.kids)
+			}
+			for _, kid := range tt.kids {
+				if _, ok := parsed[kid]; !ok {
+					t.Errorf("key %q is missing", kid)
+				}
+			}
+		})
+	}
+
+	if _, err := parseJWKS([]byte("{")); err == nil || !strings.Contains(err.Error(), "invalid JWKS") {
+		t.Errorf("parseJWKS() error = %v on malformed JSON", err)
+	}
+}
+
+// jwksServer serves set, counting requests. Requests wait on release when it
+// is set.
+type jwksServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	release  chan struct{}
+	status   atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, set jwks) *jwksServer {
+	t.Helper()
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &jwksServer{}
+	s.status.Store(http.StatusOK)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.release != nil {
+			<-s.release
+		}
+		w.WriteHeader(int(s.status.Load()))
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestKeySetSharesFetches(t *testing.T) {
+	server := newJWKSServer(t, newTestKeys(t).jwks())
+	server.release = make(chan struct{})
+
+	keys := NewKeySet(&config.JWTConfiguration{JWKSURL: server.URL, JWKSRefreshInterval: time.Hour})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keys.Key(context.Background(), "rsa")
+			errs <- err
+		}()
+	}
+
+	// Let every caller reach the fetch before it completes.
+	for server.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(server.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Key() error = %v", err)
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", n)
+	}
+}
+
+func TestKeySetServesKnownKeysDuringRefresh(t *testing.T) {
+	server := newJWKSServer(t, newTestKeys(t).jwks())
+	keys := NewKeySet(&config.JWTConfiguration{JWKSURL: server.URL, JWKSRefreshInterval: time.Hour})
+
+	if _, err := keys.Key(context.Background(), "rsa"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the next fetch hang, and allow it to start right away.
+	server.release = make(chan struct{})
+	defer close(server.release)
+	keys.mu.Lock()
+	keys.attemptedAt = time.Time{}
+	keys.mu.Unlock()
+
+	go keys.Key(context.Background(), "unknown")
+	for server.requests.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := keys.Key(context.Background(), "ec")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Key() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Key() waited on the JWKS fetch for a known kid")
+	}
+}
+
+func TestKeySetKeepsKeysWhenRefreshFails(t *testing.T) {
+	server := newJWKSServer(t, newTestKeys(t).jwks())
+	keys := NewKeySet(&config.JWTConfiguration{JWKSURL: server.URL, JWKSRefreshInterval: time.Hour})
+
+	if _, err := keys.Key(context.Background(), "rsa"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.status.Store(http.StatusInternalServerError)
+	keys.mu.Lock()
+	keys.attemptedAt = time.Time{}
+	keys.mu.Unlock()
+
+	if _, err := keys.Key(context.Background(), "unknown"); err == nil {
+		t.Error("Key() found an unknown kid")
+	}
+	if _, err := keys.Key(context.Background(), "rsa"); err != nil {
+		t.Errorf("Key() error = %v after a failed refresh", err)
+	}
+
+	// Unknown kids don't refetch more than once per jwksMinRefreshInterval.
+	requests := server.requests.Load()
+	keys.Key(context.Background(), "unknown")
+	if n := server.requests.Load(); n != requests {
+		t.Errorf("JWKS fetched %d more times within the minimum interval", n-requests)
+	}
+}
+
+func TestKeySetEmptyKid(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []jwk
+		found bool
+	}{
+		{name: "single key", keys: newTestKeys(t).jwks().Keys[:1], found: true},
+		{name: "several keys", keys: newTestKeys(t).jwks().Keys},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &config.JWTConfiguration{JWKSFile: writeJWKS(t, jwks{Keys: tt.keys}), JWKSRefreshInterval: time.Hour}
+
+			_, err := NewKeySet(conf).Key(context.Background(), "")
+			if tt.found != (err == nil) {
+				t.Errorf("Key(\"\") error = %v, want found %v", err, tt.found)
+			}
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package pkg
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/sirupsen/logrus"
 )
 
 var bearerRegexp = regexp.MustCompile(`^(?:B|b)earer (\S+$)`)
 
+var (
+	hmacMethods       = []string{"HS256", "HS384", "HS512"}
+	asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+)
+
 func ExtractBearerToken(ctx *gin.Context) (string, error) {
 	authHeader := ctx.Request.Header.Get("Authorization")
 	matches := bearerRegexp.FindStringSubmatch(authHeader)
@@ -21,15 +30,86 @@ func ExtractBearerToken(ctx *gin.Context) (string, error) {
 	return matches[1], nil
 }
 
-func ParseJWTClaims(bearer string, ctx *gin.Context, claims interface{}, secret string) (*jwt.Token, error) {
+// JWTVerifier verifies access tokens against the HS256 secrets and the JWKS
+// in JWTConfiguration, and checks their audience and issuer when configured.
+type JWTVerifier struct {
+	config  *config.JWTConfiguration
+	secrets [][]byte
+	keys    *KeySet
+	parser  *jwt.Parser
+}
+
+func NewJWTVerifier(conf *config.JWTConfiguration) *JWTVerifier {
+	v := &JWTVerifier{config: conf}
+
+	var methods []string
+	for _, secret := range append([]string{conf.Secret}, conf.Secrets...) {
+		if secret != "" {
+			v.secrets = append(v.secrets, []byte(secret))
+		}
+	}
+	if len(v.secrets) > 0 {
+		methods = append(methods, hmacMethods...)
+	}
+	if conf.JWKSURL != "" || conf.JWKSFile != "" {
+		v.keys = NewKeySet(conf)
+		methods = append(methods, asymmetricMethods...)
+	}
+
+	v.parser = &jwt.Parser{ValidMethods: methods}
+	return v
+}
+
+type audienceIssuerClaims interface {
+	VerifyAudience(cmp string, req bool) bool
+	VerifyIssuer(cmp string, req bool) bool
+}
 
-	p := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Name}}
-	token, err := p.ParseWithClaims(bearer, &config.AccessTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
+// ParseClaims verifies bearer and decodes it into claims, which must be a
+// pointer.
+func (v *JWTVerifier) ParseClaims(ctx context.Context, bearer string, claims jwt.Claims) (*jwt.Token, error) {
+	token, err := v.parser.ParseWithClaims(bearer, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.key(ctx, token)
 	})
 	if err != nil {
+		logrus.WithError(err).Debug("rejected JWT")
 		return nil, errors.New("Invalid JWT: token may be expired or system is unable to parse and verify signature")
 	}
 
+	if c, ok := claims.(audienceIssuerClaims); ok {
+		if v.config.Audience != "" && !c.VerifyAudience(v.config.Audience, true) {
+			return nil, errors.New("Invalid JWT: unexpected audience")
+		}
+		if v.config.Issuer != "" && !c.VerifyIssuer(v.config.Issuer, true) {
+			return nil, errors.New("Invalid JWT: unexpected issuer")
+		}
+	}
+
 	return token, nil
 }
+
+func (v *JWTVerifier) key(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		// Pick whichever of the rotated secrets the token was signed with.
+		if i := strings.LastIndex(token.Raw, "."); i > 0 {
+			for _, secret := range v.secrets {
+				if token.Method.Verify(token.Raw[:i], token.Raw[i+1:], secret) == nil {
+					return secret, nil
+				}
+			}
+		}
+		return v.secrets[0], nil
+	}
+
+	if v.keys == nil {
+		return nil, fmt.Errorf("no JWKS configured for %s", token.Method.Alg())
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	return v.keys.Key(ctx, kid)
+}
+
+// SignAccessToken signs claims with the HS256 secret JWTVerifier accepts.
+func SignAccessToken(claims *config.AccessTokenClaims, secret string) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
@@ -0,0 +1,223 @@
+package pkg
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+)
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// testKeys are one key of each type the JWKS loader supports.
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testKeys{rsa: rsaKey, ecdsa: ecKey, ed25519: edKey}
+}
+
+func (k *testKeys) jwks() jwks {
+	return jwks{Keys: []jwk{
+		{Kty: "RSA", Kid: "rsa", Use: "sig", N: encodeBigInt(k.rsa.N), E: encodeBigInt(big.NewInt(int64(k.rsa.E)))},
+		{Kty: "EC", Kid: "ec", Crv: "P-256", X: encodeBigInt(k.ecdsa.X), Y: encodeBigInt(k.ecdsa.Y)},
+		{Kty: "OKP", Kid: "ed", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(k.ed25519.Public().(ed25519.PublicKey))},
+	}}
+}
+
+func writeJWKS(t *testing.T, set jwks) string {
+	t.Helper()
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func validClaims() *jwt.StandardClaims {
+	return &jwt.StandardClaims{
+		Subject:   "user",
+		Audience:  "authenticated",
+		Issuer:    "https://auth.example.com",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	keys := newTestKeys(t)
+	other := newTestKeys(t)
+
+	conf := &config.JWTConfiguration{
+		Secret:              "current",
+		Secrets:             []string{"previous"},
+		JWKSFile:            writeJWKS(t, keys.jwks()),
+		JWKSRefreshInterval: time.Hour,
+		Audience:            "authenticated",
+		Issuer:              "https://auth.example.com",
+	}
+
+	expired := validClaims()
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	wrongAudience := validClaims()
+	wrongAudience.Audience = "anon"
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com"
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"HS256 with the current secret", sign(t, jwt.SigningMethodHS256, "", []byte("current"), validClaims()), true},
+		{"HS256 with a rotated secret", sign(t, jwt.SigningMethodHS256, "", []byte("previous"), validClaims()), true},
+		{"HS512 with the current secret", sign(t, jwt.SigningMethodHS512, "", []byte("current"), validClaims()), true},
+		{"HS256 with an unknown secret", sign(t, jwt.SigningMethodHS256, "", []byte("unknown"), validClaims()), false},
+		{"RS256 from the JWKS", sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims()), true},
+		{"PS256 from the JWKS", sign(t, jwt.SigningMethodPS256, "rsa", keys.rsa, validClaims()), true},
+		{"ES256 from the JWKS", sign(t, jwt.SigningMethodES256, "ec", keys.ecdsa, validClaims()), true},
+		{"EdDSA from the JWKS", sign(t, jwt.SigningMethodEdDSA, "ed", keys.ed25519, validClaims()), true},
+		{"RS256 signed with another key", sign(t, jwt.SigningMethodRS256, "rsa", other.rsa, validClaims()), false},
+		{"RS256 with an unknown kid", sign(t, jwt.SigningMethodRS256, "unknown", keys.rsa, validClaims()), false},
+		{"RS256 with the kid of another key type", sign(t, jwt.SigningMethodRS256, "ec", keys.rsa, validClaims()), false},
+		{"none algorithm", sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()), false},
+		{"expired", sign(t, jwt.SigningMethodHS256, "", []byte("current"), expired), false},
+		{"unexpected audience", sign(t, jwt.SigningMethodHS256, "", []byte("current"), wrongAudience), false},
+		{"unexpected issuer", sign(t, jwt.SigningMethodHS256, "", []byte("current"), wrongIssuer), false},
+		{"malformed", "not.a.token", false},
+	}
+
+	verifier := NewJWTVerifier(conf)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.ParseClaims(context.Background(), tt.token, &jwt.StandardClaims{})
+			if tt.valid && err != nil {
+				t.Errorf("ParseClaims() error = %v, want a valid token", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("ParseClaims() accepted an invalid token")
+			}
+		})
+	}
+}
+
+func TestJWTVerifierAlgorithms(t *testing.T) {
+	keys := newTestKeys(t)
+	hs256 := sign(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims())
+	rs256 := sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims())
+
+	tests := []struct {
+		name   string
+		conf   *config.JWTConfiguration
+		token  string
+		valid  bool
+		reason string
+	}{
+		{
+			name:  "HS256 without a JWKS",
+			conf:  &config.JWTConfiguration{Secret: "secret"},
+			token: hs256,
+			valid: true,
+		},
+		{
+			name:   "RS256 without a JWKS",
+			conf:   &config.JWTConfiguration{Secret: "secret"},
+			token:  rs256,
+			reason: "asymmetric algorithms need a JWKS",
+		},
+		{
+			name:  "RS256 with only a JWKS",
+			conf:  &config.JWTConfiguration{JWKSFile: writeJWKS(t, keys.jwks()), JWKSRefreshInterval: time.Hour},
+			token: rs256,
+			valid: true,
+		},
+		{
+			name:   "HS256 with only a JWKS",
+			conf:   &config.JWTConfiguration{JWKSFile: writeJWKS(t, keys.jwks()), JWKSRefreshInterval: time.Hour},
+			token:  hs256,
+			reason: "HMAC must not be accepted without a secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTVerifier(tt.conf).ParseClaims(context.Background(), tt.token, &jwt.StandardClaims{})
+			if tt.valid && err != nil {
+				t.Errorf("ParseClaims() error = %v, want a valid token", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("ParseClaims() accepted the token: %s", tt.reason)
+			}
+		})
+	}
+}
+
+func TestSignAccessToken(t *testing.T) {
+	claims := &config.AccessTokenClaims{StandardClaims: *validClaims(), Role: "authenticated"}
+
+	signed, err := SignAccessToken(claims, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := &config.AccessTokenClaims{}
+	verifier := NewJWTVerifier(&config.JWTConfiguration{Secret: "secret"})
+	if _, err := verifier.ParseClaims(context.Background(), signed, parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Subject != "user" || parsed.Role != "authenticated" {
+		t.Errorf("parsed claims = %+v", parsed)
+	}
+}
@@ -27,6 +27,9 @@ type Reloader struct {
 	watchDir   string
 	reloadIval time.Duration
 	tickerIval time.Duration
+
+	// OnError, if set, is called with every failed reload attempt.
+	OnError func(error)
 }
 
 func NewReloader(watchDir string) *Reloader {
@@ -106,6 +109,9 @@ func (rl *Reloader) Watch(ctx context.Context, fn ConfigFunc) error {
 			cfg, err := rl.reload()
 			if err != nil {
 				logrus.WithError(err).Error("config reload failed")
+				if rl.OnError != nil {
+					rl.OnError(err)
+				}
 				continue
 			}
 
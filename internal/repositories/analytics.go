@@ -0,0 +1,360 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type RepositoryAnalytics interface {
+	InsertBlogViews(views []schemas.BlogView) (int64, error)
+	GetBlogDailyStats(blogId uint, from time.Time, to time.Time) ([]schemas.SelectBlogDailyStats, error)
+	CountBlogReaders(blogId uint, from time.Time, to time.Time) (int64, error)
+	GetBlogReferrers(blogId uint, from time.Time, to time.Time, limit int) ([]schemas.SelectReferrerCount, error)
+	DeleteBlogViews(before time.Time) (int64, error)
+	InsertPortfolioVisits(visits []schemas.PortfolioVisit) (int64, error)
+	GetPortfolioDailyStats(userId string, from time.Time, to time.Time) ([]schemas.SelectPortfolioDailyStats, error)
+	CountPortfolioVisitors(userId string, from time.Time, to time.Time) (int64, error)
+	GetPortfolioBreakdown(userId string, dimension string, from time.Time, to time.Time, limit int) ([]schemas.SelectAnalyticsBreakdown, error)
+	GetPortfolioViewers(userId string, from time.Time, to time.Time, limit int) ([]schemas.SelectPortfolioViewer, error)
+	DeletePortfolioVisits(before time.Time) (int64, error)
+	GetOrCreateSalt(day string, salt []byte) ([]byte, error)
+	DeleteSalts(before time.Time) (int64, error)
+}
+
+// portfolioDimensions are the columns portfolio visits can be broken down by.
+var portfolioDimensions = map[string]bool{
+	"module":        true,
+	"referrer_host": true,
+	"country":       true,
+}
+
+type repositoryAnalytics struct {
+	db *gorm.DB
+}
+
+// InsertBlogViews records the views of published blogs and adds them to the
+// views_count of the blogs, skipping views already recorded that day and
+// authors reading their own blogs. It returns the number of views recorded.
+func (r *repositoryAnalytics) InsertBlogViews(views []schemas.BlogView) (int64, error) {
+	slugs := make(pq.StringArray, len(views))
+	days := make(pq.StringArray, len(views))
+	viewers := make(pq.StringArray, len(views))
+	readerIds := make(pq.StringArray, len(views))
+	referrers := make(pq.StringArray, len(views))
+	for i, view := range views {
+		slugs[i] = view.Slug
+		days[i] = view.Day
+		viewers[i] = view.Viewer
+		readerIds[i] = view.ReaderId
+		referrers[i] = view.Referrer
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO blog_views (blog_id, day, viewer, referrer)
+			SELECT blogs.id, v.day, v.viewer, nullif(v.referrer, '')
+			FROM unnest(?::text[], ?::date[], ?::text[], ?::text[], ?::text[]) AS v(slug, day, viewer, reader_id, referrer)
+			INNER JOIN blogs ON blogs.slug = v.slug
+			WHERE blogs.published_at IS NOT NULL
+				AND blogs.deleted_at IS NULL
+				AND nullif(v.reader_id, '')::uuid IS DISTINCT FROM blogs.user_id
+			ON CONFLICT DO NOTHING
+			RETURNING blog_id
+		), counted AS (
+			UPDATE blogs
+			SET attributes = jsonb_set(
+				blogs.attributes,
+				'{views_count}',
+				to_jsonb(coalesce((blogs.attributes ->> 'views_count')::bigint, 0) + c.views),
+				true
+			)
+			FROM (SELECT blog_id, count(*) AS views FROM inserted GROUP BY blog_id) c
+			WHERE blogs.id = c.blog_id
+		)
+		SELECT count(*) FROM inserted
+	`
+
+	var recorded int64
+	if err := r.db.Raw(query, slugs, days, viewers, readerIds, referrers).Row().Scan(&recorded); err != nil {
+		return 0, err
+	}
+
+	return recorded, nil
+}
+
+// GetBlogDailyStats returns a row for every day from from to to, inclusive,
+// with the views, reactions and comments of that day.
+func (r *repositoryAnalytics) GetBlogDailyStats(blogId uint, from time.Time, to time.Time) ([]schemas.SelectBlogDailyStats, error) {
+	var stats []schemas.SelectBlogDailyStats
+
+	end := to.AddDate(0, 0, 1)
+
+	query := `
+		SELECT
+			to_char(d.day, 'YYYY-MM-DD') AS day,
+			coalesce(v.views, 0) AS views,
+			coalesce(r.reactions, 0) AS reactions,
+			coalesce(c.comments, 0) AS comments
+		FROM generate_series(?::date, ?::date, interval '1 day') AS d(day)
+		LEFT JOIN (
+			SELECT day, count(*) AS views
+			FROM blog_views
+			WHERE blog_id = ? AND day >= ?::date AND day <= ?::date
+			GROUP BY day
+		) v ON v.day = d.day::date
+		LEFT JOIN (
+			SELECT (created_at AT TIME ZONE 'UTC')::date AS day, count(*) AS reactions
+			FROM blog_reactions
+			WHERE blog_id = ? AND created_at >= ? AND created_at < ?
+			GROUP BY 1
+		) r ON r.day = d.day::date
+		LEFT JOIN (
+			SELECT (comments.created_at AT TIME ZONE 'UTC')::date AS day, count(*) AS comments
+			FROM blog_comments
+			INNER JOIN comments ON comments.id = blog_comments.comment_id
+			WHERE blog_comments.blog_id = ? AND comments.deleted_at IS NULL AND comments.created_at >= ? AND comments.created_at < ?
+			GROUP BY 1
+		) c ON c.day = d.day::date
+		ORDER BY d.day
+	`
+
+	fromDay, toDay := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	args := []any{fromDay, toDay, blogId, fromDay, toDay, blogId, from, end, blogId, from, end}
+
+	if err := r.db.Raw(query, args...).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// CountBlogReaders counts the distinct viewers over the period. Signed in
+// readers are counted once; anonymous ones once per day.
+func (r *repositoryAnalytics) CountBlogReaders(blogId uint, from time.Time, to time.Time) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT count(DISTINCT viewer)
+		FROM blog_views
+		WHERE blog_id = ? AND day >= ?::date AND day <= ?::date
+	`
+
+	if err := r.db.Raw(query, blogId, from.Format(time.DateOnly), to.Format(time.DateOnly)).Row().Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *repositoryAnalytics) GetBlogReferrers(blogId uint, from time.Time, to time.Time, limit int) ([]schemas.SelectReferrerCount, error) {
+	var referrers []schemas.SelectReferrerCount
+
+	query := `
+		SELECT referrer, count(*) AS views
+		FROM blog_views
+		WHERE blog_id = ? AND day >= ?::date AND day <= ?::date AND referrer IS NOT NULL
+		GROUP BY referrer
+		ORDER BY views DESC, referrer
+		LIMIT ?
+	`
+
+	if err := r.db.Raw(query, blogId, from.Format(time.DateOnly), to.Format(time.DateOnly), limit).Scan(&referrers).Error; err != nil {
+		return nil, err
+	}
+
+	return referrers, nil
+}
+
+func (r *repositoryAnalytics) DeleteBlogViews(before time.Time) (int64, error) {
+	res := r.db.Exec("DELETE FROM blog_views WHERE day < ?::date", before.Format(time.DateOnly))
+	return res.RowsAffected, res.Error
+}
+
+// InsertPortfolioVisits records visits of portfolios, skipping visits already
+// recorded that day and users visiting their own portfolio. The viewer is
+// only kept for visitors who share their portfolio views. It returns the
+// number of visits recorded.
+func (r *repositoryAnalytics) InsertPortfolioVisits(visits []schemas.PortfolioVisit) (int64, error) {
+	slugs := make(pq.StringArray, len(visits))
+	modules := make(pq.StringArray, len(visits))
+	days := make(pq.StringArray, len(visits))
+	visitors := make(pq.StringArray, len(visits))
+	viewerIds := make(pq.StringArray, len(visits))
+	referrerHosts := make(pq.StringArray, len(visits))
+	countries := make(pq.StringArray, len(visits))
+	for i, visit := range visits {
+		slugs[i] = visit.Slug
+		modules[i] = visit.Module
+		days[i] = visit.Day
+		visitors[i] = visit.Visitor
+		viewerIds[i] = visit.ViewerId
+		referrerHosts[i] = visit.ReferrerHost
+		countries[i] = visit.Country
+	}
+
+	query := `
+		INSERT INTO portfolio_visits (user_id, day, visitor, module, viewer_id, referrer_host, country)
+		SELECT
+			p.user_id,
+			v.day,
+			v.visitor,
+			v.module,
+			CASE WHEN viewer.share_portfolio_views THEN viewer.user_id END,
+			nullif(v.referrer_host, ''),
+			nullif(v.country, '')
+		FROM unnest(?::text[], ?::text[], ?::date[], ?::text[], ?::text[], ?::text[], ?::text[])
+			AS v(slug, module, day, visitor, viewer_id, referrer_host, country)
+		INNER JOIN user_profiles p ON p.slug = v.slug AND p.deleted_at IS NULL
+		LEFT JOIN user_profiles viewer ON viewer.user_id = nullif(v.viewer_id, '')::uuid
+		WHERE nullif(v.viewer_id, '')::uuid IS DISTINCT FROM p.user_id
+		ON CONFLICT DO NOTHING
+	`
+
+	res := r.db.Exec(query, slugs, modules, days, visitors, viewerIds, referrerHosts, countries)
+	return res.RowsAffected, res.Error
+}
+
+// GetPortfolioDailyStats returns a row for every day from from to to,
+// inclusive, with the visits and visitors of that day.
+func (r *repositoryAnalytics) GetPortfolioDailyStats(userId string, from time.Time, to time.Time) ([]schemas.SelectPortfolioDailyStats, error) {
+	var stats []schemas.SelectPortfolioDailyStats
+
+	query := `
+		SELECT
+			to_char(d.day, 'YYYY-MM-DD') AS day,
+			coalesce(v.views, 0) AS views,
+			coalesce(v.visitors, 0) AS visitors
+		FROM generate_series(?::date, ?::date, interval '1 day') AS d(day)
+		LEFT JOIN (
+			SELECT day, count(*) AS views, count(DISTINCT visitor) AS visitors
+			FROM portfolio_visits
+			WHERE user_id = ? AND day >= ?::date AND day <= ?::date
+			GROUP BY day
+		) v ON v.day = d.day::date
+		ORDER BY d.day
+	`
+
+	fromDay, toDay := from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	if err := r.db.Raw(query, fromDay, toDay, userId, fromDay, toDay).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// CountPortfolioVisitors counts the visitors over the period. Visitors are
+// hashed with a daily salt, so they are counted once per day.
+func (r *repositoryAnalytics) CountPortfolioVisitors(userId string, from time.Time, to time.Time) (int64, error) {
+	var count int64
+
+	query := `
+		SELECT count(DISTINCT (day, visitor))
+		FROM portfolio_visits
+		WHERE user_id = ? AND day >= ?::date AND day <= ?::date
+	`
+
+	if err := r.db.Raw(query, userId, from.Format(time.DateOnly), to.Format(time.DateOnly)).Row().Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetPortfolioBreakdown returns the top values of dimension, one of module,
+// referrer_host or country, by visits.
+func (r *repositoryAnalytics) GetPortfolioBreakdown(userId string, dimension string, from time.Time, to time.Time, limit int) ([]schemas.SelectAnalyticsBreakdown, error) {
+	if !portfolioDimensions[dimension] {
+		return nil, fmt.Errorf("unknown portfolio dimension %q", dimension)
+	}
+
+	var breakdown []schemas.SelectAnalyticsBreakdown
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s AS value, count(*) AS views, count(DISTINCT (day, visitor)) AS visitors
+		FROM portfolio_visits
+		WHERE user_id = ? AND day >= ?::date AND day <= ?::date AND %[1]s IS NOT NULL
+		GROUP BY %[1]s
+		ORDER BY views DESC, value
+		LIMIT ?
+	`, dimension)
+
+	if err := r.db.Raw(query, userId, from.Format(time.DateOnly), to.Format(time.DateOnly), limit).Scan(&breakdown).Error; err != nil {
+		return nil, err
+	}
+
+	return breakdown, nil
+}
+
+// GetPortfolioViewers returns the signed in visitors who still share their
+// portfolio views, most recent first.
+func (r *repositoryAnalytics) GetPortfolioViewers(userId string, from time.Time, to time.Time, limit int) ([]schemas.SelectPortfolioViewer, error) {
+	var viewers []schemas.SelectPortfolioViewer
+
+	query := `
+		SELECT
+			v.viewer_id AS user_id,
+			p.full_name AS name,
+			p.avatar_url AS avatar,
+			CASE WHEN p.portfolio_status = 'ACTIVE' THEN p.slug END AS slug,
+			to_char(max(v.day), 'YYYY-MM-DD') AS last_visited_on,
+			count(*) AS visits
+		FROM portfolio_visits v
+		INNER JOIN user_profiles p ON p.user_id = v.viewer_id
+		WHERE v.user_id = ? AND v.day >= ?::date AND v.day <= ?::date
+			AND p.share_portfolio_views AND p.deleted_at IS NULL
+		GROUP BY v.viewer_id, p.full_name, p.avatar_url, p.portfolio_status, p.slug
+		ORDER BY max(v.day) DESC, visits DESC
+		LIMIT ?
+	`
+
+	if err := r.db.Raw(query, userId, from.Format(time.DateOnly), to.Format(time.DateOnly), limit).Scan(&viewers).Error; err != nil {
+		return nil, err
+	}
+
+	return viewers, nil
+}
+
+func (r *repositoryAnalytics) DeletePortfolioVisits(before time.Time) (int64, error) {
+	res := r.db.Exec("DELETE FROM portfolio_visits WHERE day < ?::date", before.Format(time.DateOnly))
+	return res.RowsAffected, res.Error
+}
+
+// GetOrCreateSalt returns the salt of day, storing salt as the salt of day
+// if it has none yet.
+func (r *repositoryAnalytics) GetOrCreateSalt(day string, salt []byte) ([]byte, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO analytics_salts (day, salt)
+			VALUES (?::date, ?)
+			ON CONFLICT DO NOTHING
+			RETURNING salt
+		)
+		SELECT salt FROM inserted
+		UNION ALL
+		SELECT salt FROM analytics_salts WHERE day = ?::date
+		LIMIT 1
+	`
+
+	var stored []byte
+	if err := r.db.Raw(query, day, salt, day).Row().Scan(&stored); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func (r *repositoryAnalytics) DeleteSalts(before time.Time) (int64, error) {
+	res := r.db.Exec("DELETE FROM analytics_salts WHERE day < ?::date", before.Format(time.DateOnly))
+	return res.RowsAffected, res.Error
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *repositoryAnalytics {
+	return &repositoryAnalytics{
+		db: db,
+	}
+}
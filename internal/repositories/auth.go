@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RepositoryAuth interface {
+	GetUserByEmail(email string) (*models.AuthUser, error)
+	GetUserById(userId uuid.UUID) (*models.AuthUser, error)
+	CreateUser(email string, encryptedPassword string, confirmed bool) (*models.AuthUser, error)
+	ConfirmEmail(userId uuid.UUID) error
+	UpdatePassword(userId uuid.UUID, encryptedPassword string) error
+	UpdateLastSignIn(userId uuid.UUID) error
+	CreateOneTimeToken(userId uuid.UUID, tokenType models.AuthOneTimeTokenType, tokenHash string, expiresAt time.Time) error
+	ConsumeOneTimeToken(tokenType models.AuthOneTimeTokenType, tokenHash string) (uuid.UUID, error)
+	CreateSession(userId uuid.UUID, userAgent string, ip string) (*models.AuthSession, error)
+	GetSession(sessionId uuid.UUID) (*models.AuthSession, error)
+	DeleteSession(sessionId uuid.UUID) error
+	DeleteUserSessions(userId uuid.UUID, except *uuid.UUID) ([]uuid.UUID, error)
+	CreateRefreshToken(sessionId uuid.UUID, tokenHash string, expiresAt time.Time) error
+	GetRefreshToken(tokenHash string) (*models.AuthRefreshToken, error)
+	RevokeRefreshToken(id uint) (int64, error)
+	DeleteExpiredTokens() (int64, error)
+}
+
+type repositoryAuth struct {
+	db *gorm.DB
+}
+
+func (r *repositoryAuth) GetUserByEmail(email string) (*models.AuthUser, error) {
+	var user models.AuthUser
+
+	if err := r.db.Where("lower(email) = lower(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *repositoryAuth) GetUserById(userId uuid.UUID) (*models.AuthUser, error) {
+	var user models.AuthUser
+
+	if err := r.db.Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// CreateUser inserts into auth.users the way Supabase does, so the trigger
+// creating the user profile runs for self-hosted users too. The token
+// columns are set to empty strings because Supabase can't read null ones.
+func (r *repositoryAuth) CreateUser(email string, encryptedPassword string, confirmed bool) (*models.AuthUser, error) {
+	var user models.AuthUser
+
+	query := `
+		INSERT INTO auth.users (
+			instance_id, id, aud, role, email, encrypted_password, email_confirmed_at,
+			confirmation_token, recovery_token, email_change_token_new, email_change,
+			raw_app_meta_data, raw_user_meta_data, created_at, updated_at
+		)
+		VALUES (
+			'00000000-0000-0000-0000-000000000000', gen_random_uuid(), 'authenticated', 'authenticated', lower(?), ?,
+			CASE WHEN ? THEN now() END,
+			'', '', '', '',
+			'{"provider": "email", "providers": ["email"]}', '{}', now(), now()
+		)
+		RETURNING id, aud, role, email, encrypted_password, email_confirmed_at, last_sign_in_at,
+			raw_app_meta_data, raw_user_meta_data, created_at, updated_at
+	`
+
+	if err := r.db.Raw(query, email, encryptedPassword, confirmed).Scan(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *repositoryAuth) ConfirmEmail(userId uuid.UUID) error {
+	return r.db.Model(&models.AuthUser{}).Where("id = ? and email_confirmed_at is null", userId).Updates(map[string]any{
+		"email_confirmed_at": gorm.Expr("now()"),
+		"updated_at":         gorm.Expr("now()"),
+	}).Error
+}
+
+func (r *repositoryAuth) UpdatePassword(userId uuid.UUID, encryptedPassword string) error {
+	return r.db.Model(&models.AuthUser{}).Where("id = ?", userId).Updates(map[string]any{
+		"encrypted_password": encryptedPassword,
+		"updated_at":         gorm.Expr("now()"),
+	}).Error
+}
+
+func (r *repositoryAuth) UpdateLastSignIn(userId uuid.UUID) error {
+	return r.db.Model(&models.AuthUser{}).Where("id = ?", userId).Updates(map[string]any{
+		"last_sign_in_at": gorm.Expr("now()"),
+	}).Error
+}
+
+// CreateOneTimeToken replaces any outstanding token of the same type, so only
+// the latest email sent works.
+func (r *repositoryAuth) CreateOneTimeToken(userId uuid.UUID, tokenType models.AuthOneTimeTokenType, tokenHash string, expiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? and token_type = ?", userId, tokenType).Delete(&models.AuthOneTimeToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.AuthOneTimeToken{
+			UserId:    userId,
+			TokenType: tokenType,
+			TokenHash: tokenHash,
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+}
+
+// ConsumeOneTimeToken deletes the token and returns its user, or
+// gorm.ErrRecordNotFound if it doesn't exist or has expired.
+func (r *repositoryAuth) ConsumeOneTimeToken(tokenType models.AuthOneTimeTokenType, tokenHash string) (uuid.UUID, error) {
+	var tokens []models.AuthOneTimeToken
+
+	if err := r.db.Clauses(clause.Returning{}).
+		Where("token_type = ? and token_hash = ? and expires_at > now()", tokenType, tokenHash).
+		Delete(&tokens).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	if len(tokens) == 0 {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+
+	return tokens[0].UserId, nil
+}
+
+func (r *repositoryAuth) CreateSession(userId uuid.UUID, userAgent string, ip string) (*models.AuthSession, error) {
+	session := models.AuthSession{
+		UserId:    userId,
+		UserAgent: &userAgent,
+		IP:        &ip,
+	}
+
+	if err := r.db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *repositoryAuth) GetSession(sessionId uuid.UUID) (*models.AuthSession, error) {
+	var session models.AuthSession
+
+	if err := r.db.Where("id = ?", sessionId).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *repositoryAuth) DeleteSession(sessionId uuid.UUID) error {
+	return r.db.Where("id = ?", sessionId).Delete(&models.AuthSession{}).Error
+}
+
+// DeleteUserSessions deletes the user's sessions, except the one given, and
+// returns the ids of the deleted ones.
+func (r *repositoryAuth) DeleteUserSessions(userId uuid.UUID, except *uuid.UUID) ([]uuid.UUID, error) {
+	var sessions []models.AuthSession
+
+	query := r.db.Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).Where("user_id = ?", userId)
+	if except != nil {
+		query = query.Where("id <> ?", *except)
+	}
+
+	if err := query.Delete(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+
+	return ids, nil
+}
+
+func (r *repositoryAuth) CreateRefreshToken(sessionId uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	if err := r.db.Create(&models.AuthRefreshToken{
+		SessionId: sessionId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.AuthSession{}).Where("id = ?", sessionId).Update("refreshed_at", gorm.Expr("now()")).Error
+}
+
+func (r *repositoryAuth) GetRefreshToken(tokenHash string) (*models.AuthRefreshToken, error) {
+	var token models.AuthRefreshToken
+
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RevokeRefreshToken returns the number of tokens revoked; 0 means it was
+// already revoked by a concurrent refresh or is being reused.
+func (r *repositoryAuth) RevokeRefreshToken(id uint) (int64, error) {
+	res := r.db.Model(&models.AuthRefreshToken{}).Where("id = ? and not revoked", id).Update("revoked", true)
+	return res.RowsAffected, res.Error
+}
+
+// DeleteExpiredTokens removes expired one-time and refresh tokens.
+func (r *repositoryAuth) DeleteExpiredTokens() (int64, error) {
+	var deleted int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("expires_at < now()").Delete(&models.AuthOneTimeToken{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted += res.RowsAffected
+
+		res = tx.Where("expires_at < now()").Delete(&models.AuthRefreshToken{})
+		deleted += res.RowsAffected
+		return res.Error
+	})
+
+	return deleted, err
+}
+
+func NewAuthRepository(db *gorm.DB) *repositoryAuth {
+	return &repositoryAuth{
+		db: db,
+	}
+}
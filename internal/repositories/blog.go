@@ -15,13 +15,20 @@ import (
 )
 
 type RepositoryBlog interface {
-	GetAll(userId *string, query *string, cursor int, limit int) (any, error)
+	GetAll(userId *string, query *string, tagId *uint, sort string, cursor int, limit int) (any, error)
 	GetUserBlogs(userId *string, query *string, cursor int, limit int) (any, error)
+	CountAll(query *string, tagId *uint) (int64, error)
+	CountUserBlogs(userId string, query *string) (int64, error)
 	Get(userId string, id string) (any, error)
 	GetBlogBySlug(userId *string, slug string) (*schemas.SchemaBlog, error)
+	GetOwnerIdBySlug(slug string) (string, error)
+	GetOwnerIdById(id uint) (string, error)
+	GetPublishedIdBySlug(slug string) (uint, error)
+	GetUserBlogIdBySlug(userId string, slug string) (uint, error)
+	GetRelated(blogId uint, limit int) ([]schemas.SelectRelatedBlog, error)
 	Create(userId string, tags *models.Tags, data *schemas.SchemaBlog, publish bool) (*models.Blog, error)
 	Update(userId string, id string, tags *models.Tags, data *schemas.SchemaBlog, publish bool) (*models.Blog, error)
-	Unpublish(userId string, id string) error
+	Unpublish(userId string, id string) (*models.Blog, error)
 	Delete(userId string, id string) error
 	GetCommentBlog(id uint) (*models.BlogComment, error)
 	CreateComment(blogId uint, commentId uint) (any, error)
@@ -34,7 +41,7 @@ type repositoryBlog struct {
 	db *gorm.DB
 }
 
-func (r *repositoryBlog) GetAll(userId *string, query *string, cursor int, limit int) (*[]schemas.SelectBlog, error) {
+func (r *repositoryBlog) GetAll(userId *string, query *string, tagId *uint, sort string, cursor int, limit int) (*[]schemas.SelectBlog, error) {
 	var rows *sql.Rows
 	var err error
 	var args []any
@@ -92,24 +99,23 @@ func (r *repositoryBlog) GetAll(userId *string, query *string, cursor int, limit
 	baseQuery += `
 		where
 			blogs.published_at is not null
-			group by
-				blogs.id,
-				user_profiles.user_id
 	`
 
-	args = append(args, limit, cursor)
-
 	if query != nil && *query != "" {
-		baseQuery += " AND blogs.fts @@ to_tsquery(?)"
-		args = append([]interface{}{*query}, args...)
+		baseQuery += " AND blogs.fts @@ websearch_to_tsquery('english', ?)"
+		args = append(args, *query)
 	}
 
-	// baseQuery += `
-	// 	group by
-	// 		blogs.id,
-	// 		user_profiles.user_id
+	if tagId != nil {
+		baseQuery += " AND blogs.id IN (SELECT blog_tags.blog_id FROM blog_tags WHERE blog_tags.tag_id = ?)"
+		args = append(args, *tagId)
+	}
 
-	// `
+	baseQuery += `
+		group by
+			blogs.id,
+			user_profiles.user_id
+	`
 
 	if userId != nil {
 		baseQuery += `
@@ -118,9 +124,10 @@ func (r *repositoryBlog) GetAll(userId *string, query *string, cursor int, limit
 	}
 
 	baseQuery += `
-		ORDER BY blogs.updated_at DESC
+		ORDER BY ` + sortOrder(sort, "blogs", "id") + `
 		LIMIT ? OFFSET ?
 	`
+	args = append(args, limit, cursor)
 
 	rows, err = r.db.Raw(baseQuery, args...).Rows()
 
@@ -156,6 +163,7 @@ func (r *repositoryBlog) GetUserBlogs(userId string, query *string, cursor int,
 			blogs.slug,
 			blogs.attributes ->> 'comments_count' as comments_count,
 			blogs.attributes -> 'reaction_metadata' as reactions_metadata,
+			blogs.attributes ->> 'views_count' as views_count,
 			blogs.published_at,
 			blogs.created_at,
 			blogs.updated_at,	
@@ -172,7 +180,7 @@ func (r *repositoryBlog) GetUserBlogs(userId string, query *string, cursor int,
 	args = append(args, limit, cursor)
 
 	if query != nil && *query != "" {
-		baseQuery += " AND blogs.fts @@ to_tsquery(?)"
+		baseQuery += " AND blogs.fts @@ websearch_to_tsquery('english', ?)"
 		args = append([]interface{}{*query}, args...)
 	}
 
@@ -196,7 +204,7 @@ func (r *repositoryBlog) GetUserBlogs(userId string, query *string, cursor int,
 	for rows.Next() {
 		var blog schemas.SelectBlog
 
-		err = rows.Scan(&blog.ID, &blog.CoverImage, &blog.Title, &blog.Slug, &blog.CommentsCount, &blog.ReactionsMetadata, &blog.PublishedAt, &blog.CreatedAt, &blog.UpdatedAt, &blog.Tags)
+		err = rows.Scan(&blog.ID, &blog.CoverImage, &blog.Title, &blog.Slug, &blog.CommentsCount, &blog.ReactionsMetadata, &blog.ViewsCount, &blog.PublishedAt, &blog.CreatedAt, &blog.UpdatedAt, &blog.Tags)
 		if err != nil {
 			return nil, err
 		}
@@ -207,6 +215,165 @@ func (r *repositoryBlog) GetUserBlogs(userId string, query *string, cursor int,
 	return &blogs, nil
 }
 
+func (r *repositoryBlog) CountAll(query *string, tagId *uint) (int64, error) {
+	baseQuery := `
+		select count(*)
+		from blogs
+		where blogs.published_at is not null
+	`
+
+	var args []any
+	if query != nil && *query != "" {
+		baseQuery += " AND blogs.fts @@ websearch_to_tsquery('english', ?)"
+		args = append(args, *query)
+	}
+
+	if tagId != nil {
+		baseQuery += " AND blogs.id IN (SELECT blog_tags.blog_id FROM blog_tags WHERE blog_tags.tag_id = ?)"
+		args = append(args, *tagId)
+	}
+
+	var total int64
+	if err := r.db.Raw(baseQuery, args...).Row().Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repositoryBlog) CountUserBlogs(userId string, query *string) (int64, error) {
+	baseQuery := `
+		select count(*)
+		from blogs
+		where blogs.user_id = ?
+	`
+
+	args := []any{userId}
+	if query != nil && *query != "" {
+		baseQuery += " AND blogs.fts @@ websearch_to_tsquery('english', ?)"
+		args = append(args, *query)
+	}
+
+	var total int64
+	if err := r.db.Raw(baseQuery, args...).Row().Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repositoryBlog) GetOwnerIdBySlug(slug string) (string, error) {
+	var userId string
+	if err := r.db.Raw("select user_id from blogs where slug = ?", slug).Row().Scan(&userId); err != nil {
+		return "", err
+	}
+
+	return userId, nil
+}
+
+func (r *repositoryBlog) GetOwnerIdById(id uint) (string, error) {
+	var userId string
+	if err := r.db.Raw("select user_id from blogs where id = ?", id).Row().Scan(&userId); err != nil {
+		return "", err
+	}
+
+	return userId, nil
+}
+
+func (r *repositoryBlog) GetPublishedIdBySlug(slug string) (uint, error) {
+	var id uint
+	if err := r.db.Raw("select id from blogs where slug = ? and published_at is not null and deleted_at is null", slug).Row().Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetUserBlogIdBySlug finds a blog of userId, published or not.
+func (r *repositoryBlog) GetUserBlogIdBySlug(userId string, slug string) (uint, error) {
+	var id uint
+	if err := r.db.Raw("select id from blogs where slug = ? and user_id = ? and deleted_at is null", slug, userId).Row().Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetRelated ranks the other published blogs by the tags they share with
+// blogId, weighing 3 each, the users who bookmarked both, weighing 2 each,
+// and how well their text matches the title of blogId.
+func (r *repositoryBlog) GetRelated(blogId uint, limit int) ([]schemas.SelectRelatedBlog, error) {
+	var related []schemas.SelectRelatedBlog
+
+	query := `
+		WITH source AS (
+			SELECT
+				blogs.id,
+				replace(plainto_tsquery('english', blogs.title)::text, '&', '|')::tsquery AS terms
+			FROM blogs
+			WHERE blogs.id = @blog_id
+		), candidates AS (
+			SELECT
+				blogs.id,
+				array(
+					SELECT tags.name
+					FROM blog_tags
+					INNER JOIN tags ON tags.id = blog_tags.tag_id
+					WHERE blog_tags.blog_id = blogs.id
+						AND blog_tags.tag_id IN (SELECT tag_id FROM blog_tags WHERE blog_id = source.id)
+					ORDER BY tags.name
+				) AS shared_tags,
+				(
+					SELECT count(*)
+					FROM blog_bookmarks
+					INNER JOIN blog_bookmarks source_bookmarks
+						ON source_bookmarks.user_id = blog_bookmarks.user_id AND source_bookmarks.blog_id = source.id
+					WHERE blog_bookmarks.blog_id = blogs.id
+				) AS co_bookmarks,
+				CASE WHEN numnode(source.terms) > 0 THEN ts_rank(blogs.fts, source.terms) ELSE 0 END AS text_rank
+			FROM blogs
+			CROSS JOIN source
+			WHERE blogs.id <> source.id
+				AND blogs.published_at IS NOT NULL
+				AND blogs.deleted_at IS NULL
+		), scored AS (
+			SELECT
+				candidates.*,
+				3 * cardinality(candidates.shared_tags) + 2 * candidates.co_bookmarks + 10 * candidates.text_rank AS score
+			FROM candidates
+		)
+		SELECT
+			blogs.id,
+			blogs.cover_image,
+			blogs.title,
+			blogs.slug,
+			user_profiles.user_id AS publisher_id,
+			user_profiles.avatar_url AS publisher_avatar,
+			user_profiles.full_name AS publisher_name,
+			blogs.published_at,
+			array(
+				SELECT tags.name
+				FROM blog_tags
+				INNER JOIN tags ON tags.id = blog_tags.tag_id
+				WHERE blog_tags.blog_id = blogs.id
+				ORDER BY tags.name
+			) AS tags,
+			scored.shared_tags
+		FROM scored
+		INNER JOIN blogs ON blogs.id = scored.id
+		INNER JOIN user_profiles ON user_profiles.user_id = blogs.user_id
+		WHERE scored.score > 0
+		ORDER BY scored.score DESC, blogs.published_at DESC
+		LIMIT @limit
+	`
+
+	if err := r.db.Raw(query, map[string]any{"blog_id": blogId, "limit": limit}).Scan(&related).Error; err != nil {
+		return nil, err
+	}
+
+	return related, nil
+}
+
 func (r *repositoryBlog) Get(userId string, id string) (interface{}, error) {
 	var rows *sql.Rows
 	var err error
@@ -272,6 +439,7 @@ func (r *repositoryBlog) GetBlogBySlug(userId *string, slug string) (*schemas.Se
 				user_profiles.full_name as publisher_name,
 				blogs.attributes ->> 'comments_count' as comments_count,
 				blogs.attributes -> 'reaction_metadata' as reactions_metadata,
+				blogs.attributes ->> 'views_count' as views_count,
 				blogs.published_at,
 	`
 
@@ -336,7 +504,7 @@ func (r *repositoryBlog) GetBlogBySlug(userId *string, slug string) (*schemas.Se
 
 	var blog schemas.SelectBlog
 	for rows.Next() {
-		err = rows.Scan(&blog.ID, &blog.CoverImage, &blog.Title, &blog.Body, &blog.Slug, &blog.PublisherId, &blog.PublisherAvatar, &blog.PublisherName, &blog.CommentsCount, &blog.ReactionsMetadata, &blog.PublishedAt, &blog.IsBookmarked, &blog.Reactions, &blog.CreatedAt, &blog.UpdatedAt, &blog.Tags)
+		err = rows.Scan(&blog.ID, &blog.CoverImage, &blog.Title, &blog.Body, &blog.Slug, &blog.PublisherId, &blog.PublisherAvatar, &blog.PublisherName, &blog.CommentsCount, &blog.ReactionsMetadata, &blog.ViewsCount, &blog.PublishedAt, &blog.IsBookmarked, &blog.Reactions, &blog.CreatedAt, &blog.UpdatedAt, &blog.Tags)
 		if err != nil {
 			return nil, err
 		}
@@ -382,6 +550,7 @@ func (r *repositoryBlog) Create(userId string, tags *models.Tags, data *schemas.
 	if publish {
 		now := time.Now()
 		blog.PublishedAt = &now
+		blog.RankAt = &now
 	}
 
 	if err := r.db.Create(&blog).Error; err != nil {
@@ -412,6 +581,7 @@ func (r *repositoryBlog) Update(userId string, id string, tags *models.Tags, dat
 	if publish && blog.PublishedAt == nil {
 		now := time.Now()
 		blogData["published_at"] = now
+		blogData["rank_at"] = now
 	}
 
 	var updatedRows models.Blogs
@@ -453,25 +623,25 @@ func (r *repositoryBlog) Update(userId string, id string, tags *models.Tags, dat
 
 }
 
-func (r *repositoryBlog) Unpublish(userId string, id string) error {
+func (r *repositoryBlog) Unpublish(userId string, id string) (*models.Blog, error) {
 	var blog models.Blog
 	if err := r.db.Where("id = ? and user_id = ?", id, userId).First(&blog).Error; err != nil {
-		return err
+		return nil, err
 	}
 
 	if blog.ID == 0 {
-		return errors.New("record not found")
+		return nil, errors.New("record not found")
 	}
 
 	if blog.PublishedAt == nil {
-		return errors.New("blog is already unpublished")
+		return nil, errors.New("blog is already unpublished")
 	}
 
-	if err := r.db.Model(&models.Blog{}).Where("id = ? and user_id = ?", id, userId).Update("published_at", nil).Error; err != nil {
-		return err
+	if err := r.db.Model(&blog).Updates(map[string]any{"published_at": nil, "rank_at": nil}).Error; err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &blog, nil
 }
 
 func (r *repositoryBlog) Delete(userId string, id string) error {
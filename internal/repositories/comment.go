@@ -12,6 +12,7 @@ import (
 
 type RepositoryComment interface {
 	Get(userId *string, module string, slug string, cursor int, limit int, parentId *int) (*[]schemas.SelectComment, error)
+	Count(module string, slug string, parentId *int) (int64, error)
 	GetById(id uint) (*models.Comment, error)
 	Create(userId string, data *schemas.SchemaComment) (*models.Comment, error)
 	Reaction(commentId uint, userId uuid.UUID, data *schemas.SchemaReaction) (any, error)
@@ -130,6 +131,39 @@ func (r *repositoryComment) Get(userId *string, module string, slug string, curs
 
 }
 
+func (r *repositoryComment) Count(module string, slug string, parentId *int) (int64, error) {
+	baseQuery := `
+		select count(*)
+		from
+			comments
+	`
+
+	if module == "blog" {
+		baseQuery += `
+			inner join blog_comments on blog_comments.comment_id = comments.id
+			inner join blogs on blogs.id = blog_comments.blog_id and blogs.slug = ?
+		`
+	} else {
+		return 0, errors.New("invalid module")
+	}
+
+	args := []any{slug}
+
+	if parentId != nil {
+		baseQuery += "where comments.parent_id = ?"
+		args = append(args, parentId)
+	} else {
+		baseQuery += "where comments.parent_id is null"
+	}
+
+	var total int64
+	if err := r.db.Raw(baseQuery, args...).Row().Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (r *repositoryComment) GetById(id uint) (*models.Comment, error) {
 	var comment models.Comment
 	if err := r.db.First(&comment, id).Error; err != nil {
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+// trendingWindow is how far back the trending feed looks.
+const trendingWindow = 30 * 24 * time.Hour
+
+type RepositoryFeed interface {
+	GetFollowingFeed(userId string, cursor *schemas.FeedCursor, limit int) ([]schemas.SelectFeedItem, error)
+	GetTrendingFeed(userId string, cursor *schemas.FeedCursor, limit int) ([]schemas.SelectFeedItem, error)
+}
+
+type repositoryFeed struct {
+	db *gorm.DB
+}
+
+// GetFollowingFeed returns the published blogs and the projects of the users
+// userId follows, and the blogs of others carrying the tags userId follows.
+func (r *repositoryFeed) GetFollowingFeed(userId string, cursor *schemas.FeedCursor, limit int) ([]schemas.SelectFeedItem, error) {
+	blogFilter := `(
+		blogs.user_id IN (SELECT following_id FROM user_follows WHERE follower_id = @user_id)
+		OR (
+			blogs.user_id <> @user_id
+			AND blogs.id IN (
+				SELECT blog_tags.blog_id
+				FROM blog_tags
+				INNER JOIN tag_follows ON tag_follows.tag_id = blog_tags.tag_id
+				WHERE tag_follows.user_id = @user_id
+			)
+		)
+	)`
+	projectFilter := "tech_projects.user_id IN (SELECT following_id FROM user_follows WHERE follower_id = @user_id)"
+	return r.getFeed(blogFilter, projectFilter, map[string]any{"user_id": userId}, cursor, limit)
+}
+
+// GetTrendingFeed returns the recent published blogs and projects of everyone
+// but userId.
+func (r *repositoryFeed) GetTrendingFeed(userId string, cursor *schemas.FeedCursor, limit int) ([]schemas.SelectFeedItem, error) {
+	blogFilter := "blogs.user_id <> @user_id AND blogs.published_at > @since"
+	projectFilter := "tech_projects.user_id <> @user_id AND tech_projects.created_at > @since"
+	args := map[string]any{"user_id": userId, "since": time.Now().Add(-trendingWindow)}
+	return r.getFeed(blogFilter, projectFilter, args, cursor, limit)
+}
+
+// getFeed returns the blogs matching blogFilter and the projects matching
+// projectFilter of active portfolios, by rank: the feed position the
+// maintenance.scores job keeps for blogs, and the creation time of projects.
+// Each side is filtered and limited on its own so it is read off
+// blogs_rank_at_idx and tech_projects_created_at_idx.
+//
+// Blog ranks move whenever the job runs, so a blog whose rank crossed the
+// cursor between two page fetches is skipped or shown twice. That is the
+// price of not ranking on every request.
+func (r *repositoryFeed) getFeed(blogFilter string, projectFilter string, args map[string]any, cursor *schemas.FeedCursor, limit int) ([]schemas.SelectFeedItem, error) {
+	var items []schemas.SelectFeedItem
+
+	blogCursor, projectCursor := "", ""
+	if cursor != nil {
+		// The first condition bounds the index scan, the second breaks ties.
+		blogCursor = " AND blogs.rank_at <= @rank_at AND (blogs.rank_at, 'blog', blogs.id) < (@rank_at, @kind, @id)"
+		projectCursor = " AND tech_projects.created_at <= @rank_at AND (tech_projects.created_at, 'project', tech_projects.id) < (@rank_at, @kind, @id)"
+		args["rank_at"] = cursor.RankAt
+		args["kind"] = cursor.Kind
+		args["id"] = cursor.ID
+	}
+
+	query := `
+		WITH items AS (
+			(
+				SELECT
+					'blog' AS kind,
+					blogs.id,
+					blogs.user_id,
+					blogs.title,
+					blogs.slug,
+					blogs.cover_image,
+					NULL::text AS description,
+					array(
+						SELECT tags.name
+						FROM blog_tags
+						INNER JOIN tags ON tags.id = blog_tags.tag_id
+						WHERE blog_tags.blog_id = blogs.id
+						ORDER BY tags.name
+					) AS tags,
+					blogs.attributes -> 'reaction_metadata' AS reactions_metadata,
+					coalesce((blogs.attributes ->> 'comments_count')::bigint, 0) AS comments_count,
+					coalesce((blogs.attributes ->> 'views_count')::bigint, 0) AS views_count,
+					blogs.published_at,
+					blogs.rank_at
+				FROM blogs
+				INNER JOIN user_profiles ON user_profiles.user_id = blogs.user_id
+				WHERE blogs.published_at IS NOT NULL
+					AND blogs.deleted_at IS NULL
+					AND user_profiles.portfolio_status = 'ACTIVE'
+					AND user_profiles.deleted_at IS NULL
+					AND ` + blogFilter + blogCursor + `
+				ORDER BY blogs.rank_at DESC, blogs.id DESC
+				LIMIT @limit
+			)
+			UNION ALL
+			(
+				SELECT
+					'project',
+					tech_projects.id,
+					tech_projects.user_id,
+					tech_projects.title,
+					NULL,
+					NULL,
+					tech_projects.description,
+					tech_projects.tech_used,
+					NULL,
+					0,
+					0,
+					tech_projects.created_at,
+					tech_projects.created_at
+				FROM tech_projects
+				INNER JOIN user_profiles ON user_profiles.user_id = tech_projects.user_id
+				WHERE tech_projects.deleted_at IS NULL
+					AND user_profiles.portfolio_status = 'ACTIVE'
+					AND user_profiles.deleted_at IS NULL
+					AND ` + projectFilter + projectCursor + `
+				ORDER BY tech_projects.created_at DESC, tech_projects.id DESC
+				LIMIT @limit
+			)
+		)
+		SELECT
+			items.kind,
+			items.id,
+			items.title,
+			items.slug,
+			items.cover_image,
+			items.description,
+			items.tags,
+			items.reactions_metadata,
+			items.comments_count,
+			items.views_count,
+			items.user_id AS publisher_id,
+			user_profiles.full_name AS publisher_name,
+			user_profiles.avatar_url AS publisher_avatar,
+			user_profiles.slug AS publisher_slug,
+			items.published_at,
+			items.rank_at
+		FROM items
+		INNER JOIN user_profiles ON user_profiles.user_id = items.user_id
+		ORDER BY items.rank_at DESC, items.kind DESC, items.id DESC
+		LIMIT @limit
+	`
+	args["limit"] = limit
+
+	if err := r.db.Raw(query, args).Scan(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func NewFeedRepository(db *gorm.DB) *repositoryFeed {
+	return &repositoryFeed{
+		db: db,
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RepositoryJob interface {
+	Create(job *models.Job) error
+	Claim(limit int, lease time.Duration) ([]models.Job, error)
+	Complete(job *models.Job) (int64, error)
+	Retry(job *models.Job, runAt time.Time, lastError string) (int64, error)
+	Bury(job *models.Job, lastError string) (int64, error)
+	DeleteFinished(before time.Time) (int64, error)
+}
+
+type repositoryJob struct {
+	db *gorm.DB
+}
+
+// Create enqueues the job. A job with the UniqueKey of an existing one is
+// silently dropped.
+func (r *repositoryJob) Create(job *models.Job) error {
+	return r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "unique_key"}}, DoNothing: true}).Create(job).Error
+}
+
+// Claim locks up to limit due jobs for lease and counts the attempt. Jobs
+// whose lock expired while running are claimed again.
+func (r *repositoryJob) Claim(limit int, lease time.Duration) ([]models.Job, error) {
+	var jobs []models.Job
+
+	query := `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_until = now() + make_interval(secs => ?), updated_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM jobs
+			WHERE (status = 'pending' AND run_at <= now()) OR (status = 'running' AND locked_until < now())
+			ORDER BY run_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	if err := r.db.Raw(query, lease.Seconds(), limit).Scan(&jobs).Error; err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Complete, Retry and Bury record the outcome of a claimed job, and return
+// 0 when the job was claimed again after its lease expired, leaving it to
+// the newer claim. Claims are told apart by the attempt they counted.
+func (r *repositoryJob) Complete(job *models.Job) (int64, error) {
+	return r.finish(job, map[string]any{
+		"status":       models.JobSucceeded,
+		"locked_until": nil,
+		"updated_at":   gorm.Expr("now()"),
+		"finished_at":  gorm.Expr("now()"),
+	})
+}
+
+func (r *repositoryJob) Retry(job *models.Job, runAt time.Time, lastError string) (int64, error) {
+	return r.finish(job, map[string]any{
+		"status":       models.JobPending,
+		"run_at":       runAt,
+		"locked_until": nil,
+		"last_error":   lastError,
+		"updated_at":   gorm.Expr("now()"),
+	})
+}
+
+// Bury moves the job to the dead-letter state.
+func (r *repositoryJob) Bury(job *models.Job, lastError string) (int64, error) {
+	return r.finish(job, map[string]any{
+		"status":       models.JobDead,
+		"locked_until": nil,
+		"last_error":   lastError,
+		"updated_at":   gorm.Expr("now()"),
+		"finished_at":  gorm.Expr("now()"),
+	})
+}
+
+func (r *repositoryJob) finish(job *models.Job, updates map[string]any) (int64, error) {
+	res := r.db.Model(&models.Job{}).
+		Where("id = ? and status = ? and attempts = ?", job.ID, models.JobRunning, job.Attempts).
+		Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+func (r *repositoryJob) DeleteFinished(before time.Time) (int64, error) {
+	res := r.db.Where("finished_at < ?", before).Delete(&models.Job{})
+	return res.RowsAffected, res.Error
+}
+
+func NewJobRepository(db *gorm.DB) *repositoryJob {
+	return &repositoryJob{
+		db: db,
+	}
+}
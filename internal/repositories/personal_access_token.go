@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"gorm.io/gorm"
+)
+
+type RepositoryPersonalAccessToken interface {
+	GetAll(userId uuid.UUID) ([]models.PersonalAccessToken, error)
+	Create(token *models.PersonalAccessToken) error
+	Delete(userId uuid.UUID, id uint) (int64, error)
+	GetActiveByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	TouchLastUsed(id uint) error
+}
+
+type repositoryPersonalAccessToken struct {
+	db *gorm.DB
+}
+
+func (r *repositoryPersonalAccessToken) GetAll(userId uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+
+	if err := r.db.Where("user_id = ?", userId).Order("created_at desc").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *repositoryPersonalAccessToken) Create(token *models.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *repositoryPersonalAccessToken) Delete(userId uuid.UUID, id uint) (int64, error) {
+	res := r.db.Where("id = ? and user_id = ?", id, userId).Delete(&models.PersonalAccessToken{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *repositoryPersonalAccessToken) GetActiveByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+
+	if err := r.db.Where("token_hash = ? and (expires_at is null or expires_at > now())", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// TouchLastUsed records a use of the token, at most once a minute so busy
+// pipelines don't write on every request.
+func (r *repositoryPersonalAccessToken) TouchLastUsed(id uint) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? and (last_used_at is null or last_used_at < now() - interval '1 minute')", id).
+		Update("last_used_at", gorm.Expr("now()")).Error
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) *repositoryPersonalAccessToken {
+	return &repositoryPersonalAccessToken{
+		db: db,
+	}
+}
@@ -4,64 +4,174 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 type RepositoryPortfolio interface {
-	GetAll(userId string, query *string, cursor int, limit int) (*[]schemas.SelectPortfoliosItem, error)
+	GetAll(userId string, filters *schemas.SchemaPortfolioFilters, sort string, cursor int, limit int) (*[]schemas.SelectPortfoliosItem, error)
+	CountAll(filters *schemas.SchemaPortfolioFilters) (int64, error)
+	GetFacets(filters *schemas.SchemaPortfolioFilters, limit int) ([]schemas.SelectPortfolioFacet, error)
+	GetIdBySlug(slug string) (string, error)
+	GetSimilar(userId string, limit int) ([]schemas.SelectSimilarPortfolio, error)
 	GetPortfolio(slug string) (any, error)
-	GetEducations(slug string) (any, error)
-	GetWorkExperiences(slug string) (any, error)
-	GetCertifications(slug string) (any, error)
-	GetHackathons(slug string) (any, error)
-	GetTechProjects(slug string) (any, error)
+	GetPortfolioFields(slug string, fields []string) (map[string]any, error)
+	GetPortfolioWith(slug string, query *schemas.SchemaPortfolioQuery) (map[string]any, error)
+	GetModule(slug string, module string, limit int) (any, error)
+	GetEducations(slug string, limit int) (any, error)
+	GetWorkExperiences(slug string, limit int) (any, error)
+	GetCertifications(slug string, limit int) (any, error)
+	GetHackathons(slug string, limit int) (any, error)
+	GetTechProjects(slug string, limit int) (any, error)
+	GetBlogs(slug string, limit int) (*[]schemas.SelectBlog, error)
 	GetUserPortfolio(userId string) (any, error)
-	GetSkills(slug string) (*models.Skills, error)
+	GetSkills(slug string, limit int) (*models.Skills, error)
 }
 
 type repositoryPortfolio struct {
 	db *gorm.DB
 }
 
-func (r *repositoryPortfolio) GetAll(userId *string, query *string, cursor int, limit int) (*[]schemas.SelectPortfoliosItem, error) {
-	var rows *sql.Rows
-	var err error
-
-	baseQuery := `
-		SELECT
-			user_id AS id,
-			full_name AS name,
-			email,
-			avatar_url AS avatar,
-			slug,
-			attributes ->> 'college' AS college,
-			attributes -> 'skills' AS skills,
-			attributes ->> 'tagline' AS tagline,
-			attributes -> 'work_domains' AS work_domains,
-			attributes -> 'social_profiles' AS social_profiles
-		FROM
-			user_profiles
-		WHERE
-			user_profiles.portfolio_status = 'ACTIVE'
+// portfolioCandidates selects the active portfolios matching the full text
+// query of filters along with the values they can be filtered on.
+func portfolioCandidates(filters *schemas.SchemaPortfolioFilters, args map[string]any) string {
+	query := `
+		WITH candidates AS (
+			SELECT
+				user_profiles.user_id,
+				array(SELECT jsonb_array_elements_text(coalesce(user_profiles.attributes -> 'skills', '[]'))) AS skills,
+				array(SELECT jsonb_array_elements_text(coalesce(user_profiles.attributes -> 'work_domains', '[]'))) AS work_domains,
+				nullif(trim(user_profiles.attributes ->> 'college'), '') AS college,
+				CASE
+					WHEN user_profiles.attributes ->> 'graduation_year' ~ '^[0-9]{4}$' THEN (user_profiles.attributes ->> 'graduation_year')::int
+				END AS graduation_year,
+				EXISTS (
+					SELECT 1 FROM hackathons
+					WHERE hackathons.user_id = user_profiles.user_id AND hackathons.deleted_at IS NULL
+				) AS has_hackathons,
+				EXISTS (
+					SELECT 1 FROM certifications
+					WHERE certifications.user_id = user_profiles.user_id AND certifications.deleted_at IS NULL
+				) AS has_certifications,
+				array(
+					SELECT DISTINCT work_experiences.job_type::text FROM work_experiences
+					WHERE work_experiences.user_id = user_profiles.user_id AND work_experiences.deleted_at IS NULL
+				) AS job_types
+			FROM user_profiles
+			WHERE user_profiles.portfolio_status = 'ACTIVE'
 	`
 
-	var args []interface{}
-	args = append(args, limit, cursor)
+	if filters.Query != nil && *filters.Query != "" {
+		query += " AND user_profiles.fts @@ websearch_to_tsquery('english', @query)"
+		args["query"] = *filters.Query
+	}
+
+	return query + ")"
+}
+
+// portfolioConditions returns the conditions on candidates for each facet
+// filtered on.
+func portfolioConditions(filters *schemas.SchemaPortfolioFilters, args map[string]any) map[string]string {
+	conditions := map[string]string{}
+
+	if len(filters.Skills) > 0 {
+		operator := "&&"
+		if filters.SkillsMatch == "all" {
+			operator = "@>"
+		}
+		conditions[schemas.PortfolioFacetSkills] = "array(SELECT lower(skill) FROM unnest(candidates.skills) AS skill) " + operator + " @skills"
+		args["skills"] = lowerArray(filters.Skills)
+	}
+	if len(filters.WorkDomains) > 0 {
+		conditions[schemas.PortfolioFacetWorkDomains] = "array(SELECT lower(work_domain) FROM unnest(candidates.work_domains) AS work_domain) && @work_domains"
+		args["work_domains"] = lowerArray(filters.WorkDomains)
+	}
+	if len(filters.Colleges) > 0 {
+		conditions[schemas.PortfolioFacetColleges] = "lower(candidates.college) = ANY(@colleges)"
+		args["colleges"] = lowerArray(filters.Colleges)
+	}
+
+	var years []string
+	if filters.GraduationYearMin != nil {
+		years = append(years, "candidates.graduation_year >= @graduation_year_min")
+		args["graduation_year_min"] = *filters.GraduationYearMin
+	}
+	if filters.GraduationYearMax != nil {
+		years = append(years, "candidates.graduation_year <= @graduation_year_max")
+		args["graduation_year_max"] = *filters.GraduationYearMax
+	}
+	if len(years) > 0 {
+		conditions[schemas.PortfolioFacetGraduationYears] = strings.Join(years, " AND ")
+	}
+
+	if filters.HasHackathons != nil {
+		conditions[schemas.PortfolioFacetHasHackathons] = "candidates.has_hackathons = @has_hackathons"
+		args["has_hackathons"] = *filters.HasHackathons
+	}
+	if filters.HasCertifications != nil {
+		conditions[schemas.PortfolioFacetHasCertifications] = "candidates.has_certifications = @has_certifications"
+		args["has_certifications"] = *filters.HasCertifications
+	}
+	if len(filters.JobTypes) > 0 {
+		conditions[schemas.PortfolioFacetJobTypes] = "candidates.job_types && @job_types"
+		args["job_types"] = pq.StringArray(filters.JobTypes)
+	}
+
+	return conditions
+}
+
+// portfolioWhere joins the conditions of every facet but except.
+func portfolioWhere(conditions map[string]string, except string) string {
+	where := []string{"TRUE"}
+	for _, facet := range schemas.PortfolioFacets {
+		if condition, ok := conditions[facet]; ok && facet != except {
+			where = append(where, "("+condition+")")
+		}
+	}
+
+	return strings.Join(where, " AND ")
+}
 
-	if query != nil && *query != "" {
-		baseQuery += " AND fts @@ to_tsquery(?)"
-		args = append([]interface{}{*query}, args...)
+func lowerArray(values []string) pq.StringArray {
+	lowered := make(pq.StringArray, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(strings.TrimSpace(value))
 	}
 
-	baseQuery += `
-		ORDER BY user_profiles.updated_at DESC
-		LIMIT ? OFFSET ?
+	return lowered
+}
+
+func (r *repositoryPortfolio) GetAll(userId *string, filters *schemas.SchemaPortfolioFilters, sort string, cursor int, limit int) (*[]schemas.SelectPortfoliosItem, error) {
+	var rows *sql.Rows
+	var err error
+
+	args := map[string]any{"limit": limit, "cursor": cursor}
+	conditions := portfolioConditions(filters, args)
+
+	baseQuery := portfolioCandidates(filters, args) + `
+		SELECT
+			user_profiles.user_id AS id,
+			user_profiles.full_name AS name,
+			user_profiles.email,
+			user_profiles.avatar_url AS avatar,
+			user_profiles.slug,
+			user_profiles.attributes ->> 'college' AS college,
+			user_profiles.attributes -> 'skills' AS skills,
+			user_profiles.attributes ->> 'tagline' AS tagline,
+			user_profiles.attributes -> 'work_domains' AS work_domains,
+			user_profiles.attributes -> 'social_profiles' AS social_profiles
+		FROM candidates
+		INNER JOIN user_profiles ON user_profiles.user_id = candidates.user_id
+		WHERE ` + portfolioWhere(conditions, "") + `
+		ORDER BY ` + sortOrder(sort, "user_profiles", "user_id") + `
+		LIMIT @limit OFFSET @cursor
 	`
 
-	rows, err = r.db.Raw(baseQuery, args...).Rows()
+	rows, err = r.db.Raw(baseQuery, args).Rows()
 
 	if err != nil {
 		return nil, err
@@ -96,6 +206,163 @@ func (r *repositoryPortfolio) GetAll(userId *string, query *string, cursor int,
 	return &results, nil
 }
 
+func (r *repositoryPortfolio) CountAll(filters *schemas.SchemaPortfolioFilters) (int64, error) {
+	args := map[string]any{}
+	conditions := portfolioConditions(filters, args)
+
+	baseQuery := portfolioCandidates(filters, args) + `
+		SELECT count(*)
+		FROM candidates
+		WHERE ` + portfolioWhere(conditions, "")
+
+	var total int64
+	if err := r.db.Raw(baseQuery, args).Row().Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetFacets counts the portfolios having each value of every facet, up to
+// limit values per facet, most common first. The values of a facet are
+// counted among the portfolios matching the filters on the other facets, so
+// that choosing one doesn't hide the alternatives. Text values differing only
+// in case are counted together.
+func (r *repositoryPortfolio) GetFacets(filters *schemas.SchemaPortfolioFilters, limit int) ([]schemas.SelectPortfolioFacet, error) {
+	var facets []schemas.SelectPortfolioFacet
+
+	args := map[string]any{"limit": limit}
+	conditions := portfolioConditions(filters, args)
+	where := func(facet string) string {
+		return portfolioWhere(conditions, facet)
+	}
+
+	query := portfolioCandidates(filters, args) + `
+		SELECT facet, value, count
+		FROM (
+			SELECT counts.*, row_number() OVER (PARTITION BY counts.facet ORDER BY counts.count DESC, counts.value) AS position
+			FROM (
+				SELECT 'skills' AS facet, min(skill) AS value, count(DISTINCT candidates.user_id) AS count
+				FROM candidates, unnest(candidates.skills) AS skill
+				WHERE ` + where(schemas.PortfolioFacetSkills) + `
+				GROUP BY lower(skill)
+				UNION ALL
+				SELECT 'work_domains', min(work_domain), count(DISTINCT candidates.user_id)
+				FROM candidates, unnest(candidates.work_domains) AS work_domain
+				WHERE ` + where(schemas.PortfolioFacetWorkDomains) + `
+				GROUP BY lower(work_domain)
+				UNION ALL
+				SELECT 'colleges', min(candidates.college), count(*)
+				FROM candidates
+				WHERE candidates.college IS NOT NULL AND ` + where(schemas.PortfolioFacetColleges) + `
+				GROUP BY lower(candidates.college)
+				UNION ALL
+				SELECT 'graduation_years', candidates.graduation_year::text, count(*)
+				FROM candidates
+				WHERE candidates.graduation_year IS NOT NULL AND ` + where(schemas.PortfolioFacetGraduationYears) + `
+				GROUP BY candidates.graduation_year
+				UNION ALL
+				SELECT 'has_hackathons', candidates.has_hackathons::text, count(*)
+				FROM candidates
+				WHERE ` + where(schemas.PortfolioFacetHasHackathons) + `
+				GROUP BY candidates.has_hackathons
+				UNION ALL
+				SELECT 'has_certifications', candidates.has_certifications::text, count(*)
+				FROM candidates
+				WHERE ` + where(schemas.PortfolioFacetHasCertifications) + `
+				GROUP BY candidates.has_certifications
+				UNION ALL
+				SELECT 'job_types', job_type, count(*)
+				FROM candidates, unnest(candidates.job_types) AS job_type
+				WHERE ` + where(schemas.PortfolioFacetJobTypes) + `
+				GROUP BY job_type
+			) AS counts
+		) AS ranked
+		WHERE ranked.position <= @limit
+		ORDER BY ranked.facet, ranked.position
+	`
+
+	if err := r.db.Raw(query, args).Scan(&facets).Error; err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func (r *repositoryPortfolio) GetIdBySlug(slug string) (string, error) {
+	var userId string
+	if err := r.db.Raw("select user_id from user_profiles where slug = ? and deleted_at is null", slug).Row().Scan(&userId); err != nil {
+		return "", err
+	}
+
+	return userId, nil
+}
+
+// GetSimilar ranks the other active portfolios by the work domains, weighing
+// 3 each, skills, weighing 2, and project technologies they share with the
+// portfolio of userId, ignoring case.
+func (r *repositoryPortfolio) GetSimilar(userId string, limit int) ([]schemas.SelectSimilarPortfolio, error) {
+	var similar []schemas.SelectSimilarPortfolio
+
+	query := `
+		WITH profiles AS (
+			SELECT
+				user_profiles.user_id,
+				array(SELECT jsonb_array_elements_text(coalesce(user_profiles.attributes -> 'skills', '[]'))) AS skills,
+				array(SELECT jsonb_array_elements_text(coalesce(user_profiles.attributes -> 'work_domains', '[]'))) AS work_domains,
+				array(
+					SELECT DISTINCT tech
+					FROM tech_projects, unnest(tech_projects.tech_used) AS tech
+					WHERE tech_projects.user_id = user_profiles.user_id AND tech_projects.deleted_at IS NULL
+				) AS tech
+			FROM user_profiles
+			WHERE user_profiles.user_id = @user_id
+				OR (user_profiles.portfolio_status = 'ACTIVE' AND user_profiles.deleted_at IS NULL)
+		), source AS (
+			SELECT
+				array(SELECT lower(skill) FROM unnest(profiles.skills) AS skill) AS skills,
+				array(SELECT lower(work_domain) FROM unnest(profiles.work_domains) AS work_domain) AS work_domains,
+				array(SELECT lower(tech) FROM unnest(profiles.tech) AS tech) AS tech
+			FROM profiles
+			WHERE profiles.user_id = @user_id
+		), shared AS (
+			SELECT
+				profiles.user_id,
+				array(SELECT DISTINCT skill FROM unnest(profiles.skills) AS skill WHERE lower(skill) = ANY(source.skills)) AS shared_skills,
+				array(SELECT DISTINCT work_domain FROM unnest(profiles.work_domains) AS work_domain WHERE lower(work_domain) = ANY(source.work_domains)) AS shared_work_domains,
+				array(SELECT DISTINCT tech FROM unnest(profiles.tech) AS tech WHERE lower(tech) = ANY(source.tech)) AS shared_tech
+			FROM profiles
+			CROSS JOIN source
+			WHERE profiles.user_id <> @user_id
+		), scored AS (
+			SELECT
+				shared.*,
+				3 * cardinality(shared.shared_work_domains) + 2 * cardinality(shared.shared_skills) + cardinality(shared.shared_tech) AS score
+			FROM shared
+		)
+		SELECT
+			user_profiles.user_id AS id,
+			user_profiles.full_name AS name,
+			user_profiles.avatar_url AS avatar,
+			user_profiles.slug,
+			user_profiles.attributes ->> 'tagline' AS tagline,
+			scored.shared_skills,
+			scored.shared_work_domains,
+			scored.shared_tech
+		FROM scored
+		INNER JOIN user_profiles ON user_profiles.user_id = scored.user_id
+		WHERE scored.score > 0
+		ORDER BY scored.score DESC, user_profiles.score_all DESC, user_profiles.user_id
+		LIMIT @limit
+	`
+
+	if err := r.db.Raw(query, map[string]any{"user_id": userId, "limit": limit}).Scan(&similar).Error; err != nil {
+		return nil, err
+	}
+
+	return similar, nil
+}
+
 func (r *repositoryPortfolio) GetUserPortfolio(userId string) (interface{}, error) {
 	var rows *sql.Rows
 	var err error
@@ -258,47 +525,191 @@ func (r *repositoryPortfolio) GetPortfolio(slug string) (interface{}, error) {
 	return &results, nil
 }
 
-func (r *repositoryPortfolio) GetEducations(slug string) (interface{}, error) {
+// portfolioColumns maps the sparse fieldset names of GET /portfolio/:slug to
+// the expressions producing them.
+var portfolioColumns = map[string]string{
+	"id":     "user_profiles.user_id",
+	"status": "user_profiles.portfolio_status",
+	"basic_details": `json_build_object(
+		'email', user_profiles.email,
+		'name', user_profiles.full_name,
+		'avatar', user_profiles.avatar_url,
+		'slug', user_profiles.slug,
+		'about', user_profiles.attributes -> 'about',
+		'tagline', user_profiles.attributes -> 'tagline',
+		'college', user_profiles.attributes -> 'college',
+		'graduation_year', user_profiles.attributes -> 'graduation_year',
+		'work_domains', user_profiles.attributes -> 'work_domains',
+		'social_profiles', user_profiles.attributes -> 'social_profiles',
+		'resume', user_profiles.attributes -> 'resume',
+		'hero_image', user_profiles.attributes -> 'hero_image',
+		'about_image', user_profiles.attributes -> 'about_image'
+	)`,
+	"skills": "user_profiles.attributes -> 'skills'",
+	"additional_details": `json_build_object(
+		'education_metadata', user_profiles.attributes -> 'education_metadata',
+		'hackathon_metadata', user_profiles.attributes -> 'hackathon_metadata',
+		'work_gallery_metadata', user_profiles.attributes -> 'work_gallery_metadata',
+		'certification_metadata', user_profiles.attributes -> 'certification_metadata',
+		'work_experience_metadata', user_profiles.attributes -> 'work_experience_metadata',
+		'blog_metadata', user_profiles.attributes -> 'blog_metadata'
+	)`,
+}
+
+// portfolioModuleLimits are the limits used when an include does not name
+// one. Modules missing from here are returned in full.
+var portfolioModuleLimits = map[string]int{
+	"works": 6,
+	"blogs": 10,
+}
+
+// GetPortfolioFields selects only the requested fields of a portfolio. The id
+// is always returned.
+func (r *repositoryPortfolio) GetPortfolioFields(slug string, fields []string) (map[string]any, error) {
+	names := []string{"id"}
+	for _, field := range fields {
+		if field != "id" {
+			names = append(names, field)
+		}
+	}
+
+	columns := make([]string, 0, len(names))
+	for _, name := range names {
+		column, ok := portfolioColumns[name]
+		if !ok {
+			return nil, errors.New("invalid field " + name)
+		}
+		columns = append(columns, "to_jsonb("+column+") as "+name)
+	}
+
+	rows, err := r.db.Raw(`
+		select `+strings.Join(columns, ", ")+`
+		from
+			user_profiles
+		where
+			user_profiles.slug = ?
+	`, slug).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("failed to get portfolio")
+	}
+
+	values := make([]json.RawMessage, len(names))
+	dest := make([]any, len(names))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]any, len(names))
+	for i, name := range names {
+		res[name] = values[i]
+	}
+
+	return res, nil
+}
+
+// GetPortfolioWith resolves a sparse, expanded portfolio. Only the modules
+// named in query.Include are queried.
+func (r *repositoryPortfolio) GetPortfolioWith(slug string, query *schemas.SchemaPortfolioQuery) (map[string]any, error) {
+	fields := query.Fields
+	if len(fields) == 0 {
+		fields = schemas.PortfolioFields
+	}
+
+	res, err := r.GetPortfolioFields(slug, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, include := range query.Include {
+		module, err := r.GetModule(slug, include.Module, include.Limit)
+		if err != nil {
+			return nil, err
+		}
+		res[include.Module] = module
+	}
+
+	return res, nil
+}
+
+// GetModule returns one portfolio module. A limit of 0 uses the module's
+// default from portfolioModuleLimits.
+func (r *repositoryPortfolio) GetModule(slug string, module string, limit int) (any, error) {
+	if limit == 0 {
+		limit = portfolioModuleLimits[module]
+	}
+
+	switch module {
+	case "educations":
+		return r.GetEducations(slug, limit)
+	case "work_experiences":
+		return r.GetWorkExperiences(slug, limit)
+	case "certifications":
+		return r.GetCertifications(slug, limit)
+	case "hackathons":
+		return r.GetHackathons(slug, limit)
+	case "works":
+		return r.GetTechProjects(slug, limit)
+	case "skills":
+		return r.GetSkills(slug, limit)
+	case "blogs":
+		return r.GetBlogs(slug, limit)
+	}
+
+	return nil, errors.New("invalid module")
+}
+
+func (r *repositoryPortfolio) GetEducations(slug string, limit int) (interface{}, error) {
 	var userEducations models.Educations
 
-	if err := r.db.Joins("inner join user_profiles on user_profiles.user_id = educations.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userEducations).Error; err != nil {
+	if err := withLimit(r.db, limit).Joins("inner join user_profiles on user_profiles.user_id = educations.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userEducations).Error; err != nil {
 		return nil, err
 	}
 
 	return &userEducations, nil
 }
 
-func (r *repositoryPortfolio) GetHackathons(slug string) (*models.Hackathons, error) {
+func (r *repositoryPortfolio) GetHackathons(slug string, limit int) (*models.Hackathons, error) {
 	var userHackathons models.Hackathons
 
-	if err := r.db.Joins("inner join user_profiles on user_profiles.user_id = hackathons.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userHackathons).Error; err != nil {
+	if err := withLimit(r.db, limit).Joins("inner join user_profiles on user_profiles.user_id = hackathons.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userHackathons).Error; err != nil {
 		return nil, err
 	}
 
 	return &userHackathons, nil
 }
 
-func (r *repositoryPortfolio) GetWorkExperiences(slug string) (*models.WorkExperiences, error) {
+func (r *repositoryPortfolio) GetWorkExperiences(slug string, limit int) (*models.WorkExperiences, error) {
 	var userExperiences models.WorkExperiences
 
-	if err := r.db.Joins("inner join user_profiles on user_profiles.user_id = work_experiences.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userExperiences).Error; err != nil {
+	if err := withLimit(r.db, limit).Joins("inner join user_profiles on user_profiles.user_id = work_experiences.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userExperiences).Error; err != nil {
 		return nil, err
 	}
 
 	return &userExperiences, nil
 }
 
-func (r *repositoryPortfolio) GetCertifications(slug string) (*models.Certifications, error) {
+func (r *repositoryPortfolio) GetCertifications(slug string, limit int) (*models.Certifications, error) {
 	var userCertifications models.Certifications
 
-	if err := r.db.Joins("inner join user_profiles on user_profiles.user_id = certifications.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userCertifications).Error; err != nil {
+	if err := withLimit(r.db, limit).Joins("inner join user_profiles on user_profiles.user_id = certifications.user_id").Where("user_profiles.slug = ?", slug).Order("order_index desc").Find(&userCertifications).Error; err != nil {
 		return nil, err
 	}
 
 	return &userCertifications, nil
 }
 
-func (r *repositoryPortfolio) GetTechProjects(slug string) (*[]schemas.SelectUserTechProject, error) {
+func (r *repositoryPortfolio) GetTechProjects(slug string, limit int) (*[]schemas.SelectUserTechProject, error) {
 	var rows *sql.Rows
 	var err error
 
@@ -333,8 +744,8 @@ func (r *repositoryPortfolio) GetTechProjects(slug string) (*[]schemas.SelectUse
 			tech_projects.id
 		order by
 			order_index desc
-		limit 6
-	`, slug).Rows()
+		limit ?
+	`, slug, limit).Rows()
 
 	if err != nil {
 		return nil, err
@@ -375,11 +786,11 @@ func (r *repositoryPortfolio) GetTechProjects(slug string) (*[]schemas.SelectUse
 	return &userTechProjects, nil
 }
 
-func (r *repositoryPortfolio) GetSkills(slug string) (*models.Skills, error) {
+func (r *repositoryPortfolio) GetSkills(slug string, limit int) (*models.Skills, error) {
 	var rows *sql.Rows
 	var err error
 
-	rows, err = r.db.Raw(`
+	baseQuery := `
 		with
 			user_skills as (
 				select
@@ -394,8 +805,16 @@ func (r *repositoryPortfolio) GetSkills(slug string) (*models.Skills, error) {
 			skills.image
 		from
 			skills
-		right	join user_skills us on skills.name = us.skill_name;
-	`, slug).Rows()
+		right	join user_skills us on skills.name = us.skill_name
+	`
+
+	args := []any{slug}
+	if limit > 0 {
+		baseQuery += " limit ?"
+		args = append(args, limit)
+	}
+
+	rows, err = r.db.Raw(baseQuery, args...).Rows()
 
 	if err != nil {
 		return nil, err
@@ -416,6 +835,69 @@ func (r *repositoryPortfolio) GetSkills(slug string) (*models.Skills, error) {
 	return &skills, nil
 }
 
+func (r *repositoryPortfolio) GetBlogs(slug string, limit int) (*[]schemas.SelectBlog, error) {
+	var rows *sql.Rows
+	var err error
+
+	baseQuery := `
+		select
+			blogs.id,
+			blogs.cover_image,
+			blogs.title,
+			blogs.slug,
+			blogs.attributes ->> 'comments_count' as comments_count,
+			blogs.attributes -> 'reaction_metadata' as reactions_metadata,
+			blogs.published_at,
+			blogs.created_at,
+			blogs.updated_at,
+			array_remove(array_agg(tags.name), NULL) AS tags
+		from
+			blogs
+			inner join user_profiles on user_profiles.user_id = blogs.user_id
+			left join blog_tags on blog_tags.blog_id = blogs.id
+			left join tags on tags.id = blog_tags.tag_id
+		where
+			user_profiles.slug = ? and blogs.published_at is not null
+		group by
+			blogs.id
+		order by
+			blogs.published_at desc
+	`
+
+	args := []any{slug}
+	if limit > 0 {
+		baseQuery += " limit ?"
+		args = append(args, limit)
+	}
+
+	rows, err = r.db.Raw(baseQuery, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blogs := []schemas.SelectBlog{}
+	for rows.Next() {
+		var blog schemas.SelectBlog
+
+		err = rows.Scan(&blog.ID, &blog.CoverImage, &blog.Title, &blog.Slug, &blog.CommentsCount, &blog.ReactionsMetadata, &blog.PublishedAt, &blog.CreatedAt, &blog.UpdatedAt, &blog.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		blogs = append(blogs, blog)
+	}
+
+	return &blogs, nil
+}
+
+func withLimit(db *gorm.DB, limit int) *gorm.DB {
+	if limit > 0 {
+		return db.Limit(limit)
+	}
+	return db
+}
+
 func NewPortfolioRepository(db *gorm.DB) *repositoryPortfolio {
 	return &repositoryPortfolio{
 		db: db,
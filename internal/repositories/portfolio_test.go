@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/lib/pq"
+)
+
+func TestPortfolioConditions(t *testing.T) {
+	yearMin, yearMax := 2024, 2026
+	yes, no := true, false
+
+	tests := []struct {
+		name           string
+		filters        schemas.SchemaPortfolioFilters
+		wantConditions map[string]string
+		wantArgs       map[string]any
+	}{
+		{
+			name:           "no filters",
+			filters:        schemas.SchemaPortfolioFilters{SkillsMatch: "any"},
+			wantConditions: map[string]string{},
+			wantArgs:       map[string]any{},
+		},
+		{
+			name:    "any skill",
+			filters: schemas.SchemaPortfolioFilters{Skills: []string{"Go", " React "}, SkillsMatch: "any"},
+			wantConditions: map[string]string{
+				schemas.PortfolioFacetSkills: "array(SELECT lower(skill) FROM unnest(candidates.skills) AS skill) && @skills",
+			},
+			wantArgs: map[string]any{"skills": pq.StringArray{"go", "react"}},
+		},
+		{
+			name:    "all skills",
+			filters: schemas.SchemaPortfolioFilters{Skills: []string{"Go", "React"}, SkillsMatch: "all"},
+			wantConditions: map[string]string{
+				schemas.PortfolioFacetSkills: "array(SELECT lower(skill) FROM unnest(candidates.skills) AS skill) @> @skills",
+			},
+			wantArgs: map[string]any{"skills": pq.StringArray{"go", "react"}},
+		},
+		{
+			name:    "graduation years",
+			filters: schemas.SchemaPortfolioFilters{GraduationYearMin: &yearMin, GraduationYearMax: &yearMax},
+			wantConditions: map[string]string{
+				schemas.PortfolioFacetGraduationYears: "candidates.graduation_year >= @graduation_year_min AND candidates.graduation_year <= @graduation_year_max",
+			},
+			wantArgs: map[string]any{"graduation_year_min": 2024, "graduation_year_max": 2026},
+		},
+		{
+			name:    "graduation year max",
+			filters: schemas.SchemaPortfolioFilters{GraduationYearMax: &yearMax},
+			wantConditions: map[string]string{
+				schemas.PortfolioFacetGraduationYears: "candidates.graduation_year <= @graduation_year_max",
+			},
+			wantArgs: map[string]any{"graduation_year_max": 2026},
+		},
+		{
+			name: "every other facet",
+			filters: schemas.SchemaPortfolioFilters{
+				WorkDomains:       []string{"Backend Developer"},
+				Colleges:          []string{"IIT Bombay"},
+				HasHackathons:     &yes,
+				HasCertifications: &no,
+				JobTypes:          []string{"FULL_TIME"},
+			},
+			wantConditions: map[string]string{
+				schemas.PortfolioFacetWorkDomains:       "array(SELECT lower(work_domain) FROM unnest(candidates.work_domains) AS work_domain) && @work_domains",
+				schemas.PortfolioFacetColleges:          "lower(candidates.college) = ANY(@colleges)",
+				schemas.PortfolioFacetHasHackathons:     "candidates.has_hackathons = @has_hackathons",
+				schemas.PortfolioFacetHasCertifications: "candidates.has_certifications = @has_certifications",
+				schemas.PortfolioFacetJobTypes:          "candidates.job_types && @job_types",
+			},
+			wantArgs: map[string]any{
+				"work_domains":       pq.StringArray{"backend developer"},
+				"colleges":           pq.StringArray{"iit bombay"},
+				"has_hackathons":     true,
+				"has_certifications": false,
+				// Job types are an enum, so they keep their case.
+				"job_types": pq.StringArray{"FULL_TIME"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			conditions := portfolioConditions(&tt.filters, args)
+
+			if !reflect.DeepEqual(conditions, tt.wantConditions) {
+				t.Errorf("conditions = %v, want %v", conditions, tt.wantConditions)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPortfolioWhere(t *testing.T) {
+	conditions := map[string]string{
+		schemas.PortfolioFacetJobTypes: "c",
+		schemas.PortfolioFacetSkills:   "a",
+		schemas.PortfolioFacetColleges: "b",
+	}
+
+	tests := []struct {
+		name       string
+		conditions map[string]string
+		except     string
+		want       string
+	}{
+		{name: "no conditions", conditions: map[string]string{}, want: "TRUE"},
+		// Facets are joined in a fixed order so the query text is stable.
+		{name: "every facet", conditions: conditions, want: "TRUE AND (a) AND (b) AND (c)"},
+		// A facet's own counts ignore its filter, or picking one value would
+		// hide the others.
+		{name: "except skills", conditions: conditions, except: schemas.PortfolioFacetSkills, want: "TRUE AND (b) AND (c)"},
+		{name: "except an unfiltered facet", conditions: conditions, except: schemas.PortfolioFacetWorkDomains, want: "TRUE AND (a) AND (b) AND (c)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portfolioWhere(tt.conditions, tt.except); got != tt.want {
+				t.Errorf("portfolioWhere(%q) = %q, want %q", tt.except, got, tt.want)
+			}
+		})
+	}
+}
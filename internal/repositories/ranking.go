@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+// trendingHalfLife is how long it takes the weight of an engagement in the
+// trending score to halve.
+const trendingHalfLife = 48 * time.Hour
+
+// scoreColumns are the columns of blogs and user_profiles holding the score of
+// each sort.
+var scoreColumns = map[string]string{
+	schemas.SortTrending: "score_trending",
+	schemas.SortTopWeek:  "score_week",
+	schemas.SortTopMonth: "score_month",
+	schemas.SortTopAll:   "score_all",
+}
+
+// blogEvents lists the engagements of blogs with their weight: reactions
+// count 1, comments 2 and bookmarks 3.
+const blogEvents = `
+	SELECT blog_reactions.blog_id, blog_reactions.created_at, 1.0 AS weight
+	FROM blog_reactions
+	UNION ALL
+	SELECT blog_comments.blog_id, comments.created_at, 2.0
+	FROM blog_comments
+	INNER JOIN comments ON comments.id = blog_comments.comment_id
+	WHERE comments.deleted_at IS NULL
+	UNION ALL
+	SELECT blog_bookmarks.blog_id, blog_bookmarks.created_at, 3.0
+	FROM blog_bookmarks
+`
+
+// eventScores aggregates the weights of the events e. Engagements older than
+// a month barely count towards trending, so they are left out to leave the
+// scores of inactive rows unchanged.
+const eventScores = `
+	coalesce(sum(e.weight * power(0.5, extract(epoch FROM now() - e.created_at) / @half_life)) FILTER (WHERE e.created_at > now() - interval '30 days'), 0)::float8 AS trending,
+	coalesce(sum(e.weight) FILTER (WHERE e.created_at > now() - interval '7 days'), 0)::float8 AS week,
+	coalesce(sum(e.weight) FILTER (WHERE e.created_at > now() - interval '30 days'), 0)::float8 AS month,
+	coalesce(sum(e.weight), 0)::float8 AS all_time
+`
+
+type RepositoryRanking interface {
+	UpdateBlogScores() (int64, error)
+	UpdatePortfolioScores() (int64, error)
+	UpdateSkillUsage() (int64, error)
+	UpdateTagUsage() (int64, error)
+}
+
+type repositoryRanking struct {
+	db *gorm.DB
+}
+
+// UpdateBlogScores recomputes the scores of published blogs, and their feed
+// rank: the publication time pushed forward by 12 hours times
+// ln(1 + engagement), where engagement is the all time score plus a tenth of
+// the views. It returns the number of blogs whose scores changed.
+func (r *repositoryRanking) UpdateBlogScores() (int64, error) {
+	query := `
+		WITH e AS (` + blogEvents + `), scores AS (
+			SELECT blogs.id, ` + eventScores + `
+			FROM blogs
+			LEFT JOIN e ON e.blog_id = blogs.id
+			WHERE blogs.published_at IS NOT NULL AND blogs.deleted_at IS NULL
+			GROUP BY blogs.id
+		), ranked AS (
+			SELECT
+				scores.*,
+				blogs.published_at + interval '12 hours' * ln(
+					1 + scores.all_time + coalesce((blogs.attributes ->> 'views_count')::bigint, 0) / 10.0
+				)::float8 AS rank_at
+			FROM scores
+			INNER JOIN blogs ON blogs.id = scores.id
+		)
+		UPDATE blogs
+		SET
+			score_trending = ranked.trending,
+			score_week = ranked.week,
+			score_month = ranked.month,
+			score_all = ranked.all_time,
+			rank_at = ranked.rank_at
+		FROM ranked
+		WHERE blogs.id = ranked.id
+			AND (blogs.score_trending, blogs.score_week, blogs.score_month, blogs.score_all, blogs.rank_at)
+				IS DISTINCT FROM (ranked.trending, ranked.week, ranked.month, ranked.all_time, ranked.rank_at)
+	`
+
+	res := r.db.Exec(query, map[string]any{"half_life": trendingHalfLife.Seconds()})
+	return res.RowsAffected, res.Error
+}
+
+// UpdatePortfolioScores recomputes the scores of active portfolios from their
+// follows, weighing 3, and the engagements of their blogs. It returns the
+// number of portfolios whose scores changed.
+func (r *repositoryRanking) UpdatePortfolioScores() (int64, error) {
+	query := `
+		WITH blog_events AS (` + blogEvents + `), e AS (
+			SELECT user_follows.following_id AS user_id, user_follows.created_at, 3.0 AS weight
+			FROM user_follows
+			UNION ALL
+			SELECT blogs.user_id, blog_events.created_at, blog_events.weight
+			FROM blog_events
+			INNER JOIN blogs ON blogs.id = blog_events.blog_id
+			WHERE blogs.published_at IS NOT NULL AND blogs.deleted_at IS NULL
+		), scores AS (
+			SELECT user_profiles.user_id, ` + eventScores + `
+			FROM user_profiles
+			LEFT JOIN e ON e.user_id = user_profiles.user_id
+			WHERE user_profiles.portfolio_status = 'ACTIVE'
+			GROUP BY user_profiles.user_id
+		)
+		UPDATE user_profiles
+		SET
+			score_trending = scores.trending,
+			score_week = scores.week,
+			score_month = scores.month,
+			score_all = scores.all_time
+		FROM scores
+		WHERE user_profiles.user_id = scores.user_id
+			AND (user_profiles.score_trending, user_profiles.score_week, user_profiles.score_month, user_profiles.score_all)
+				IS DISTINCT FROM (scores.trending, scores.week, scores.month, scores.all_time)
+	`
+
+	res := r.db.Exec(query, map[string]any{"half_life": trendingHalfLife.Seconds()})
+	return res.RowsAffected, res.Error
+}
+
+// UpdateSkillUsage counts the active portfolios listing each skill, ignoring
+// case. It returns the number of skills whose count changed.
+func (r *repositoryRanking) UpdateSkillUsage() (int64, error) {
+	query := `
+		WITH usage AS (
+			SELECT skills.id, count(user_profiles.user_id) AS usage_count
+			FROM skills
+			LEFT JOIN user_profiles ON user_profiles.portfolio_status = 'ACTIVE'
+				AND user_profiles.deleted_at IS NULL
+				AND EXISTS (
+					SELECT 1
+					FROM jsonb_array_elements_text(coalesce(user_profiles.attributes -> 'skills', '[]')) AS skill
+					WHERE lower(skill) = lower(skills.name)
+				)
+			GROUP BY skills.id
+		)
+		UPDATE skills
+		SET usage_count = usage.usage_count
+		FROM usage
+		WHERE skills.id = usage.id AND skills.usage_count <> usage.usage_count
+	`
+
+	res := r.db.Exec(query)
+	return res.RowsAffected, res.Error
+}
+
+// UpdateTagUsage counts the published blogs tagged with each tag. It returns
+// the number of tags whose count changed.
+func (r *repositoryRanking) UpdateTagUsage() (int64, error) {
+	query := `
+		WITH usage AS (
+			SELECT tags.id, count(blogs.id) AS usage_count
+			FROM tags
+			LEFT JOIN blog_tags ON blog_tags.tag_id = tags.id
+			LEFT JOIN blogs ON blogs.id = blog_tags.blog_id
+				AND blogs.published_at IS NOT NULL
+				AND blogs.deleted_at IS NULL
+			GROUP BY tags.id
+		)
+		UPDATE tags
+		SET usage_count = usage.usage_count
+		FROM usage
+		WHERE tags.id = usage.id AND tags.usage_count <> usage.usage_count
+	`
+
+	res := r.db.Exec(query)
+	return res.RowsAffected, res.Error
+}
+
+// sortOrder is the ORDER BY expression of sort for a list of table, falling
+// back to latest.
+func sortOrder(sort string, table string, id string) string {
+	if column, ok := scoreColumns[sort]; ok {
+		return table + "." + column + " DESC, " + table + "." + id + " DESC"
+	}
+
+	return table + ".updated_at DESC"
+}
+
+func NewRankingRepository(db *gorm.DB) *repositoryRanking {
+	return &repositoryRanking{
+		db: db,
+	}
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RepositoryRevocation interface {
+	Status(userId string, sessionId string, jti string, issuedAt time.Time) (banned bool, revoked bool, err error)
+	IsBanned(userId string) (bool, error)
+	Revoke(kind models.TokenRevocationKind, key string, expiresAt *time.Time) error
+	Ban(ban *models.UserBan) error
+	Unban(userId uuid.UUID) (int64, error)
+	DeleteExpired() (int64, error)
+}
+
+type repositoryRevocation struct {
+	db *gorm.DB
+}
+
+// Status reports whether the user is banned and whether the token described
+// by sessionId, jti and issuedAt has been revoked. Tokens only carry their
+// issue time to the second, so revocations of all the user's tokens are
+// compared at that precision too and spare the tokens issued in the second
+// of the revocation, such as a login right after an unban.
+func (r *repositoryRevocation) Status(userId string, sessionId string, jti string, issuedAt time.Time) (bool, bool, error) {
+	var status struct {
+		Banned  bool
+		Revoked bool
+	}
+
+	query := `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM user_bans
+				WHERE user_id::text = @user AND (banned_until IS NULL OR banned_until > now())
+			) AS banned,
+			EXISTS (
+				SELECT 1 FROM token_revocations
+				WHERE (expires_at IS NULL OR expires_at > now()) AND (
+					(kind = 'session' AND key = @session AND @session <> '') OR
+					(kind = 'jti' AND key = @jti AND @jti <> '') OR
+					(kind = 'user' AND key = @user AND date_trunc('second', revoked_at) > @issued_at)
+				)
+			) AS revoked
+	`
+
+	if err := r.db.Raw(query, map[string]any{
+		"user":      userId,
+		"session":   sessionId,
+		"jti":       jti,
+		"issued_at": issuedAt,
+	}).Scan(&status).Error; err != nil {
+		return false, false, err
+	}
+
+	return status.Banned, status.Revoked, nil
+}
+
+func (r *repositoryRevocation) IsBanned(userId string) (bool, error) {
+	var count int64
+
+	if err := r.db.Model(&models.UserBan{}).
+		Where("user_id::text = ? and (banned_until is null or banned_until > now())", userId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Revoke records a revocation, moving RevokedAt forward if one exists.
+func (r *repositoryRevocation) Revoke(kind models.TokenRevocationKind, key string, expiresAt *time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_at", "expires_at"}),
+	}).Create(&models.TokenRevocation{
+		Kind:      kind,
+		Key:       key,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (r *repositoryRevocation) Ban(ban *models.UserBan) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "banned_until", "created_by", "created_at"}),
+	}).Create(ban).Error
+}
+
+func (r *repositoryRevocation) Unban(userId uuid.UUID) (int64, error) {
+	res := r.db.Where("user_id = ?", userId).Delete(&models.UserBan{})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteExpired removes the revocations of tokens that have expired anyway.
+func (r *repositoryRevocation) DeleteExpired() (int64, error) {
+	res := r.db.Where("expires_at < now()").Delete(&models.TokenRevocation{})
+	return res.RowsAffected, res.Error
+}
+
+func NewRevocationRepository(db *gorm.DB) *repositoryRevocation {
+	return &repositoryRevocation{
+		db: db,
+	}
+}
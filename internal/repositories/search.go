@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+// searchSimilarityThreshold is how close a word of a title has to be to the
+// search for it to match despite typos.
+const searchSimilarityThreshold = 0.4
+
+// searchMatches selects everything matching @q, either through full text
+// search or, to tolerate typos, through trigram similarity to the title. The
+// document column is HTML escaped so snippets only ever contain the <mark>
+// tags ts_headline adds.
+const searchMatches = `
+	WITH search AS (
+		SELECT websearch_to_tsquery('english', @q) AS query
+	), matches AS (
+		SELECT
+			'portfolios' AS type,
+			user_profiles.user_id::text AS id,
+			coalesce(user_profiles.full_name, user_profiles.slug) AS title,
+			user_profiles.slug,
+			user_profiles.avatar_url AS image,
+			NULL::text AS owner_name,
+			NULL::text AS owner_slug,
+			concat_ws(' ', user_profiles.full_name, user_profiles.attributes ->> 'tagline', user_profiles.attributes ->> 'college') AS document,
+			ts_rank(user_profiles.fts, search.query) + word_similarity(@q, coalesce(user_profiles.full_name, '')) AS rank
+		FROM user_profiles, search
+		WHERE user_profiles.portfolio_status = 'ACTIVE'
+			AND user_profiles.deleted_at IS NULL
+			AND (user_profiles.fts @@ search.query OR @q <% user_profiles.full_name)
+		UNION ALL
+		SELECT
+			'blogs',
+			blogs.id::text,
+			blogs.title,
+			blogs.slug,
+			blogs.cover_image,
+			user_profiles.full_name,
+			user_profiles.slug,
+			coalesce(blogs.body, blogs.title),
+			ts_rank(blogs.fts, search.query) + word_similarity(@q, blogs.title)
+		FROM blogs
+		INNER JOIN user_profiles ON user_profiles.user_id = blogs.user_id, search
+		WHERE blogs.published_at IS NOT NULL
+			AND blogs.deleted_at IS NULL
+			AND user_profiles.deleted_at IS NULL
+			AND (blogs.fts @@ search.query OR @q <% blogs.title)
+		UNION ALL
+		SELECT
+			'projects',
+			tech_projects.id::text,
+			tech_projects.title,
+			NULL,
+			NULL,
+			user_profiles.full_name,
+			user_profiles.slug,
+			tech_projects.description,
+			ts_rank(tech_projects.fts, search.query) + word_similarity(@q, tech_projects.title)
+		FROM tech_projects
+		INNER JOIN user_profiles ON user_profiles.user_id = tech_projects.user_id, search
+		WHERE tech_projects.deleted_at IS NULL
+			AND user_profiles.portfolio_status = 'ACTIVE'
+			AND user_profiles.deleted_at IS NULL
+			AND (tech_projects.fts @@ search.query OR @q <% tech_projects.title)
+		UNION ALL
+		SELECT
+			'skills',
+			skills.id::text,
+			skills.name,
+			NULL,
+			skills.image,
+			NULL,
+			NULL,
+			NULL,
+			CASE WHEN strpos(lower(skills.name), lower(@q)) > 0 THEN 1 ELSE 0 END + word_similarity(@q, skills.name)
+		FROM skills
+		WHERE strpos(lower(skills.name), lower(@q)) > 0 OR @q <% skills.name
+	)
+`
+
+type RepositorySearch interface {
+	Search(q string, searchType string, cursor int, limit int) ([]schemas.SelectSearchResult, []schemas.SelectSearchFacet, error)
+}
+
+type repositorySearch struct {
+	db *gorm.DB
+}
+
+// Search returns the matches of searchType, best first, along with the
+// number of matches of each type.
+func (r *repositorySearch) Search(q string, searchType string, cursor int, limit int) ([]schemas.SelectSearchResult, []schemas.SelectSearchFacet, error) {
+	var results []schemas.SelectSearchResult
+	var facets []schemas.SelectSearchFacet
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setSimilarityThreshold(tx); err != nil {
+			return err
+		}
+
+		facetsQuery := searchMatches + `
+			SELECT type, count(*) AS count
+			FROM matches
+			GROUP BY type
+		`
+		if err := tx.Raw(facetsQuery, map[string]any{"q": q}).Scan(&facets).Error; err != nil {
+			return err
+		}
+
+		resultsQuery := searchMatches + `
+			SELECT
+				page.type,
+				page.id,
+				page.title,
+				page.slug,
+				ts_headline(
+					'english',
+					replace(replace(replace(page.document, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+					search.query,
+					'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2'
+				) AS snippet,
+				page.image,
+				page.owner_name,
+				page.owner_slug,
+				page.rank
+			FROM (
+				SELECT *
+				FROM matches
+				WHERE @type = 'all' OR matches.type = @type
+				ORDER BY matches.rank DESC, matches.type, matches.id
+				LIMIT @limit OFFSET @cursor
+			) AS page, search
+			ORDER BY page.rank DESC, page.type, page.id
+		`
+		args := map[string]any{"q": q, "type": searchType, "limit": limit, "cursor": cursor}
+		return tx.Raw(resultsQuery, args).Scan(&results).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return results, facets, nil
+}
+
+// setSimilarityThreshold sets the threshold of the <% operator for the rest
+// of the transaction tx.
+func setSimilarityThreshold(tx *gorm.DB) error {
+	return tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", searchSimilarityThreshold).Error
+}
+
+func NewSearchRepository(db *gorm.DB) *repositorySearch {
+	return &repositorySearch{
+		db: db,
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 type RepositorySkill interface {
 	GetAll(userId *string, query *string, cursor int, limit int) (*models.Skills, error)
+	CountAll(query *string) (int64, error)
 	GetUserSkills(userId string) (*models.Skills, error)
 }
 
@@ -20,11 +21,11 @@ type repositorySkill struct {
 func (r *repositorySkill) GetAll(query *string, cursor int, limit int) (*models.Skills, error) {
 	var skills models.Skills
 	if query != nil && *query != "" {
-		if err := r.db.Where("LOWER(name) ILIKE ?", "%"+strings.ToLower(*query)+"%").Offset(cursor).Limit(limit).Find(&skills).Error; err != nil {
+		if err := r.db.Where("LOWER(name) ILIKE ?", "%"+strings.ToLower(*query)+"%").Order("usage_count DESC, name").Offset(cursor).Limit(limit).Find(&skills).Error; err != nil {
 			return nil, err
 		}
 	} else {
-		if err := r.db.Offset(cursor).Limit(limit).Find(&skills).Error; err != nil {
+		if err := r.db.Order("usage_count DESC, name").Offset(cursor).Limit(limit).Find(&skills).Error; err != nil {
 			return nil, err
 		}
 	}
@@ -32,6 +33,19 @@ func (r *repositorySkill) GetAll(query *string, cursor int, limit int) (*models.
 	return &skills, nil
 }
 
+func (r *repositorySkill) CountAll(query *string) (int64, error) {
+	var total int64
+	db := r.db.Model(&models.Skill{})
+	if query != nil && *query != "" {
+		db = db.Where("LOWER(name) ILIKE ?", "%"+strings.ToLower(*query)+"%")
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (r *repositorySkill) GetUserSkills(userId string) (*models.Skills, error) {
 	var rows *sql.Rows
 	var err error
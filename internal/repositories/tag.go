@@ -1,45 +1,269 @@
 package repositories
 
 import (
+	"github.com/google/uuid"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// selectTags selects tags as schemas.SelectTag for the user @user_id, who
+// may be NULL.
+const selectTags = `
+	SELECT
+		tags.id,
+		tags.name,
+		nullif(tags.description, '') AS description,
+		array(SELECT tag_aliases.name FROM tag_aliases WHERE tag_aliases.tag_id = tags.id ORDER BY tag_aliases.name) AS aliases,
+		tags.usage_count,
+		(SELECT count(*) FROM tag_follows WHERE tag_follows.tag_id = tags.id) AS followers_count,
+		CASE
+			WHEN CAST(@user_id AS uuid) IS NULL THEN NULL
+			ELSE EXISTS (SELECT 1 FROM tag_follows WHERE tag_follows.tag_id = tags.id AND tag_follows.user_id = CAST(@user_id AS uuid))
+		END AS is_following,
+		tags.deprecated_at
+	FROM tags
+`
+
 type RepositoryTag interface {
-	FindOrCreate(userId string, tags []string) (*models.Tags, error)
+	FindOrCreate(names []string) (*models.Tags, error)
+	GetByName(name string) (*models.Tag, error)
+	GetTag(userId *string, id uint) (*schemas.SelectTag, error)
+	GetPopular(limit int) ([]schemas.SelectTag, error)
+	GetFollowed(userId string, cursor int, limit int) ([]schemas.SelectTag, error)
+	CountFollowed(userId string) (int64, error)
+	GetBlogTagIds(blogId string) ([]uint, error)
+	GetBlogOwnerIds(tagId uint) ([]string, error)
+	Follow(userId uuid.UUID, tagId uint) error
+	Unfollow(userId uuid.UUID, tagId uint) error
+	Update(id uint, values map[string]any) error
+	Merge(sourceId uint, targetId uint) error
+	AddAlias(tagId uint, name string) error
+	RemoveAlias(tagId uint, name string) (int64, error)
 }
 
 type repositoryTag struct {
 	db *gorm.DB
 }
 
-func (r *repositoryTag) FindOrCreate(userId string, tags []string) (*models.Tags, error) {
+// FindOrCreate resolves names, normalized with schemas.NormalizeTag, to their
+// tags, following aliases, and creates the tags that don't exist yet. Names
+// resolving to the same tag yield it once.
+func (r *repositoryTag) FindOrCreate(names []string) (*models.Tags, error) {
+	tags := models.Tags{}
+	if len(names) == 0 {
+		return &tags, nil
+	}
+
+	var aliases models.TagAliases
+	if err := r.db.Where("name IN ?", names).Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+
+	aliasTagIds := map[string]uint{}
+	var ids []uint
+	for _, alias := range aliases {
+		aliasTagIds[alias.Name] = alias.TagId
+		ids = append(ids, alias.TagId)
+	}
 
-	var existingTags models.Tags
-	r.db.Where("name in ?", tags).Find(&existingTags)
+	var existing models.Tags
+	if err := r.db.Where("lower(name) IN ?", names).Or("id IN ?", append(ids, 0)).Find(&existing).Error; err != nil {
+		return nil, err
+	}
 
-	existingTagNames := map[string]bool{}
-	for _, tag := range existingTags {
-		existingTagNames[tag.Name] = true
+	byId := map[uint]models.Tag{}
+	byName := map[string]models.Tag{}
+	for _, tag := range existing {
+		byId[tag.ID] = tag
+		byName[schemas.NormalizeTag(tag.Name)] = tag
 	}
 
-	var newTags models.Tags
-	for _, tagName := range tags {
-		if !existingTagNames[tagName] {
-			newTags = append(newTags, models.Tag{Name: tagName, Attributes: []byte("{}")})
+	seen := map[uint]bool{}
+	for _, name := range names {
+		tag, ok := byId[aliasTagIds[name]]
+		if !ok {
+			tag, ok = byName[name]
+		}
+		if !ok {
+			var err error
+			if tag, err = r.create(name); err != nil {
+				return nil, err
+			}
+			byName[name] = tag
+		}
+
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, tag)
 		}
 	}
 
-	if len(newTags) > 0 {
-		result := r.db.Create(&newTags)
-		if result.Error != nil {
-			panic(result.Error)
+	return &tags, nil
+}
+
+// create creates the tag name, or returns it if it was created concurrently.
+func (r *repositoryTag) create(name string) (models.Tag, error) {
+	tag := models.Tag{Name: name, Attributes: []byte("{}")}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+		return tag, err
+	}
+
+	if tag.ID == 0 {
+		err := r.db.Where("name = ?", name).First(&tag).Error
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// GetByName returns the tag named name or having it as an alias, ignoring
+// case.
+func (r *repositoryTag) GetByName(name string) (*models.Tag, error) {
+	var tag models.Tag
+
+	name = schemas.NormalizeTag(name)
+	if err := r.db.Where("lower(name) = ? OR id IN (SELECT tag_id FROM tag_aliases WHERE name = ?)", name, name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (r *repositoryTag) GetTag(userId *string, id uint) (*schemas.SelectTag, error) {
+	var tag schemas.SelectTag
+
+	query := selectTags + " WHERE tags.id = @id"
+	if err := r.db.Raw(query, map[string]any{"user_id": userId, "id": id}).Scan(&tag).Error; err != nil {
+		return nil, err
+	}
+	if tag.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &tag, nil
+}
+
+// GetPopular returns the tags carried by the most published blogs, leaving
+// out deprecated ones.
+func (r *repositoryTag) GetPopular(limit int) ([]schemas.SelectTag, error) {
+	var tags []schemas.SelectTag
+
+	query := selectTags + `
+		WHERE tags.deleted_at IS NULL AND tags.deprecated_at IS NULL
+		ORDER BY tags.usage_count DESC, tags.id
+		LIMIT @limit
+	`
+	if err := r.db.Raw(query, map[string]any{"user_id": nil, "limit": limit}).Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetFollowed returns the tags userId follows, most recently followed first.
+func (r *repositoryTag) GetFollowed(userId string, cursor int, limit int) ([]schemas.SelectTag, error) {
+	var tags []schemas.SelectTag
+
+	query := selectTags + `
+		INNER JOIN tag_follows follows ON follows.tag_id = tags.id AND follows.user_id = CAST(@user_id AS uuid)
+		WHERE tags.deleted_at IS NULL
+		ORDER BY follows.created_at DESC, tags.id
+		LIMIT @limit OFFSET @cursor
+	`
+	args := map[string]any{"user_id": userId, "limit": limit, "cursor": cursor}
+	if err := r.db.Raw(query, args).Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *repositoryTag) CountFollowed(userId string) (int64, error) {
+	var total int64
+	if err := r.db.Model(&models.TagFollow{}).Where("user_id = ?", userId).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repositoryTag) GetBlogTagIds(blogId string) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&models.BlogTag{}).Where("blog_id = ?", blogId).Pluck("tag_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetBlogOwnerIds returns the authors of the blogs carrying tagId.
+func (r *repositoryTag) GetBlogOwnerIds(tagId uint) ([]string, error) {
+	var ids []string
+
+	query := `
+		SELECT DISTINCT blogs.user_id::text
+		FROM blog_tags
+		INNER JOIN blogs ON blogs.id = blog_tags.blog_id
+		WHERE blog_tags.tag_id = ?
+	`
+	if err := r.db.Raw(query, tagId).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *repositoryTag) Follow(userId uuid.UUID, tagId uint) error {
+	follow := models.TagFollow{UserId: userId, TagId: tagId}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error
+}
+
+func (r *repositoryTag) Unfollow(userId uuid.UUID, tagId uint) error {
+	return r.db.Where("user_id = ? AND tag_id = ?", userId, tagId).Delete(&models.TagFollow{}).Error
+}
+
+func (r *repositoryTag) Update(id uint, values map[string]any) error {
+	return r.db.Model(&models.Tag{}).Where("id = ?", id).Updates(values).Error
+}
+
+// Merge moves the blogs, followers and aliases of sourceId to targetId, makes
+// the name of sourceId an alias of targetId and deletes sourceId.
+func (r *repositoryTag) Merge(sourceId uint, targetId uint) error {
+	args := map[string]any{"source": sourceId, "target": targetId}
+
+	statements := []string{
+		`INSERT INTO blog_tags (blog_id, tag_id)
+			SELECT blog_id, @target FROM blog_tags WHERE tag_id = @source
+			ON CONFLICT DO NOTHING`,
+		`INSERT INTO tag_follows (user_id, tag_id, created_at)
+			SELECT user_id, @target, created_at FROM tag_follows WHERE tag_id = @source
+			ON CONFLICT DO NOTHING`,
+		`UPDATE tag_aliases SET tag_id = @target WHERE tag_id = @source`,
+		`INSERT INTO tag_aliases (name, tag_id)
+			SELECT lower(name), @target FROM tags WHERE id = @source
+			ON CONFLICT (name) DO UPDATE SET tag_id = excluded.tag_id`,
+		`DELETE FROM tags WHERE id = @source`,
+	}
+
+	for _, statement := range statements {
+		if err := r.db.Exec(statement, args).Error; err != nil {
+			return err
 		}
 	}
 
-	combinedTags := append(existingTags, newTags...)
+	return nil
+}
+
+func (r *repositoryTag) AddAlias(tagId uint, name string) error {
+	alias := models.TagAlias{Name: name, TagId: tagId}
+	return r.db.Create(&alias).Error
+}
 
-	return &combinedTags, nil
+func (r *repositoryTag) RemoveAlias(tagId uint, name string) (int64, error) {
+	res := r.db.Where("tag_id = ? AND name = ?", tagId, name).Delete(&models.TagAlias{})
+	return res.RowsAffected, res.Error
 }
 
 func NewTagRepository(db *gorm.DB) *repositoryTag {
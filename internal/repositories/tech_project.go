@@ -17,6 +17,8 @@ import (
 type RepositoryUserTechProject interface {
 	GetAll(userId *string, query *string, cursor int, limit int) (*[]schemas.SelectUserTechProject, error)
 	GetUserTechProjects(userId string, query *string, cursor int, limit int) (*[]schemas.SelectUserTechProject, error)
+	CountAll(query *string) (int64, error)
+	CountUserTechProjects(userId string, query *string) (int64, error)
 	Get(userId string, id string) (any, error)
 	Create(userId string, data *schemas.SchemaTechProject) (*models.TechProject, error)
 	Update(userId string, id string, data *schemas.SchemaTechProject) (*models.TechProject, error)
@@ -64,7 +66,7 @@ func (r *repositoryUserTechProject) GetAll(userId *string, query *string, cursor
 	args = append(args, limit, cursor)
 
 	if query != nil && *query != "" {
-		baseQuery += " where tech_projects.fts @@ to_tsquery(?)"
+		baseQuery += " where tech_projects.fts @@ websearch_to_tsquery('english', ?)"
 		args = append([]interface{}{*query}, args...)
 	}
 
@@ -154,7 +156,7 @@ func (r *repositoryUserTechProject) GetUserTechProjects(userId string, query *st
 	args = append(args, limit, cursor)
 
 	if query != nil && *query != "" {
-		baseQuery += " AND tech_projects.fts @@ to_tsquery(?)"
+		baseQuery += " AND tech_projects.fts @@ websearch_to_tsquery('english', ?)"
 		args = append([]interface{}{*query}, args...)
 	}
 
@@ -208,6 +210,47 @@ func (r *repositoryUserTechProject) GetUserTechProjects(userId string, query *st
 	return &userTechProjects, nil
 }
 
+func (r *repositoryUserTechProject) CountAll(query *string) (int64, error) {
+	baseQuery := `
+		select count(*)
+		from tech_projects
+	`
+
+	var args []any
+	if query != nil && *query != "" {
+		baseQuery += " where tech_projects.fts @@ websearch_to_tsquery('english', ?)"
+		args = append(args, *query)
+	}
+
+	var total int64
+	if err := r.db.Raw(baseQuery, args...).Row().Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repositoryUserTechProject) CountUserTechProjects(userId string, query *string) (int64, error) {
+	baseQuery := `
+		select count(*)
+		from tech_projects
+		where tech_projects.user_id = ?
+	`
+
+	args := []any{userId}
+	if query != nil && *query != "" {
+		baseQuery += " AND tech_projects.fts @@ websearch_to_tsquery('english', ?)"
+		args = append(args, *query)
+	}
+
+	var total int64
+	if err := r.db.Raw(baseQuery, args...).Row().Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (r *repositoryUserTechProject) Get(userId string, id string) (any, error) {
 	var rows *sql.Rows
 	var err error
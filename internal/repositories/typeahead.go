@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+// typeaheadQueries select the completions of @q of each kind. Names starting
+// with @q come first, then names with a word close to it, each by popularity.
+var typeaheadQueries = map[string]string{
+	schemas.TypeaheadSkills: `
+		SELECT skills.name AS value, skills.name AS label, skills.image, skills.usage_count AS count
+		FROM skills
+		WHERE skills.name ILIKE @prefix OR @q <% skills.name
+		ORDER BY skills.name ILIKE @prefix DESC, skills.usage_count DESC, word_similarity(@q, skills.name) DESC, skills.name
+		LIMIT @limit
+	`,
+	schemas.TypeaheadTags: `
+		SELECT tags.name AS value, tags.name AS label, NULL AS image, tags.usage_count AS count
+		FROM tags
+		WHERE tags.deleted_at IS NULL AND tags.deprecated_at IS NULL AND (tags.name ILIKE @prefix OR @q <% tags.name)
+		ORDER BY tags.name ILIKE @prefix DESC, tags.usage_count DESC, word_similarity(@q, tags.name) DESC, tags.name
+		LIMIT @limit
+	`,
+	schemas.TypeaheadInstitutes: `
+		SELECT
+			mode() WITHIN GROUP (ORDER BY educations.institute_name) AS value,
+			mode() WITHIN GROUP (ORDER BY educations.institute_name) AS label,
+			NULL AS image,
+			count(DISTINCT educations.user_id) AS count
+		FROM educations
+		INNER JOIN user_profiles ON user_profiles.user_id = educations.user_id
+		WHERE educations.deleted_at IS NULL
+			AND user_profiles.portfolio_status = 'ACTIVE'
+			AND user_profiles.deleted_at IS NULL
+			AND (educations.institute_name ILIKE @prefix OR @q <% educations.institute_name)
+		GROUP BY lower(educations.institute_name)
+		ORDER BY bool_or(educations.institute_name ILIKE @prefix) DESC, count DESC, max(word_similarity(@q, educations.institute_name)) DESC, value
+		LIMIT @limit
+	`,
+	schemas.TypeaheadCompanies: `
+		SELECT
+			mode() WITHIN GROUP (ORDER BY work_experiences.company_name) AS value,
+			mode() WITHIN GROUP (ORDER BY work_experiences.company_name) AS label,
+			NULL AS image,
+			count(DISTINCT work_experiences.user_id) AS count
+		FROM work_experiences
+		INNER JOIN user_profiles ON user_profiles.user_id = work_experiences.user_id
+		WHERE work_experiences.deleted_at IS NULL
+			AND user_profiles.portfolio_status = 'ACTIVE'
+			AND user_profiles.deleted_at IS NULL
+			AND (work_experiences.company_name ILIKE @prefix OR @q <% work_experiences.company_name)
+		GROUP BY lower(work_experiences.company_name)
+		ORDER BY bool_or(work_experiences.company_name ILIKE @prefix) DESC, count DESC, max(word_similarity(@q, work_experiences.company_name)) DESC, value
+		LIMIT @limit
+	`,
+	schemas.TypeaheadUsers: `
+		SELECT
+			user_profiles.slug AS value,
+			coalesce(user_profiles.full_name, user_profiles.slug) AS label,
+			user_profiles.avatar_url AS image,
+			(SELECT count(*) FROM user_follows WHERE user_follows.following_id = user_profiles.user_id) AS count
+		FROM user_profiles
+		WHERE user_profiles.portfolio_status = 'ACTIVE'
+			AND user_profiles.deleted_at IS NULL
+			AND (
+				user_profiles.slug ILIKE @prefix
+				OR user_profiles.full_name ILIKE @prefix
+				OR @q <% user_profiles.slug
+				OR @q <% user_profiles.full_name
+			)
+		ORDER BY
+			(user_profiles.slug ILIKE @prefix OR user_profiles.full_name ILIKE @prefix) DESC,
+			count DESC,
+			greatest(word_similarity(@q, user_profiles.slug), word_similarity(@q, coalesce(user_profiles.full_name, ''))) DESC,
+			value
+		LIMIT @limit
+	`,
+}
+
+var errUnknownTypeahead = errors.New("unknown typeahead kind")
+
+type RepositoryTypeahead interface {
+	Suggest(kind string, q string, limit int) ([]schemas.SelectSuggestion, error)
+}
+
+type repositoryTypeahead struct {
+	db *gorm.DB
+}
+
+// Suggest returns up to limit completions of q of kind.
+func (r *repositoryTypeahead) Suggest(kind string, q string, limit int) ([]schemas.SelectSuggestion, error) {
+	query, ok := typeaheadQueries[kind]
+	if !ok {
+		return nil, errUnknownTypeahead
+	}
+
+	var suggestions []schemas.SelectSuggestion
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setSimilarityThreshold(tx); err != nil {
+			return err
+		}
+
+		args := map[string]any{"q": q, "prefix": likePrefix(q), "limit": limit}
+		return tx.Raw(query, args).Scan(&suggestions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// likePrefix is the LIKE pattern of the values starting with s.
+func likePrefix(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
+}
+
+func NewTypeaheadRepository(db *gorm.DB) *repositoryTypeahead {
+	return &repositoryTypeahead{
+		db: db,
+	}
+}
@@ -24,8 +24,11 @@ type RepositoryUser interface {
 	GetModuleMetadata(userId string, module string) (any, error)
 	UpdateStatus(userId string, status models.PortfolioStatus) error
 	UpdateProfileAttachment(userId string, module string, url *string) error
+	UpdatePrivacy(userId string, data *schemas.SchemaProfilePrivacy) error
 	GetFollowers(userId string, cursor int, limit int) (*[]schemas.SelectFollowers, error)
 	GetFollowing(userId string, cursor int, limit int) (*[]schemas.SelectFollowing, error)
+	CountFollowers(userId string) (int64, error)
+	CountFollowing(userId string) (int64, error)
 	FollowUser(userId uuid.UUID, followingUserId uuid.UUID) error
 	UnfollowUser(userId uuid.UUID, followingUserId uuid.UUID) error
 	FollowStatus(userId uuid.UUID, followingUserId uuid.UUID) (*models.UserFollow, error)
@@ -182,6 +185,10 @@ func (r *repositoryUser) GetModuleMetadata(userId string, module string) (interf
 	return data, nil
 }
 
+func (r *repositoryUser) UpdatePrivacy(userId string, data *schemas.SchemaProfilePrivacy) error {
+	return r.db.Model(&models.UserProfile{}).Where("user_id = ?", userId).Update("share_portfolio_views", *data.SharePortfolioViews).Error
+}
+
 func (r *repositoryUser) AddOrUpdateModuleMetadata(userId string, module string, data interface{}) error {
 	t := map[string]any{
 		"attributes": datatypes.JSONSet("attributes").
@@ -311,6 +318,24 @@ func (r *repositoryUser) GetFollowing(userId string, cursor int, limit int) (*[]
 	return &results, nil
 }
 
+func (r *repositoryUser) CountFollowers(userId string) (int64, error) {
+	var total int64
+	if err := r.db.Model(&models.UserFollow{}).Where("following_id = ?", userId).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repositoryUser) CountFollowing(userId string) (int64, error) {
+	var total int64
+	if err := r.db.Model(&models.UserFollow{}).Where("follower_id = ?", userId).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (r *repositoryUser) FollowUser(userId uuid.UUID, followingUserId uuid.UUID) error {
 	userFollower := models.UserFollow{
 		FollowerId:  userId,
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RepositoryWebhook interface {
+	GetAll(userId uuid.UUID) ([]models.WebhookEndpoint, error)
+	Get(userId uuid.UUID, id uint) (*models.WebhookEndpoint, error)
+	Count(userId uuid.UUID) (int64, error)
+	Create(endpoint *models.WebhookEndpoint) error
+	Update(userId uuid.UUID, id uint, values map[string]any) (*models.WebhookEndpoint, error)
+	Delete(userId uuid.UUID, id uint) (int64, error)
+	GetDeliveries(endpointId uint, cursor int, limit int) ([]models.WebhookDelivery, error)
+	CountDeliveries(endpointId uint) (int64, error)
+	GetDelivery(endpointId uint, id uint) (*models.WebhookDelivery, error)
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	Enqueue(userId uuid.UUID, eventType string, eventId uuid.UUID, payload []byte) error
+	ClaimDue(limit int, lease time.Duration) ([]schemas.SelectPendingWebhookDelivery, error)
+	RecordAttempt(delivery *models.WebhookDelivery) error
+	DeleteFinishedDeliveries(before time.Time) (int64, error)
+}
+
+type repositoryWebhook struct {
+	db *gorm.DB
+}
+
+func (r *repositoryWebhook) GetAll(userId uuid.UUID) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+
+	if err := r.db.Where("user_id = ?", userId).Order("created_at desc").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *repositoryWebhook) Get(userId uuid.UUID, id uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+
+	if err := r.db.Where("id = ? and user_id = ?", id, userId).First(&endpoint).Error; err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (r *repositoryWebhook) Count(userId uuid.UUID) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.WebhookEndpoint{}).Where("user_id = ?", userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *repositoryWebhook) Create(endpoint *models.WebhookEndpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+// Update returns gorm.ErrRecordNotFound when the user has no such endpoint.
+func (r *repositoryWebhook) Update(userId uuid.UUID, id uint, values map[string]any) (*models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+
+	values["updated_at"] = gorm.Expr("now()")
+	if err := r.db.Model(&endpoints).Clauses(clause.Returning{}).Where("id = ? and user_id = ?", id, userId).Updates(values).Error; err != nil {
+		return nil, err
+	}
+
+	if len(endpoints) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &endpoints[0], nil
+}
+
+func (r *repositoryWebhook) Delete(userId uuid.UUID, id uint) (int64, error) {
+	res := r.db.Where("id = ? and user_id = ?", id, userId).Delete(&models.WebhookEndpoint{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *repositoryWebhook) GetDeliveries(endpointId uint, cursor int, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	if err := r.db.Where("endpoint_id = ?", endpointId).Order("created_at desc, id desc").Offset(cursor).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *repositoryWebhook) CountDeliveries(endpointId uint) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *repositoryWebhook) GetDelivery(endpointId uint, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	if err := r.db.Where("id = ? and endpoint_id = ?", id, endpointId).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *repositoryWebhook) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// Enqueue adds a pending delivery of the event to every active endpoint of
+// the user subscribed to its type.
+func (r *repositoryWebhook) Enqueue(userId uuid.UUID, eventType string, eventId uuid.UUID, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		SELECT id, ?, ?, ?::jsonb
+		FROM webhook_endpoints
+		WHERE user_id = ? AND active AND ? = ANY(event_types)
+	`
+
+	return r.db.Exec(query, eventId, eventType, string(payload), userId, eventType).Error
+}
+
+// ClaimDue picks pending deliveries that are due and pushes their next
+// attempt out by lease, so another worker won't pick them up while they're
+// being sent. A worker that dies mid-send has its deliveries retried once
+// the lease runs out.
+func (r *repositoryWebhook) ClaimDue(limit int, lease time.Duration) ([]schemas.SelectPendingWebhookDelivery, error) {
+	var deliveries []schemas.SelectPendingWebhookDelivery
+
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = now() + make_interval(secs => ?)
+			WHERE id IN (
+				SELECT id
+				FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT claimed.*, webhook_endpoints.url, webhook_endpoints.secret, webhook_endpoints.active
+		FROM claimed
+		INNER JOIN webhook_endpoints ON webhook_endpoints.id = claimed.endpoint_id
+	`
+
+	if err := r.db.Raw(query, lease.Seconds(), limit).Scan(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt saves the outcome of an attempt to send the delivery.
+func (r *repositoryWebhook) RecordAttempt(delivery *models.WebhookDelivery) error {
+	return r.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]any{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"last_attempt_at": delivery.LastAttemptAt,
+		"response_status": delivery.ResponseStatus,
+		"response_body":   delivery.ResponseBody,
+		"error":           delivery.Error,
+	}).Error
+}
+
+// DeleteFinishedDeliveries removes the deliveries created before the given
+// time that are no longer being retried.
+func (r *repositoryWebhook) DeleteFinishedDeliveries(before time.Time) (int64, error) {
+	res := r.db.Where("status <> ? and created_at < ?", models.WebhookDeliveryPending, before).Delete(&models.WebhookDelivery{})
+	return res.RowsAffected, res.Error
+}
+
+func NewWebhookRepository(db *gorm.DB) *repositoryWebhook {
+	return &repositoryWebhook{
+		db: db,
+	}
+}
@@ -0,0 +1,81 @@
+package schemas
+
+// BlogView is one read of a blog, counted once per viewer per day. Day is
+// formatted as YYYY-MM-DD in UTC. Viewer is the salted hash of the reader and
+// ReaderId the signed in reader, which is only used to skip authors reading
+// their own blogs and is never stored.
+type BlogView struct {
+	Slug     string
+	Day      string
+	Viewer   string
+	ReaderId string
+	Referrer string
+}
+
+// PortfolioVisit is one visit of a portfolio module, counted once per
+// visitor per day. Visitor is the salted hash of the visitor and ViewerId
+// the signed in visitor, if any.
+type PortfolioVisit struct {
+	Slug         string
+	Module       string
+	Day          string
+	Visitor      string
+	ViewerId     string
+	ReferrerHost string
+	Country      string
+}
+
+type SelectBlogDailyStats struct {
+	Day       string `json:"day"`
+	Views     int64  `json:"views"`
+	Reactions int64  `json:"reactions"`
+	Comments  int64  `json:"comments"`
+}
+
+type SelectReferrerCount struct {
+	Referrer string `json:"referrer"`
+	Views    int64  `json:"views"`
+}
+
+type BlogAnalytics struct {
+	BlogId        uint                   `json:"blog_id"`
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	Views         int64                  `json:"views"`
+	UniqueReaders int64                  `json:"unique_readers"`
+	Daily         []SelectBlogDailyStats `json:"daily"`
+	Referrers     []SelectReferrerCount  `json:"referrers"`
+}
+
+type SelectPortfolioDailyStats struct {
+	Day      string `json:"day"`
+	Views    int64  `json:"views"`
+	Visitors int64  `json:"visitors"`
+}
+
+type SelectAnalyticsBreakdown struct {
+	Value    string `json:"value"`
+	Views    int64  `json:"views"`
+	Visitors int64  `json:"visitors"`
+}
+
+type SelectPortfolioViewer struct {
+	UserId        string  `json:"user_id"`
+	Name          *string `json:"name"`
+	Avatar        *string `json:"avatar"`
+	Slug          *string `json:"slug"`
+	LastVisitedOn string  `json:"last_visited_on"`
+	Visits        int64   `json:"visits"`
+}
+
+type PortfolioAnalytics struct {
+	From      string                      `json:"from"`
+	To        string                      `json:"to"`
+	Views     int64                       `json:"views"`
+	Visitors  int64                       `json:"visitors"`
+	Daily     []SelectPortfolioDailyStats `json:"daily"`
+	Modules   []SelectAnalyticsBreakdown  `json:"modules"`
+	Referrers []SelectAnalyticsBreakdown  `json:"referrers"`
+	Countries []SelectAnalyticsBreakdown  `json:"countries"`
+	Viewers   []SelectPortfolioViewer     `json:"viewers"`
+}
@@ -1,6 +1,6 @@
 package schemas
 
-import "github.com/go-playground/validator/v10"
+import "github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 
 type Attachment struct {
 	FileName string `json:"name" validate:"required,min=3,max=100"`
@@ -10,7 +10,7 @@ type Attachment struct {
 }
 
 func (s *Attachment) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -0,0 +1,74 @@
+package schemas
+
+import (
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+type SchemaSignup struct {
+	Email    string `json:"email" binding:"required" validate:"required,email,max=255"`
+	Password string `json:"password" binding:"required" validate:"required,min=8,max=72"`
+}
+
+func (s *SchemaSignup) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaLogin struct {
+	Email    string `json:"email" binding:"required" validate:"required,email"`
+	Password string `json:"password" binding:"required" validate:"required"`
+}
+
+func (s *SchemaLogin) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaVerify struct {
+	Type      string `json:"type" binding:"required" validate:"required,oneof=signup recovery"`
+	TokenHash string `json:"token_hash" binding:"required" validate:"required"`
+}
+
+func (s *SchemaVerify) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaRefresh struct {
+	RefreshToken string `json:"refresh_token" binding:"required" validate:"required"`
+}
+
+func (s *SchemaRefresh) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaRecover struct {
+	Email string `json:"email" binding:"required" validate:"required,email"`
+}
+
+func (s *SchemaRecover) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaUpdatePassword struct {
+	Password string `json:"password" binding:"required" validate:"required,min=8,max=72"`
+}
+
+func (s *SchemaUpdatePassword) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+// Session is returned by every endpoint that signs a user in. The access
+// token has the same claims as the ones Supabase issues.
+type Session struct {
+	AccessToken  string           `json:"access_token"`
+	TokenType    string           `json:"token_type"`
+	ExpiresIn    int              `json:"expires_in"`
+	ExpiresAt    int64            `json:"expires_at"`
+	RefreshToken string           `json:"refresh_token"`
+	User         *models.AuthUser `json:"user"`
+}
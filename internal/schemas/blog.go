@@ -3,7 +3,7 @@ package schemas
 import (
 	"time"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 	"github.com/lib/pq"
 	"gorm.io/datatypes"
 )
@@ -17,7 +17,7 @@ type SchemaBlog struct {
 }
 
 func (s SchemaBlog) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -27,7 +27,7 @@ type SchemaBlogMetadata struct {
 }
 
 func (s *SchemaBlogMetadata) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -43,9 +43,25 @@ type SelectBlog struct {
 	PublishedAt       *time.Time      `json:"published_at"`
 	CommentsCount     *int            `json:"comments_count"`
 	ReactionsMetadata *datatypes.JSON `json:"reactions_metadata"`
+	ViewsCount        *int            `json:"views_count"`
 	IsBookmarked      *bool           `json:"is_bookmarked"`
 	Reactions         *pq.StringArray `json:"reactions"`
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
 	Tags              *pq.StringArray `json:"tags" gorm:"type:text"`
 }
+
+// SelectRelatedBlog is a blog recommended after another. SharedTags are the
+// tags both blogs have.
+type SelectRelatedBlog struct {
+	ID              uint           `json:"id"`
+	CoverImage      *string        `json:"cover_image"`
+	Title           string         `json:"title"`
+	Slug            string         `json:"slug"`
+	PublisherId     string         `json:"publisher_id"`
+	PublisherAvatar *string        `json:"publisher_avatar"`
+	PublisherName   *string        `json:"publisher_name"`
+	PublishedAt     time.Time      `json:"published_at"`
+	Tags            pq.StringArray `json:"tags" gorm:"type:text[]"`
+	SharedTags      pq.StringArray `json:"shared_tags" gorm:"type:text[]"`
+}
@@ -1,6 +1,6 @@
 package schemas
 
-import "github.com/go-playground/validator/v10"
+import "github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 
 type SchemaCertification struct {
 	Title           string   `json:"title" binding:"required" validate:"required,min=4,max=100"`
@@ -11,7 +11,7 @@ type SchemaCertification struct {
 }
 
 func (s *SchemaCertification) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -21,6 +21,6 @@ type SchemaCertificationMetadata struct {
 }
 
 func (s *SchemaCertificationMetadata) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
@@ -3,8 +3,8 @@ package schemas
 import (
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofrs/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 	"github.com/lib/pq"
 	"gorm.io/datatypes"
 )
@@ -14,7 +14,7 @@ type SchemaComment struct {
 }
 
 func (s SchemaComment) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -25,7 +25,7 @@ type SchemaCreateComment struct {
 }
 
 func (s SchemaCreateComment) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -35,7 +35,7 @@ type SchemaCommentReply struct {
 }
 
 func (s SchemaCommentReply) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
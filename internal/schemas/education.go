@@ -1,7 +1,6 @@
 package schemas
 
 import (
-	"github.com/go-playground/validator/v10"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 )
 
@@ -18,10 +17,7 @@ type SchemaEducation struct {
 }
 
 func (s *SchemaEducation) Validate() error {
-	validate := validator.New()
-	validate.RegisterValidation("year_in_range", utilities.YearWithinValidRangeValidator)
-	validate.RegisterValidation("college_degree", utilities.CollegeDegreeValidator)
-
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -30,7 +26,7 @@ type SchemaReorderEducation struct {
 }
 
 func (s *SchemaReorderEducation) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -40,6 +36,6 @@ type SchemaEducationMetadata struct {
 }
 
 func (s *SchemaEducationMetadata) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
@@ -0,0 +1,115 @@
+package schemas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+const (
+	FeedKindBlog    = "blog"
+	FeedKindProject = "project"
+
+	FeedSourceFollowing = "following"
+	FeedSourceTrending  = "trending"
+)
+
+var errInvalidFeedCursor = errors.New("invalid feed cursor")
+
+// FeedCursor is the position of the last item of a feed page. Items are
+// ordered by rank, then kind and id, so pages don't shift when new items are
+// published.
+type FeedCursor struct {
+	Source string    `json:"s"`
+	RankAt time.Time `json:"r"`
+	Kind   string    `json:"k"`
+	ID     uint      `json:"i"`
+}
+
+func (c FeedCursor) Encode() string {
+	buf, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func ParseFeedCursor(s string) (*FeedCursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidFeedCursor
+	}
+
+	var cursor FeedCursor
+	if err := json.Unmarshal(buf, &cursor); err != nil {
+		return nil, errInvalidFeedCursor
+	}
+
+	if cursor.Source != FeedSourceFollowing && cursor.Source != FeedSourceTrending {
+		return nil, errInvalidFeedCursor
+	}
+	if cursor.Kind != FeedKindBlog && cursor.Kind != FeedKindProject {
+		return nil, errInvalidFeedCursor
+	}
+
+	return &cursor, nil
+}
+
+type FeedQuery struct {
+	Cursor *FeedCursor
+	Limit  int
+}
+
+// FetchLimit is the number of rows repositories should be asked for, one
+// more than the page to tell whether another page exists.
+func (q FeedQuery) FetchLimit() int {
+	return q.Limit + 1
+}
+
+// SelectFeedItem is a published blog or a work gallery project. Tags are the
+// tags of a blog or the technologies of a project.
+type SelectFeedItem struct {
+	Kind              string          `json:"kind"`
+	ID                uint            `json:"id"`
+	Title             string          `json:"title"`
+	Slug              *string         `json:"slug"`
+	CoverImage        *string         `json:"cover_image"`
+	Description       *string         `json:"description"`
+	Tags              pq.StringArray  `json:"tags" gorm:"type:text[]"`
+	ReactionsMetadata *datatypes.JSON `json:"reactions_metadata"`
+	CommentsCount     int64           `json:"comments_count"`
+	ViewsCount        int64           `json:"views_count"`
+	PublisherId       string          `json:"publisher_id"`
+	PublisherName     *string         `json:"publisher_name"`
+	PublisherAvatar   *string         `json:"publisher_avatar"`
+	PublisherSlug     *string         `json:"publisher_slug"`
+	PublishedAt       time.Time       `json:"published_at"`
+	RankAt            time.Time       `json:"-"`
+}
+
+type Feed struct {
+	Source     string           `json:"source"`
+	Items      []SelectFeedItem `json:"items"`
+	NextCursor *string          `json:"next_cursor"`
+	HasMore    bool             `json:"has_more"`
+}
+
+// NewFeed builds a feed page from rows fetched with query.FetchLimit().
+func NewFeed(source string, rows []SelectFeedItem, query FeedQuery) *Feed {
+	feed := &Feed{Source: source, Items: rows}
+	if feed.Items == nil {
+		feed.Items = []SelectFeedItem{}
+	}
+
+	if len(feed.Items) > query.Limit {
+		feed.Items = feed.Items[:query.Limit]
+		feed.HasMore = true
+
+		last := feed.Items[len(feed.Items)-1]
+		next := FeedCursor{Source: source, RankAt: last.RankAt, Kind: last.Kind, ID: last.ID}.Encode()
+		feed.NextCursor = &next
+	}
+
+	return feed
+}
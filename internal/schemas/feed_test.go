@@ -0,0 +1,98 @@
+package schemas
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestFeedCursorRoundTrip(t *testing.T) {
+	cursor := FeedCursor{
+		Source: FeedSourceTrending,
+		RankAt: time.Date(2026, 10, 19, 12, 30, 15, 123456000, time.UTC),
+		Kind:   FeedKindProject,
+		ID:     42,
+	}
+
+	got, err := ParseFeedCursor(cursor.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ranks keep their sub-second part, or items sharing a second would be
+	// skipped.
+	if got.Source != cursor.Source || !got.RankAt.Equal(cursor.RankAt) || got.Kind != cursor.Kind || got.ID != cursor.ID {
+		t.Errorf("ParseFeedCursor(Encode()) = %+v, want %+v", got, cursor)
+	}
+}
+
+func TestParseFeedCursorErrors(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "not json", cursor: encode("42")},
+		{name: "offset cursor", cursor: "10"},
+		{name: "unknown source", cursor: encode(`{"s":"everyone","r":"2026-10-19T12:00:00Z","k":"blog","i":1}`)},
+		{name: "unknown kind", cursor: encode(`{"s":"following","r":"2026-10-19T12:00:00Z","k":"hackathon","i":1}`)},
+		{name: "no source", cursor: encode(`{"r":"2026-10-19T12:00:00Z","k":"blog","i":1}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := ParseFeedCursor(tt.cursor); err == nil {
+				t.Errorf("ParseFeedCursor(%q) = %+v, want an error", tt.cursor, cursor)
+			}
+		})
+	}
+}
+
+func TestNewFeed(t *testing.T) {
+	rankAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	rows := []SelectFeedItem{
+		{Kind: FeedKindBlog, ID: 3, RankAt: rankAt.Add(2 * time.Hour)},
+		{Kind: FeedKindProject, ID: 7, RankAt: rankAt},
+		{Kind: FeedKindBlog, ID: 5, RankAt: rankAt},
+	}
+
+	t.Run("more items", func(t *testing.T) {
+		feed := NewFeed(FeedSourceFollowing, rows, FeedQuery{Limit: 2})
+
+		if len(feed.Items) != 2 || !feed.HasMore || feed.NextCursor == nil {
+			t.Fatalf("NewFeed() = %+v, want 2 items and a next cursor", feed)
+		}
+
+		// The cursor points at the last item shown, not the extra row.
+		next, err := ParseFeedCursor(*feed.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := FeedCursor{Source: FeedSourceFollowing, RankAt: rankAt, Kind: FeedKindProject, ID: 7}
+		if next.Source != want.Source || !next.RankAt.Equal(want.RankAt) || next.Kind != want.Kind || next.ID != want.ID {
+			t.Errorf("next cursor = %+v, want %+v", *next, want)
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		feed := NewFeed(FeedSourceTrending, rows, FeedQuery{Limit: 3})
+
+		if len(feed.Items) != 3 || feed.HasMore || feed.NextCursor != nil {
+			t.Errorf("NewFeed() = %+v, want all items and no next cursor", feed)
+		}
+		if feed.Source != FeedSourceTrending {
+			t.Errorf("source = %q, want %q", feed.Source, FeedSourceTrending)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		feed := NewFeed(FeedSourceFollowing, nil, FeedQuery{Limit: 3})
+
+		if feed.Items == nil || len(feed.Items) != 0 || feed.HasMore {
+			t.Errorf("NewFeed() = %+v, want an empty list", feed)
+		}
+	})
+}
@@ -1,6 +1,6 @@
 package schemas
 
-import "github.com/go-playground/validator/v10"
+import "github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 
 type SchemaHackathonLink struct {
 	Platform string `json:"platform" validate:"required,oneof=Github Website Social"`
@@ -20,7 +20,7 @@ type SchemaHackathon struct {
 }
 
 func (s *SchemaHackathon) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -30,6 +30,6 @@ type SchemaHackathonMetadata struct {
 }
 
 func (s *SchemaHackathonMetadata) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
@@ -0,0 +1,76 @@
+package schemas
+
+import "time"
+
+// PageAPIVersion is the API version that introduced Page. Clients pinned to
+// an older version get the Legacy envelope instead.
+var PageAPIVersion = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// PageQuery holds the offset based paging parameters shared by every list
+// endpoint.
+type PageQuery struct {
+	Cursor       int
+	Limit        int
+	IncludeTotal bool
+}
+
+// FetchLimit is the number of rows repositories should be asked for. The
+// extra row only tells whether another page exists and is never returned.
+func (q PageQuery) FetchLimit() int {
+	return q.Limit + 1
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor *int   `json:"next_cursor"`
+	PrevCursor *int   `json:"prev_cursor"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// Paginated is implemented by every Page so handlers can add Link headers
+// without knowing the item type.
+type Paginated interface {
+	Cursors() (next *int, prev *int)
+}
+
+// NewPage builds a page from rows fetched with query.FetchLimit().
+func NewPage[T any](rows []T, query PageQuery, total *int64) *Page[T] {
+	page := &Page[T]{Items: rows, Total: total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(page.Items) > query.Limit {
+		page.Items = page.Items[:query.Limit]
+		page.HasMore = true
+
+		next := query.Cursor + query.Limit
+		page.NextCursor = &next
+	}
+
+	if query.Cursor > 0 {
+		prev := max(query.Cursor-query.Limit, 0)
+		page.PrevCursor = &prev
+	}
+
+	return page
+}
+
+func (p *Page[T]) Cursors() (*int, *int) {
+	return p.NextCursor, p.PrevCursor
+}
+
+// Legacy returns the {"list", "cursor"} envelope served to clients pinned to
+// an API version older than PageAPIVersion.
+func (p *Page[T]) Legacy() any {
+	return map[string]any{"list": p.Items, "cursor": p.NextCursor}
+}
+
+// ForAPIVersion returns the body served to clients pinned to version.
+func (p *Page[T]) ForAPIVersion(version time.Time) any {
+	if version.Before(PageAPIVersion) {
+		return p.Legacy()
+	}
+	return p
+}
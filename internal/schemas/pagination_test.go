@@ -0,0 +1,212 @@
+package schemas
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []int
+		query   PageQuery
+		items   []int
+		next    *int
+		prev    *int
+		hasMore bool
+	}{
+		{
+			name:  "first and only page",
+			rows:  []int{1, 2},
+			query: PageQuery{Limit: 3},
+			items: []int{1, 2},
+		},
+		{
+			name:    "first page with more",
+			rows:    []int{1, 2, 3, 4},
+			query:   PageQuery{Limit: 3},
+			items:   []int{1, 2, 3},
+			next:    intPtr(3),
+			hasMore: true,
+		},
+		{
+			name:    "middle page",
+			rows:    []int{4, 5, 6, 7},
+			query:   PageQuery{Cursor: 3, Limit: 3},
+			items:   []int{4, 5, 6},
+			next:    intPtr(6),
+			prev:    intPtr(0),
+			hasMore: true,
+		},
+		{
+			name:  "last page",
+			rows:  []int{10},
+			query: PageQuery{Cursor: 9, Limit: 3},
+			items: []int{10},
+			prev:  intPtr(6),
+		},
+		{
+			name:  "cursor not aligned on the limit",
+			rows:  []int{2},
+			query: PageQuery{Cursor: 1, Limit: 3},
+			items: []int{2},
+			prev:  intPtr(0),
+		},
+		{
+			name:  "no rows",
+			query: PageQuery{Limit: 3},
+			items: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage(tt.rows, tt.query, nil)
+
+			if len(page.Items) != len(tt.items) {
+				t.Fatalf("Items = %v, want %v", page.Items, tt.items)
+			}
+			for i := range tt.items {
+				if page.Items[i] != tt.items[i] {
+					t.Fatalf("Items = %v, want %v", page.Items, tt.items)
+				}
+			}
+			if page.HasMore != tt.hasMore {
+				t.Errorf("HasMore = %v, want %v", page.HasMore, tt.hasMore)
+			}
+
+			next, prev := page.Cursors()
+			if !equalCursor(next, tt.next) {
+				t.Errorf("NextCursor = %v, want %v", deref(next), deref(tt.next))
+			}
+			if !equalCursor(prev, tt.prev) {
+				t.Errorf("PrevCursor = %v, want %v", deref(prev), deref(tt.prev))
+			}
+		})
+	}
+}
+
+func TestPageEnvelopes(t *testing.T) {
+	total := int64(7)
+
+	tests := []struct {
+		name string
+		page Paginated
+		want string
+	}{
+		{
+			name: "page",
+			page: NewPage([]string{"a", "b", "c"}, PageQuery{Cursor: 2, Limit: 2, IncludeTotal: true}, &total),
+			want: `{"items":["a","b"],"next_cursor":4,"prev_cursor":0,"has_more":true,"total":7}`,
+		},
+		{
+			name: "empty page",
+			page: NewPage[string](nil, PageQuery{Limit: 2}, nil),
+			want: `{"items":[],"next_cursor":null,"prev_cursor":null,"has_more":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("page = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageForAPIVersion(t *testing.T) {
+	before := PageAPIVersion.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		page    interface{ ForAPIVersion(time.Time) any }
+		version time.Time
+		want    string
+	}{
+		{
+			name:    "legacy envelope with more pages",
+			page:    NewPage([]string{"a", "b", "c"}, PageQuery{Cursor: 2, Limit: 2}, nil),
+			version: before,
+			want:    `{"cursor":4,"list":["a","b"]}`,
+		},
+		{
+			name:    "legacy envelope on the last page",
+			page:    NewPage([]string{"a"}, PageQuery{Cursor: 2, Limit: 2}, nil),
+			version: time.Time{},
+			want:    `{"cursor":null,"list":["a"]}`,
+		},
+		{
+			name:    "legacy envelope of an empty page",
+			page:    NewPage[string](nil, PageQuery{Limit: 2}, nil),
+			version: before,
+			want:    `{"cursor":null,"list":[]}`,
+		},
+		{
+			name:    "page",
+			page:    NewPage([]string{"a"}, PageQuery{Limit: 2}, nil),
+			version: PageAPIVersion,
+			want:    `{"items":["a"],"next_cursor":null,"prev_cursor":null,"has_more":false}`,
+		},
+		{
+			name:    "portfolio page keeps the legacy envelope",
+			page:    NewPortfolioPage(nil, nil, PageQuery{Limit: 2}, nil),
+			version: before,
+			want:    `{"cursor":null,"list":[]}`,
+		},
+		{
+			name:    "search results are never legacy",
+			page:    NewSearchResults(nil, nil, SearchQuery{Type: SearchTypeBlogs, PageQuery: PageQuery{Limit: 2}}),
+			version: before,
+			want:    `{"items":[],"next_cursor":null,"prev_cursor":null,"has_more":false,"facets":{"blogs":0,"portfolios":0,"projects":0,"skills":0}}`,
+		},
+		{
+			name:    "tag pages are never legacy",
+			page:    &TagPage{Tag: &SelectTag{Name: "go"}, Page: NewPage[SelectBlog](nil, PageQuery{Limit: 2}, nil)},
+			version: before,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.page.ForAPIVersion(tt.version)
+			if tt.want == "" {
+				if body != tt.page {
+					t.Errorf("ForAPIVersion() = %#v, want the page itself", body)
+				}
+				return
+			}
+
+			data, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("ForAPIVersion() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func equalCursor(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(i *int) any {
+	if i == nil {
+		return nil
+	}
+	return *i
+}
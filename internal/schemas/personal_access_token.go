@@ -0,0 +1,26 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+type SchemaPersonalAccessToken struct {
+	Name      string     `json:"name" binding:"required" validate:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required" validate:"required,min=1,unique,dive,oneof=profile:read profile:write portfolio:read portfolio:write work-gallery:read work-gallery:write blogs:read blogs:write comments:read comments:write"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,gt"`
+}
+
+func (s *SchemaPersonalAccessToken) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+// SelectPersonalAccessTokenCreated is the only response the token itself is
+// ever returned in.
+type SelectPersonalAccessTokenCreated struct {
+	models.PersonalAccessToken
+	Token string `json:"token"`
+}
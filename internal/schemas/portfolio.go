@@ -1,6 +1,10 @@
 package schemas
 
-import "gorm.io/datatypes"
+import (
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
 
 type SelectGetPortfolio struct {
 	ID                string         `json:"id"`
@@ -22,3 +26,117 @@ type SelectPortfoliosItem struct {
 	WorkDomains    *datatypes.JSON `json:"work_domains"`
 	SocialProfiles *datatypes.JSON `json:"social_profiles"`
 }
+
+// SelectSimilarPortfolio is a portfolio recommended after another, with the
+// skills, work domains and project technologies they have in common.
+type SelectSimilarPortfolio struct {
+	ID                string         `json:"id"`
+	Name              *string        `json:"name"`
+	Avatar            *string        `json:"avatar"`
+	Slug              string         `json:"slug"`
+	Tagline           *string        `json:"tagline"`
+	SharedSkills      pq.StringArray `json:"shared_skills" gorm:"type:text[]"`
+	SharedWorkDomains pq.StringArray `json:"shared_work_domains" gorm:"type:text[]"`
+	SharedTech        pq.StringArray `json:"shared_tech" gorm:"type:text[]"`
+}
+
+// PortfolioFields are the sparse fieldset names accepted by ?fields on
+// GET /portfolio/:slug, in response order.
+var PortfolioFields = []string{"id", "status", "basic_details", "skills", "additional_details"}
+
+type PortfolioInclude struct {
+	Module string `json:"module" validate:"required,oneof=educations work_experiences certifications hackathons works skills blogs"`
+	Limit  int    `json:"limit" validate:"min=0,max=50"`
+}
+
+// SchemaPortfolioQuery is parsed from ?fields=basic_details,skills and
+// ?include=educations,blogs:5. An included module is embedded under its own
+// name, so including skills replaces the skill names with the resolved skills.
+type SchemaPortfolioQuery struct {
+	Fields  []string           `json:"fields" validate:"unique,dive,oneof=id status basic_details skills additional_details"`
+	Include []PortfolioInclude `json:"include" validate:"unique=Module,dive"`
+}
+
+func (s *SchemaPortfolioQuery) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+func (s *SchemaPortfolioQuery) IsEmpty() bool {
+	return len(s.Fields) == 0 && len(s.Include) == 0
+}
+
+const (
+	PortfolioFacetSkills            = "skills"
+	PortfolioFacetWorkDomains       = "work_domains"
+	PortfolioFacetColleges          = "colleges"
+	PortfolioFacetGraduationYears   = "graduation_years"
+	PortfolioFacetHasHackathons     = "has_hackathons"
+	PortfolioFacetHasCertifications = "has_certifications"
+	PortfolioFacetJobTypes          = "job_types"
+)
+
+var PortfolioFacets = []string{
+	PortfolioFacetSkills,
+	PortfolioFacetWorkDomains,
+	PortfolioFacetColleges,
+	PortfolioFacetGraduationYears,
+	PortfolioFacetHasHackathons,
+	PortfolioFacetHasCertifications,
+	PortfolioFacetJobTypes,
+}
+
+// SchemaPortfolioFilters narrows GET /portfolio. Values within a list match
+// any of them, except skills with SkillsMatch set to all. Text values are
+// compared ignoring case.
+type SchemaPortfolioFilters struct {
+	Query             *string  `json:"query"`
+	Skills            []string `json:"skills" validate:"max=20,dive,required,max=100"`
+	SkillsMatch       string   `json:"skills_match" validate:"oneof=any all"`
+	WorkDomains       []string `json:"work_domains" validate:"max=20,dive,required,max=100"`
+	Colleges          []string `json:"colleges" validate:"max=20,dive,required,max=100"`
+	GraduationYearMin *int     `json:"graduation_year_min" validate:"omitempty,min=1900,max=2100"`
+	GraduationYearMax *int     `json:"graduation_year_max" validate:"omitempty,min=1900,max=2100"`
+	HasHackathons     *bool    `json:"has_hackathons"`
+	HasCertifications *bool    `json:"has_certifications"`
+	JobTypes          []string `json:"job_types" validate:"unique,dive,oneof=PART_TIME SEMI_FULL_TIME FULL_TIME"`
+}
+
+func (s *SchemaPortfolioFilters) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SelectPortfolioFacet struct {
+	Facet string
+	Value string
+	Count int64
+}
+
+type PortfolioFacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// PortfolioPage is a page of portfolios with, for each filter, the number of
+// portfolios having each value among those matching the other filters.
+type PortfolioPage struct {
+	*Page[SelectPortfoliosItem]
+	Facets map[string][]PortfolioFacetValue `json:"facets"`
+}
+
+func NewPortfolioPage(rows []SelectPortfoliosItem, facets []SelectPortfolioFacet, query PageQuery, total *int64) *PortfolioPage {
+	page := &PortfolioPage{
+		Page:   NewPage(rows, query, total),
+		Facets: make(map[string][]PortfolioFacetValue, len(PortfolioFacets)),
+	}
+
+	for _, facet := range PortfolioFacets {
+		page.Facets[facet] = []PortfolioFacetValue{}
+	}
+	for _, facet := range facets {
+		page.Facets[facet.Facet] = append(page.Facets[facet.Facet], PortfolioFacetValue{Value: facet.Value, Count: facet.Count})
+	}
+
+	return page
+}
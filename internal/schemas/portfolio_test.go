@@ -0,0 +1,31 @@
+package schemas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewPortfolioPage(t *testing.T) {
+	facets := []SelectPortfolioFacet{
+		{Facet: PortfolioFacetSkills, Value: "go", Count: 4},
+		{Facet: PortfolioFacetSkills, Value: "react", Count: 2},
+		{Facet: PortfolioFacetHasHackathons, Value: "true", Count: 3},
+	}
+
+	page := NewPortfolioPage(nil, facets, PageQuery{Limit: 10}, nil)
+
+	// Every facet is listed, so clients need not tell an unknown facet from
+	// one without values.
+	want := map[string][]PortfolioFacetValue{
+		PortfolioFacetSkills:            {{Value: "go", Count: 4}, {Value: "react", Count: 2}},
+		PortfolioFacetWorkDomains:       {},
+		PortfolioFacetColleges:          {},
+		PortfolioFacetGraduationYears:   {},
+		PortfolioFacetHasHackathons:     {{Value: "true", Count: 3}},
+		PortfolioFacetHasCertifications: {},
+		PortfolioFacetJobTypes:          {},
+	}
+	if !reflect.DeepEqual(page.Facets, want) {
+		t.Errorf("facets = %+v, want %+v", page.Facets, want)
+	}
+}
@@ -0,0 +1,13 @@
+package schemas
+
+// Orders of the blog and portfolio lists. SortLatest, the default, orders by
+// last update; the others by scores recomputed periodically.
+const (
+	SortLatest   = "latest"
+	SortTrending = "trending"
+	SortTopWeek  = "top_week"
+	SortTopMonth = "top_month"
+	SortTopAll   = "top_all"
+)
+
+var Sorts = []string{SortLatest, SortTrending, SortTopWeek, SortTopMonth, SortTopAll}
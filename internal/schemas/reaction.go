@@ -1,6 +1,6 @@
 package schemas
 
-import "github.com/go-playground/validator/v10"
+import "github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 
 type SchemaReaction struct {
 	Action   string `json:"action" binding:"required" validate:"required,oneof=add remove"`
@@ -8,6 +8,6 @@ type SchemaReaction struct {
 }
 
 func (s SchemaReaction) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
@@ -0,0 +1,30 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+// SchemaBanUser bans a user until BannedUntil, or for good when it's unset.
+type SchemaBanUser struct {
+	Reason      string     `json:"reason" validate:"omitempty,max=500"`
+	BannedUntil *time.Time `json:"banned_until" validate:"omitempty,gt"`
+}
+
+func (s *SchemaBanUser) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+// SchemaRevokeSessions revokes one session or token of a user, or all of
+// them when both fields are empty.
+type SchemaRevokeSessions struct {
+	SessionId string `json:"session_id" validate:"omitempty,uuid"`
+	JTI       string `json:"jti" validate:"omitempty,max=255"`
+}
+
+func (s *SchemaRevokeSessions) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
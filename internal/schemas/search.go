@@ -0,0 +1,80 @@
+package schemas
+
+import "time"
+
+const (
+	SearchTypeAll        = "all"
+	SearchTypePortfolios = "portfolios"
+	SearchTypeBlogs      = "blogs"
+	SearchTypeProjects   = "projects"
+	SearchTypeSkills     = "skills"
+)
+
+var SearchTypes = []string{SearchTypeAll, SearchTypePortfolios, SearchTypeBlogs, SearchTypeProjects, SearchTypeSkills}
+
+type SearchQuery struct {
+	Q    string
+	Type string
+	PageQuery
+}
+
+// SelectSearchResult is a portfolio, blog, project or skill matching a
+// search. Snippet holds the matched text with the terms wrapped in
+// <mark></mark>.
+type SelectSearchResult struct {
+	Type      string  `json:"type"`
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Slug      *string `json:"slug"`
+	Snippet   *string `json:"snippet"`
+	Image     *string `json:"image"`
+	OwnerName *string `json:"owner_name"`
+	OwnerSlug *string `json:"owner_slug"`
+	Rank      float64 `json:"rank"`
+}
+
+type SelectSearchFacet struct {
+	Type  string
+	Count int64
+}
+
+// SearchResults is a page of results along with the number of matches of
+// every type, whatever type was asked for.
+type SearchResults struct {
+	*Page[SelectSearchResult]
+	Facets map[string]int64 `json:"facets"`
+}
+
+// ForAPIVersion serves search results as they are to every version, as
+// search was added after the legacy envelope was replaced.
+func (r *SearchResults) ForAPIVersion(time.Time) any {
+	return r
+}
+
+func NewSearchResults(rows []SelectSearchResult, facets []SelectSearchFacet, query SearchQuery) *SearchResults {
+	counts := map[string]int64{
+		SearchTypePortfolios: 0,
+		SearchTypeBlogs:      0,
+		SearchTypeProjects:   0,
+		SearchTypeSkills:     0,
+	}
+	var all int64
+	for _, facet := range facets {
+		counts[facet.Type] = facet.Count
+		all += facet.Count
+	}
+
+	var total *int64
+	if query.IncludeTotal {
+		count := all
+		if query.Type != SearchTypeAll {
+			count = counts[query.Type]
+		}
+		total = &count
+	}
+
+	return &SearchResults{
+		Page:   NewPage(rows, query.PageQuery, total),
+		Facets: counts,
+	}
+}
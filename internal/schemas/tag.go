@@ -0,0 +1,69 @@
+package schemas
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+	"github.com/lib/pq"
+)
+
+// SelectTag is a tag with the number of published blogs carrying it and of
+// users following it.
+type SelectTag struct {
+	ID             uint           `json:"id"`
+	Name           string         `json:"name"`
+	Description    *string        `json:"description"`
+	Aliases        pq.StringArray `json:"aliases" gorm:"type:text[]"`
+	UsageCount     int64          `json:"usage_count"`
+	FollowersCount int64          `json:"followers_count"`
+	IsFollowing    *bool          `json:"is_following"`
+	DeprecatedAt   *time.Time     `json:"deprecated_at"`
+}
+
+// TagPage is a page of the published blogs carrying a tag.
+type TagPage struct {
+	Tag *SelectTag `json:"tag"`
+	*Page[SelectBlog]
+}
+
+// ForAPIVersion serves tag pages as they are to every version, as they were
+// added after the legacy envelope was replaced.
+func (p *TagPage) ForAPIVersion(time.Time) any {
+	return p
+}
+
+type SchemaUpdateTag struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+	Deprecated  *bool   `json:"deprecated"`
+}
+
+func (s *SchemaUpdateTag) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaMergeTag struct {
+	Into string `json:"into" validate:"required,min=1,max=100"`
+}
+
+func (s *SchemaMergeTag) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaTagAlias struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+func (s *SchemaTagAlias) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+// NormalizeTag is the canonical spelling of a tag name: lowercase, with
+// single spaces between words.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,22 @@
+package schemas
+
+const (
+	TypeaheadSkills     = "skills"
+	TypeaheadTags       = "tags"
+	TypeaheadInstitutes = "institutes"
+	TypeaheadCompanies  = "companies"
+	TypeaheadUsers      = "users"
+)
+
+var TypeaheadKinds = []string{TypeaheadSkills, TypeaheadTags, TypeaheadInstitutes, TypeaheadCompanies, TypeaheadUsers}
+
+// SelectSuggestion is a typeahead completion. Value is what to fill in, the
+// slug for users, and Label what to show. Count is the popularity it is
+// ranked by: the users listing the skill, institute or company, the published
+// blogs carrying the tag or the followers of the user.
+type SelectSuggestion struct {
+	Value string  `json:"value"`
+	Label string  `json:"label"`
+	Image *string `json:"image"`
+	Count int64   `json:"count"`
+}
@@ -1,7 +1,6 @@
 package schemas
 
 import (
-	"github.com/go-playground/validator/v10"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 )
 
@@ -22,30 +21,8 @@ type SchemaProfileBasic struct {
 }
 
 func (s *SchemaProfileBasic) Validate() error {
-	validate := validator.New()
-	validate.RegisterValidation("year_in_range", utilities.YearWithinValidRangeValidator)
-	validate.RegisterValidation("work_domain", utilities.WorkDomainsValidator)
-	validate.RegisterValidation("social_platform", utilities.SocialPlatformValidator)
-
+	validate := utilities.Validator()
 	return validate.Struct(s)
-
-	// err := validate.Struct(s)
-
-	// structKeysToLabel := map[string]string{
-	// 	"FullName":           "Name",
-	// 	"ProfilePicture":     "Profile Picture",
-	// 	"Collage":            "Collage",
-	// 	"GraduationYear":     "Graduation Year",
-	// 	"WorkDomains":        "Work Domain",
-	// 	"SocialProfileLinks": "Social Profile Link",
-	// }
-
-	// errorsMap := make(map[string]string)
-
-	// errs := err.(validator.ValidationErrors)
-	// for _, fieldErr := range errs {
-	// 	errorsMap[fieldErr.Field()] = err
-	// }
 }
 
 type File struct {
@@ -59,7 +36,7 @@ type SchemaPresignedURL struct {
 }
 
 func (s *SchemaPresignedURL) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -68,7 +45,7 @@ type SchemaReorderItem struct {
 }
 
 func (s *SchemaReorderItem) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -77,7 +54,7 @@ type SchemaSkills struct {
 }
 
 func (s *SchemaSkills) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -86,7 +63,16 @@ type SchemaResume struct {
 }
 
 func (s *SchemaResume) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+type SchemaProfilePrivacy struct {
+	SharePortfolioViews *bool `json:"share_portfolio_views" validate:"required"`
+}
+
+func (s *SchemaProfilePrivacy) Validate() error {
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -96,7 +82,7 @@ type SchemaProfileAttachment struct {
 }
 
 func (s *SchemaProfileAttachment) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -0,0 +1,56 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+)
+
+// SchemaWebhookEndpoint creates or replaces an endpoint. A secret is
+// generated when none is given; on update an empty secret keeps the old one.
+type SchemaWebhookEndpoint struct {
+	URL         string   `json:"url" binding:"required" validate:"required,http_url,max=2048"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16,max=256"`
+	EventTypes  []string `json:"event_types" binding:"required" validate:"required,min=1,unique,dive,oneof=blog.published blog.unpublished comment.created follow.created portfolio.status_changed portfolio.module_updated"`
+	Active      *bool    `json:"active"`
+	Description *string  `json:"description" validate:"omitempty,max=500"`
+}
+
+func (s *SchemaWebhookEndpoint) Validate() error {
+	validate := utilities.Validator()
+	return validate.Struct(s)
+}
+
+// SelectWebhookEndpointWithSecret is returned when the secret was just set,
+// the only time it is sent back.
+type SelectWebhookEndpointWithSecret struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// SelectPendingWebhookDelivery is a claimed delivery with what's needed to
+// send it.
+type SelectPendingWebhookDelivery struct {
+	models.WebhookDelivery
+	URL    string
+	Secret string
+	Active bool
+}
+
+// WebhookEvent is the body of every delivery. ID is the same for every
+// endpoint and attempt, so receivers can drop duplicates.
+type WebhookEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// JobWebhookEvent is the payload of the job fanning an event out to the
+// endpoints of UserId.
+type JobWebhookEvent struct {
+	UserId uuid.UUID    `json:"user_id"`
+	Event  WebhookEvent `json:"event"`
+}
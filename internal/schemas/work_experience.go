@@ -1,6 +1,6 @@
 package schemas
 
-import "github.com/go-playground/validator/v10"
+import "github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 
 type SchemaWorkExperience struct {
 	CompanyName     string   `json:"company_name" binding:"required" validate:"required,min=3,max=150"`
@@ -16,7 +16,7 @@ type SchemaWorkExperience struct {
 }
 
 func (s *SchemaWorkExperience) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -26,6 +26,6 @@ type SchemaWorkExperienceMetadata struct {
 }
 
 func (s *SchemaWorkExperienceMetadata) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
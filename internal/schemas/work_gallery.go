@@ -3,7 +3,7 @@ package schemas
 import (
 	"time"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
 	"github.com/lib/pq"
 	"gorm.io/datatypes"
 )
@@ -23,7 +23,7 @@ type SchemaTechProject struct {
 }
 
 func (s SchemaTechProject) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -33,7 +33,7 @@ type SchemaTechProjectMetadata struct {
 }
 
 func (s *SchemaTechProjectMetadata) Validate() error {
-	validate := validator.New()
+	validate := utilities.Validator()
 	return validate.Struct(s)
 }
 
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxReferrers bounds the referrers listed in analytics.
+	maxReferrers = 20
+	// maxBreakdown bounds the values listed per portfolio breakdown.
+	maxBreakdown = 10
+	// maxViewers bounds the signed in visitors listed in portfolio analytics.
+	maxViewers = 20
+)
+
+var ErrBlogNotFound = errors.New("blog not found")
+
+type ServiceAnalytics interface {
+	GetBlogAnalytics(ctx context.Context, userId string, blogId string, days int) (*schemas.BlogAnalytics, error)
+	GetPortfolioAnalytics(ctx context.Context, userId string, days int) (*schemas.PortfolioAnalytics, error)
+}
+
+type serviceAnalytics struct {
+	db *gorm.DB
+}
+
+// GetBlogAnalytics reports on the last days days, today included, of a blog
+// of userId.
+func (s *serviceAnalytics) GetBlogAnalytics(ctx context.Context, userId string, slug string, days int) (*schemas.BlogAnalytics, error) {
+	id, err := repositories.NewBlogRepository(s.db.WithContext(ctx)).GetUserBlogIdBySlug(userId, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBlogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := analyticsPeriod(days)
+
+	repository := repositories.NewAnalyticsRepository(s.db.WithContext(ctx))
+
+	daily, err := repository.GetBlogDailyStats(id, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	readers, err := repository.CountBlogReaders(id, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	referrers, err := repository.GetBlogReferrers(id, from, to, maxReferrers)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &schemas.BlogAnalytics{
+		BlogId:        id,
+		From:          from.Format(time.DateOnly),
+		To:            to.Format(time.DateOnly),
+		UniqueReaders: readers,
+		Daily:         daily,
+		Referrers:     referrers,
+	}
+	for _, day := range daily {
+		analytics.Views += day.Views
+	}
+
+	return analytics, nil
+}
+
+// GetPortfolioAnalytics reports on the visits of the portfolio of userId over
+// the last days days, today included.
+func (s *serviceAnalytics) GetPortfolioAnalytics(ctx context.Context, userId string, days int) (*schemas.PortfolioAnalytics, error) {
+	from, to := analyticsPeriod(days)
+
+	repository := repositories.NewAnalyticsRepository(s.db.WithContext(ctx))
+
+	daily, err := repository.GetPortfolioDailyStats(userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	visitors, err := repository.CountPortfolioVisitors(userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := repository.GetPortfolioBreakdown(userId, "module", from, to, maxBreakdown)
+	if err != nil {
+		return nil, err
+	}
+
+	referrers, err := repository.GetPortfolioBreakdown(userId, "referrer_host", from, to, maxBreakdown)
+	if err != nil {
+		return nil, err
+	}
+
+	countries, err := repository.GetPortfolioBreakdown(userId, "country", from, to, maxBreakdown)
+	if err != nil {
+		return nil, err
+	}
+
+	viewers, err := repository.GetPortfolioViewers(userId, from, to, maxViewers)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &schemas.PortfolioAnalytics{
+		From:      from.Format(time.DateOnly),
+		To:        to.Format(time.DateOnly),
+		Visitors:  visitors,
+		Daily:     daily,
+		Modules:   modules,
+		Referrers: referrers,
+		Countries: countries,
+		Viewers:   viewers,
+	}
+	for _, day := range daily {
+		analytics.Views += day.Views
+	}
+
+	return analytics, nil
+}
+
+// analyticsPeriod returns the first and last UTC day of the last days days.
+func analyticsPeriod(days int) (time.Time, time.Time) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	return to.AddDate(0, 0, 1-days), to
+}
+
+func NewAnalyticsService(db *gorm.DB) *serviceAnalytics {
+	return &serviceAnalytics{
+		db: db,
+	}
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/pkg"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid login credentials")
+	ErrEmailNotConfirmed   = errors.New("email not confirmed")
+	ErrEmailExists         = errors.New("a user with this email address has already been registered")
+	ErrInvalidToken        = errors.New("token has expired or is invalid")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+)
+
+// dummyPasswordHash is checked when there is no password to check, so a
+// login takes as long whether or not the account exists.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// SessionInfo describes the client a session is created for.
+type SessionInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type ServiceAuth interface {
+	Signup(ctx context.Context, data *schemas.SchemaSignup, info SessionInfo) (*schemas.Session, *models.AuthUser, error)
+	Verify(ctx context.Context, data *schemas.SchemaVerify, info SessionInfo) (*schemas.Session, error)
+	Login(ctx context.Context, data *schemas.SchemaLogin, info SessionInfo) (*schemas.Session, error)
+	Refresh(ctx context.Context, refreshToken string) (*schemas.Session, error)
+	Logout(ctx context.Context, userId string, sessionId string) error
+	Recover(ctx context.Context, email string) error
+	UpdatePassword(ctx context.Context, userId string, sessionId string, password string) error
+}
+
+type serviceAuth struct {
+	db          *gorm.DB
+	config      *config.GlobalConfiguration
+	mailer      *pkg.Mailer
+	revocations ServiceRevocation
+}
+
+// Signup creates an unconfirmed user and mails a confirmation link, unless
+// Auth.AutoConfirm is set in which case the user is signed in right away.
+func (s *serviceAuth) Signup(ctx context.Context, data *schemas.SchemaSignup, info SessionInfo) (*schemas.Session, *models.AuthUser, error) {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	// Hashed first, so a signup takes as long whether or not the address is
+	// taken.
+	hash, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if existing, err := repository.GetUserByEmail(data.Email); err == nil {
+		return s.signupExisting(ctx, existing)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	user, err := repository.CreateUser(data.Email, string(hash), s.config.Auth.AutoConfirm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.config.Auth.AutoConfirm {
+		session, err := s.issueSession(ctx, user, info)
+		return session, user, err
+	}
+
+	if err := s.sendOneTimeToken(ctx, user, models.AuthTokenSignup); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, user, nil
+}
+
+// signupExisting answers a signup with a taken address like a new signup,
+// so it can't be used to find out who has an account, and mails the owner
+// instead: the confirmation link again if they never confirmed, otherwise a
+// notice. A session can't be faked, so with Auth.AutoConfirm the address is
+// reported as taken.
+func (s *serviceAuth) signupExisting(ctx context.Context, user *models.AuthUser) (*schemas.Session, *models.AuthUser, error) {
+	if s.config.Auth.AutoConfirm {
+		return nil, nil, ErrEmailExists
+	}
+
+	var err error
+	if user.EmailConfirmedAt == nil {
+		err = s.sendOneTimeToken(ctx, user, models.AuthTokenSignup)
+	} else {
+		err = s.mailer.Send(user.Email, "You already have an account", pkg.TemplateAccountExists, pkg.TemplateData{
+			SiteURL: s.config.SiteURL,
+			Email:   user.Email,
+		})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// What CreateUser returns for a new address.
+	now := time.Now()
+	return nil, &models.AuthUser{
+		ID:              uuid.New(),
+		Aud:             "authenticated",
+		Role:            "authenticated",
+		Email:           strings.ToLower(user.Email),
+		RawAppMetaData:  []byte(`{"provider": "email", "providers": ["email"]}`),
+		RawUserMetaData: []byte(`{}`),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}, nil
+}
+
+// Verify consumes a token from a confirmation or recovery email and signs
+// the user in.
+func (s *serviceAuth) Verify(ctx context.Context, data *schemas.SchemaVerify, info SessionInfo) (*schemas.Session, error) {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	userId, err := repository.ConsumeOneTimeToken(models.AuthOneTimeTokenType(data.Type), hashToken(data.TokenHash))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Following a recovery link proves ownership of the address too.
+	if err := repository.ConfirmEmail(userId); err != nil {
+		return nil, err
+	}
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.revocations.CheckBanned(ctx, user.ID.String()); err != nil {
+		return nil, err
+	}
+
+	return s.issueSession(ctx, user, info)
+}
+
+func (s *serviceAuth) Login(ctx context.Context, data *schemas.SchemaLogin, info SessionInfo) (*schemas.Session, error) {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	user, err := repository.GetUserByEmail(data.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// A password is compared even without a user, so the response time
+	// doesn't tell whether the account exists.
+	hash := dummyPasswordHash()
+	if user != nil && user.EncryptedPassword != "" {
+		hash = []byte(user.EncryptedPassword)
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(data.Password)) != nil || user == nil || user.EncryptedPassword == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.EmailConfirmedAt == nil {
+		return nil, ErrEmailNotConfirmed
+	}
+
+	if err := s.revocations.CheckBanned(ctx, user.ID.String()); err != nil {
+		return nil, err
+	}
+
+	return s.issueSession(ctx, user, info)
+}
+
+// Refresh rotates the refresh token. Presenting an already rotated token
+// revokes the whole session, since it means the token has leaked.
+func (s *serviceAuth) Refresh(ctx context.Context, refreshToken string) (*schemas.Session, error) {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	token, err := repository.GetRefreshToken(hashToken(refreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	revoked, err := repository.RevokeRefreshToken(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Revoked || revoked == 0 {
+		logrus.WithField("session_id", token.SessionId).Warn("refresh token reused, revoking session")
+		if err := repository.DeleteSession(token.SessionId); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := repository.GetSession(token.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := repository.GetUserById(session.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.revocations.CheckBanned(ctx, user.ID.String()); err != nil {
+		return nil, err
+	}
+
+	return s.newSession(ctx, user, session)
+}
+
+// Logout ends the session and revokes the access tokens issued for it.
+func (s *serviceAuth) Logout(ctx context.Context, userId string, sessionId string) error {
+	if _, err := uuid.Parse(sessionId); err != nil {
+		return errors.New("failed to parse session id")
+	}
+
+	return s.revocations.RevokeSession(ctx, userId, sessionId)
+}
+
+// Recover mails a password reset link. It succeeds for unknown addresses as
+// well so it can't be used to find out who has an account.
+func (s *serviceAuth) Recover(ctx context.Context, email string) error {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	user, err := repository.GetUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return s.sendOneTimeToken(ctx, user, models.AuthTokenRecovery)
+}
+
+// UpdatePassword changes the password and signs out every other session.
+func (s *serviceAuth) UpdatePassword(ctx context.Context, userId string, sessionId string, password string) error {
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return errors.New("failed to parse user id")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	var revoked []uuid.UUID
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewAuthRepository(tx)
+
+		if err := repository.UpdatePassword(id, string(hash)); err != nil {
+			return err
+		}
+
+		var except *uuid.UUID
+		if current, err := uuid.Parse(sessionId); err == nil {
+			except = &current
+		}
+
+		revoked, err = repository.DeleteUserSessions(id, except)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, revokedId := range revoked {
+		if err := s.revocations.RevokeSession(ctx, userId, revokedId.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceAuth) issueSession(ctx context.Context, user *models.AuthUser, info SessionInfo) (*schemas.Session, error) {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	session, err := repository.CreateSession(user.ID, info.UserAgent, info.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.UpdateLastSignIn(user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.newSession(ctx, user, session)
+}
+
+// newSession signs an access token for the session and stores a fresh
+// refresh token for it.
+func (s *serviceAuth) newSession(ctx context.Context, user *models.AuthUser, session *models.AuthSession) (*schemas.Session, error) {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.Auth.AccessTokenTTL)
+
+	audience := "authenticated"
+	if s.config.JWT.Audience != "" {
+		audience = s.config.JWT.Audience
+	}
+
+	claims := config.AccessTokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   user.ID.String(),
+			Audience:  audience,
+			Issuer:    s.config.JWT.Issuer,
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  now.Unix(),
+		},
+		Email:                       user.Email,
+		Role:                        "authenticated",
+		AppMetaData:                 map[string]interface{}{"provider": "email", "providers": []string{"email"}},
+		UserMetaData:                map[string]interface{}{},
+		AuthenticatorAssuranceLevel: "aal1",
+		AuthenticationMethodReference: []config.AMREntry{
+			{Method: "password", Timestamp: session.CreatedAt.Unix()},
+		},
+		SessionId: session.ID.String(),
+	}
+
+	accessToken, err := pkg.SignAccessToken(&claims, s.config.JWT.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.CreateRefreshToken(session.ID, hashToken(refreshToken), now.Add(s.config.Auth.RefreshTokenTTL)); err != nil {
+		return nil, err
+	}
+
+	return &schemas.Session{
+		AccessToken:  accessToken,
+		TokenType:    "bearer",
+		ExpiresIn:    int(s.config.Auth.AccessTokenTTL.Seconds()),
+		ExpiresAt:    expiresAt.Unix(),
+		RefreshToken: refreshToken,
+		User:         user,
+	}, nil
+}
+
+func (s *serviceAuth) sendOneTimeToken(ctx context.Context, user *models.AuthUser, tokenType models.AuthOneTimeTokenType) error {
+	repository := repositories.NewAuthRepository(s.db.WithContext(ctx))
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	subject, template, ttl := "Confirm your signup", pkg.TemplateConfirmSignup, s.config.Auth.ConfirmationTTL
+	if tokenType == models.AuthTokenRecovery {
+		subject, template, ttl = "Reset your password", pkg.TemplatePasswordReset, s.config.Auth.RecoveryTTL
+	}
+
+	if err := repository.CreateOneTimeToken(user.ID, tokenType, hashToken(token), time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(user.Email, subject, template, pkg.TemplateData{
+		SiteURL:   s.config.SiteURL,
+		Email:     user.Email,
+		TokenHash: token,
+	})
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what tokens are stored as, so a database leak doesn't hand
+// out working sessions.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewAuthService(db *gorm.DB, config *config.GlobalConfiguration, mailer *pkg.Mailer, revocations ServiceRevocation) *serviceAuth {
+	return &serviceAuth{
+		db:          db,
+		config:      config,
+		mailer:      mailer,
+		revocations: revocations,
+	}
+}
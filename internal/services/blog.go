@@ -1,72 +1,85 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 	"gorm.io/gorm"
 )
 
 type ServiceBlog interface {
-	GetAll(userId *string, query *string, cursor int, limit int) (any, error)
-	GetUserBlogs(userId string, query *string, cursor int, limit int) (any, error)
-	Get(userId string, blogId string) (any, error)
-	GetBlogBySlug(userId *string, slug string) (any, error)
-	Create(userId string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error)
-	Update(userId string, blogId string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error)
-	Unpublish(userId string, blogId string) error
-	Delete(userId string, blogId string) error
-	GetMetadata(userId string) (any, error)
-	UpdateMetadata(userId string, data *schemas.SchemaBlogMetadata) error
-	Reaction(blogId string, userId string, data *schemas.SchemaReaction) (any, error)
-	Bookmark(blogId string, userId string) (*models.BlogBookmark, error)
-	RemoveBookmark(blogId string, userId string) error
+	GetAll(ctx context.Context, userId *string, query *string, sort string, page schemas.PageQuery) (*schemas.Page[schemas.SelectBlog], error)
+	GetUserBlogs(ctx context.Context, userId string, query *string, page schemas.PageQuery) (*schemas.Page[schemas.SelectBlog], error)
+	Get(ctx context.Context, userId string, blogId string) (any, error)
+	GetBlogBySlug(ctx context.Context, userId *string, slug string) (any, error)
+	GetRelated(ctx context.Context, slug string, limit int) ([]schemas.SelectRelatedBlog, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error)
+	Update(ctx context.Context, userId string, blogId string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error)
+	Unpublish(ctx context.Context, userId string, blogId string) error
+	Delete(ctx context.Context, userId string, blogId string) error
+	GetMetadata(ctx context.Context, userId string) (any, error)
+	UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaBlogMetadata) error
+	Reaction(ctx context.Context, blogId string, userId string, data *schemas.SchemaReaction) (any, error)
+	Bookmark(ctx context.Context, blogId string, userId string) (*models.BlogBookmark, error)
+	RemoveBookmark(ctx context.Context, blogId string, userId string) error
 }
 
 type serviceBlog struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceBlog) GetAll(userId *string, query *string, cursor int, limit int) (any, error) {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) GetAll(ctx context.Context, userId *string, query *string, sort string, page schemas.PageQuery) (*schemas.Page[schemas.SelectBlog], error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
-	res, err := blogRepository.GetAll(userId, query, cursor, limit)
+	res, err := blogRepository.GetAll(userId, query, nil, sort, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	var total *int64
+	if page.IncludeTotal {
+		count, err := blogRepository.CountAll(query, nil)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
-	return map[string]any{"list": res, "cursor": nextCursor}, nil
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceBlog) GetUserBlogs(userId string, query *string, cursor int, limit int) (any, error) {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) GetUserBlogs(ctx context.Context, userId string, query *string, page schemas.PageQuery) (*schemas.Page[schemas.SelectBlog], error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
-	res, err := blogRepository.GetUserBlogs(userId, query, cursor, limit)
+	res, err := blogRepository.GetUserBlogs(userId, query, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	var total *int64
+	if page.IncludeTotal {
+		count, err := blogRepository.CountUserBlogs(userId, query)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
-	return map[string]any{"list": res, "cursor": nextCursor}, nil
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceBlog) Get(userId string, id string) (any, error) {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) Get(ctx context.Context, userId string, id string) (any, error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
 	res, err := blogRepository.Get(userId, id)
 	if err != nil {
@@ -76,8 +89,8 @@ func (s *serviceBlog) Get(userId string, id string) (any, error) {
 	return res, nil
 }
 
-func (s *serviceBlog) GetBlogBySlug(userId *string, slug string) (any, error) {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) GetBlogBySlug(ctx context.Context, userId *string, slug string) (any, error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
 	res, err := blogRepository.GetBlogBySlug(userId, slug)
 	if err != nil {
@@ -87,14 +100,36 @@ func (s *serviceBlog) GetBlogBySlug(userId *string, slug string) (any, error) {
 	return res, nil
 }
 
-func (s *serviceBlog) Create(userId string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error) {
+// GetRelated suggests blogs to read after the published blog slug.
+func (s *serviceBlog) GetRelated(ctx context.Context, slug string, limit int) ([]schemas.SelectRelatedBlog, error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
+
+	id, err := blogRepository.GetPublishedIdBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBlogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := blogRepository.GetRelated(id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		res = []schemas.SelectRelatedBlog{}
+	}
+	return res, nil
+}
+
+func (s *serviceBlog) Create(ctx context.Context, userId string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error) {
 	var blog *models.Blog
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		blogRepository := repositories.NewBlogRepository(tx)
-		tagRepository := repositories.NewTagRepository(tx)
 
-		tags, err := tagRepository.FindOrCreate(userId, data.Tags)
+		tags, err := resolveBlogTags(tx, data.Tags, nil)
 		if err != nil {
 			return err
 		}
@@ -103,6 +138,10 @@ func (s *serviceBlog) Create(userId string, data *schemas.SchemaBlog, publish bo
 		if err != nil {
 			return err
 		}
+
+		if publish {
+			return enqueueWebhookEvent(tx, userId, models.WebhookEventBlogPublished, blogWebhookData(blog))
+		}
 		return nil
 	})
 
@@ -110,18 +149,29 @@ func (s *serviceBlog) Create(userId string, data *schemas.SchemaBlog, publish bo
 		return nil, err
 	}
 
+	if publish {
+		observability.BlogsPublished.Inc()
+	}
+	s.cache.Invalidate(cache.UserTag(userId))
 	return blog, nil
 
 }
 
-func (s *serviceBlog) Update(userId string, id string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error) {
+func (s *serviceBlog) Update(ctx context.Context, userId string, id string, data *schemas.SchemaBlog, publish bool) (*models.Blog, error) {
 	var blog *models.Blog
+	// Postgres keeps microseconds, so a published_at set by this update is
+	// never before start.
+	start := time.Now().Truncate(time.Microsecond)
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		blogRepository := repositories.NewBlogRepository(tx)
-		tagRepository := repositories.NewTagRepository(tx)
 
-		tags, err := tagRepository.FindOrCreate(userId, data.Tags)
+		current, err := repositories.NewTagRepository(tx).GetBlogTagIds(id)
+		if err != nil {
+			return err
+		}
+
+		tags, err := resolveBlogTags(tx, data.Tags, current)
 		if err != nil {
 			return err
 		}
@@ -131,6 +181,10 @@ func (s *serviceBlog) Update(userId string, id string, data *schemas.SchemaBlog,
 			return err
 		}
 
+		// Publishing an already published blog only saves it.
+		if publish && blog.PublishedAt != nil && !blog.PublishedAt.Before(start) {
+			return enqueueWebhookEvent(tx, userId, models.WebhookEventBlogPublished, blogWebhookData(blog))
+		}
 		return nil
 	})
 
@@ -138,29 +192,35 @@ func (s *serviceBlog) Update(userId string, id string, data *schemas.SchemaBlog,
 		return nil, err
 	}
 
+	if publish {
+		observability.BlogsPublished.Inc()
+	}
+	s.cache.Invalidate(cache.UserTag(userId))
 	return blog, nil
 }
 
-func (s *serviceBlog) Unpublish(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceBlog) Unpublish(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		blogRepository := repositories.NewBlogRepository(tx)
 
-		if err := blogRepository.Unpublish(userId, id); err != nil {
+		blog, err := blogRepository.Unpublish(userId, id)
+		if err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueWebhookEvent(tx, userId, models.WebhookEventBlogUnpublished, blogWebhookData(blog))
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceBlog) Delete(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceBlog) Delete(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		blogRepository := repositories.NewBlogRepository(tx)
 
 		if err := blogRepository.Delete(userId, id); err != nil {
@@ -174,11 +234,12 @@ func (s *serviceBlog) Delete(userId string, id string) error {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceBlog) GetMetadata(userId string) (any, error) {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceBlog) GetMetadata(ctx context.Context, userId string) (any, error) {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := userRepository.GetModuleMetadata(userId, "blog")
 	if err != nil {
@@ -188,19 +249,20 @@ func (s *serviceBlog) GetMetadata(userId string) (any, error) {
 	return res, nil
 }
 
-func (s *serviceBlog) UpdateMetadata(userId string, data *schemas.SchemaBlogMetadata) error {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceBlog) UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaBlogMetadata) error {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	err := userRepository.AddOrUpdateModuleMetadata(userId, "blog", data)
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceBlog) Reaction(blogId string, userId string, data *schemas.SchemaReaction) (any, error) {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) Reaction(ctx context.Context, blogId string, userId string, data *schemas.SchemaReaction) (any, error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -217,11 +279,16 @@ func (s *serviceBlog) Reaction(blogId string, userId string, data *schemas.Schem
 		return nil, err
 	}
 
+	// The cached blog carries its reaction counts.
+	if ownerId, err := blogRepository.GetOwnerIdById(uint(blogIdInt)); err == nil {
+		s.cache.Invalidate(cache.UserTag(ownerId))
+	}
+
 	return reaction, nil
 }
 
-func (s *serviceBlog) Bookmark(blogId string, userId string) (*models.BlogBookmark, error) {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) Bookmark(ctx context.Context, blogId string, userId string) (*models.BlogBookmark, error) {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -241,8 +308,8 @@ func (s *serviceBlog) Bookmark(blogId string, userId string) (*models.BlogBookma
 	return blogBookmark, nil
 }
 
-func (s *serviceBlog) RemoveBookmark(blogId string, userId string) error {
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceBlog) RemoveBookmark(ctx context.Context, blogId string, userId string) error {
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -261,8 +328,21 @@ func (s *serviceBlog) RemoveBookmark(blogId string, userId string) error {
 	return nil
 }
 
-func NewBlogService(db *gorm.DB) *serviceBlog {
+// blogWebhookData is the data of blog.published events.
+func blogWebhookData(blog *models.Blog) map[string]any {
+	return map[string]any{
+		"id":           blog.ID,
+		"user_id":      blog.UserId,
+		"title":        blog.Title,
+		"slug":         blog.Slug,
+		"cover_image":  blog.CoverImage,
+		"published_at": blog.PublishedAt,
+	}
+}
+
+func NewBlogService(db *gorm.DB, store cache.Store) *serviceBlog {
 	return &serviceBlog{
-		db: db,
+		db:    db,
+		cache: store,
 	}
 }
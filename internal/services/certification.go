@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -8,21 +11,22 @@ import (
 )
 
 type ServiceUserCertification interface {
-	GetAll(userId string) (*models.Certifications, error)
-	Create(userId string, data *schemas.SchemaCertification) (*models.Certification, error)
-	Update(userId string, id string, data *schemas.SchemaCertification) (*models.Certification, error)
-	Reorder(userId string, id string, newIndex int) error
-	Delete(userId string, id string) error
-	GetMetadata(userId string) (interface{}, error)
-	UpdateMetadata(userId string, data *schemas.SchemaCertificationMetadata) error
+	GetAll(ctx context.Context, userId string) (*models.Certifications, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaCertification) (*models.Certification, error)
+	Update(ctx context.Context, userId string, id string, data *schemas.SchemaCertification) (*models.Certification, error)
+	Reorder(ctx context.Context, userId string, id string, newIndex int) error
+	Delete(ctx context.Context, userId string, id string) error
+	GetMetadata(ctx context.Context, userId string) (interface{}, error)
+	UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaCertificationMetadata) error
 }
 
 type serviceUserCertification struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceUserCertification) GetAll(userId string) (*models.Certifications, error) {
-	userExperienceRepository := repositories.NewUserCertificationRepository(s.db)
+func (s *serviceUserCertification) GetAll(ctx context.Context, userId string) (*models.Certifications, error) {
+	userExperienceRepository := repositories.NewUserCertificationRepository(s.db.WithContext(ctx))
 
 	res, err := userExperienceRepository.GetAll(userId)
 	if err != nil {
@@ -32,68 +36,94 @@ func (s *serviceUserCertification) GetAll(userId string) (*models.Certifications
 	return res, nil
 }
 
-func (s *serviceUserCertification) Create(userId string, data *schemas.SchemaCertification) (*models.Certification, error) {
-	userExperienceRepository := repositories.NewUserCertificationRepository(s.db)
+func (s *serviceUserCertification) Create(ctx context.Context, userId string, data *schemas.SchemaCertification) (*models.Certification, error) {
+	var res *models.Certification
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userExperienceRepository := repositories.NewUserCertificationRepository(tx)
+
+		var err error
+		res, err = userExperienceRepository.Create(userId, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "certification", "created")
+	})
 
-	res, err := userExperienceRepository.Create(userId, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return res, nil
 }
 
-func (s *serviceUserCertification) Update(userId string, id string, data *schemas.SchemaCertification) (*models.Certification, error) {
-	userExperienceRepository := repositories.NewUserCertificationRepository(s.db)
+func (s *serviceUserCertification) Update(ctx context.Context, userId string, id string, data *schemas.SchemaCertification) (*models.Certification, error) {
+	var res *models.Certification
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userExperienceRepository := repositories.NewUserCertificationRepository(tx)
+
+		var err error
+		res, err = userExperienceRepository.Update(userId, id, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "certification", "updated")
+	})
 
-	res, err := userExperienceRepository.Update(userId, id, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return res, nil
 }
 
-func (s *serviceUserCertification) Reorder(userId string, id string, newIndex int) error {
+func (s *serviceUserCertification) Reorder(ctx context.Context, userId string, id string, newIndex int) error {
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userExperienceRepository := repositories.NewUserCertificationRepository(tx)
 
 		if err := userExperienceRepository.Reorder(userId, id, newIndex); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "certification", "reordered")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 
 }
 
-func (s *serviceUserCertification) Delete(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceUserCertification) Delete(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userExperienceRepository := repositories.NewUserCertificationRepository(tx)
 
 		if err := userExperienceRepository.Delete(userId, id); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "certification", "deleted")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceUserCertification) GetMetadata(userId string) (interface{}, error) {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserCertification) GetMetadata(ctx context.Context, userId string) (interface{}, error) {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := userRepository.GetModuleMetadata(userId, "certification")
 	if err != nil {
@@ -103,19 +133,28 @@ func (s *serviceUserCertification) GetMetadata(userId string) (interface{}, erro
 	return res, nil
 }
 
-func (s *serviceUserCertification) UpdateMetadata(userId string, data *schemas.SchemaCertificationMetadata) error {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserCertification) UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaCertificationMetadata) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userRepository := repositories.NewUserRepository(tx)
+
+		if err := userRepository.AddOrUpdateModuleMetadata(userId, "certification", data); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "certification", "metadata_updated")
+	})
 
-	err := userRepository.AddOrUpdateModuleMetadata(userId, "certification", data)
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func NewUserCertificationService(db *gorm.DB) *serviceUserCertification {
+func NewUserCertificationService(db *gorm.DB, store cache.Store) *serviceUserCertification {
 	return &serviceUserCertification{
-		db: db,
+		db:    db,
+		cache: store,
 	}
 }
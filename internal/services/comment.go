@@ -1,50 +1,58 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"strconv"
 
 	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 	"gorm.io/gorm"
 )
 
 type ServiceComment interface {
-	GetAll(userId *string, module string, slug string, cursor int, limit int, parentId *int) (any, error)
-	Create(userId string, data *schemas.SchemaCreateComment) (any, error)
-	Reaction(commentId string, userId string, data *schemas.SchemaReaction) (any, error)
-	Reply(userId string, commentId string, data *schemas.SchemaCommentReply) (any, error)
+	GetAll(ctx context.Context, userId *string, module string, slug string, page schemas.PageQuery, parentId *int) (*schemas.Page[schemas.SelectComment], error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaCreateComment) (any, error)
+	Reaction(ctx context.Context, commentId string, userId string, data *schemas.SchemaReaction) (any, error)
+	Reply(ctx context.Context, userId string, commentId string, data *schemas.SchemaCommentReply) (any, error)
 }
 
 type serviceComment struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceComment) GetAll(userId *string, module string, slug string, cursor int, limit int, parentId *int) (any, error) {
+func (s *serviceComment) GetAll(ctx context.Context, userId *string, module string, slug string, page schemas.PageQuery, parentId *int) (*schemas.Page[schemas.SelectComment], error) {
 
-	commentRepository := repositories.NewCommentRepository(s.db)
+	commentRepository := repositories.NewCommentRepository(s.db.WithContext(ctx))
 
-	res, err := commentRepository.Get(userId, module, slug, cursor, limit, parentId)
+	res, err := commentRepository.Get(userId, module, slug, page.Cursor, page.FetchLimit(), parentId)
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	var total *int64
+	if page.IncludeTotal {
+		count, err := commentRepository.Count(module, slug, parentId)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
-	return map[string]any{"list": res, "cursor": nextCursor}, nil
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceComment) Create(userId string, data *schemas.SchemaCreateComment) (any, error) {
+func (s *serviceComment) Create(ctx context.Context, userId string, data *schemas.SchemaCreateComment) (any, error) {
+	var ownerId string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		commentRepository := repositories.NewCommentRepository(tx)
+		blogRepository := repositories.NewBlogRepository(tx)
 
-	commentRepository := repositories.NewCommentRepository(s.db)
-	blogRepository := repositories.NewBlogRepository(s.db)
-
-	err := s.db.Transaction(func(tx *gorm.DB) error {
 		comment, err := commentRepository.Create(userId, data)
 		if err != nil {
 			return err
@@ -60,6 +68,11 @@ func (s *serviceComment) Create(userId string, data *schemas.SchemaCreateComment
 			if err != nil {
 				return err
 			}
+
+			if blog.PublisherId != nil {
+				ownerId = *blog.PublisherId
+				return enqueueWebhookEvent(tx, *blog.PublisherId, models.WebhookEventCommentCreated, commentWebhookData(comment, data.Module, blog.ID))
+			}
 		} else {
 			return errors.New("module not supported")
 		}
@@ -71,11 +84,17 @@ func (s *serviceComment) Create(userId string, data *schemas.SchemaCreateComment
 		return nil, err
 	}
 
+	// The cached blog carries its comment count.
+	if ownerId != "" {
+		s.cache.Invalidate(cache.UserTag(ownerId))
+	}
+
+	observability.CommentsCreated.Inc()
 	return nil, nil
 }
 
-func (s *serviceComment) Reaction(commentId string, userId string, data *schemas.SchemaReaction) (any, error) {
-	commentRepository := repositories.NewCommentRepository(s.db)
+func (s *serviceComment) Reaction(ctx context.Context, commentId string, userId string, data *schemas.SchemaReaction) (any, error) {
+	commentRepository := repositories.NewCommentRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -95,9 +114,8 @@ func (s *serviceComment) Reaction(commentId string, userId string, data *schemas
 	return nil, nil
 }
 
-func (s *serviceComment) Reply(userId string, commentId string, data *schemas.SchemaCommentReply) (any, error) {
-	commentRepository := repositories.NewCommentRepository(s.db)
-	blogRepository := repositories.NewBlogRepository(s.db)
+func (s *serviceComment) Reply(ctx context.Context, userId string, commentId string, data *schemas.SchemaCommentReply) (any, error) {
+	commentRepository := repositories.NewCommentRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -114,28 +132,61 @@ func (s *serviceComment) Reply(userId string, commentId string, data *schemas.Sc
 		return nil, err
 	}
 
-	newComment, err := commentRepository.Reply(parentComment.ID, userUUID, data)
-	if err != nil {
-		return nil, err
-	}
+	var ownerId string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		commentRepository := repositories.NewCommentRepository(tx)
+		blogRepository := repositories.NewBlogRepository(tx)
+
+		newComment, err := commentRepository.Reply(parentComment.ID, userUUID, data)
+		if err != nil {
+			return err
+		}
+
+		if data.Module != "blog" {
+			return errors.New("module not supported")
+		}
 
-	if data.Module == "blog" {
 		commentBlog, err := blogRepository.GetCommentBlog(parentComment.ID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		_, err = blogRepository.CreateComment(commentBlog.BlogId, newComment.ID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		ownerId, err = blogRepository.GetOwnerIdById(commentBlog.BlogId)
+		if err != nil {
+			return err
 		}
-	} else {
-		return nil, errors.New("module not supported")
+
+		return enqueueWebhookEvent(tx, ownerId, models.WebhookEventCommentCreated, commentWebhookData(newComment, data.Module, commentBlog.BlogId))
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(ownerId))
+	observability.CommentsCreated.Inc()
 	return nil, nil
 }
 
-func NewServiceComment(db *gorm.DB) *serviceComment {
-	return &serviceComment{db: db}
+// commentWebhookData is the data of comment.created events, sent to the
+// owner of what was commented on.
+func commentWebhookData(comment *models.Comment, module string, blogId uint) map[string]any {
+	return map[string]any{
+		"id":         comment.ID,
+		"parent_id":  comment.ParentId,
+		"user_id":    comment.UserId,
+		"body":       comment.Body,
+		"created_at": comment.CreatedAt,
+		"module":     module,
+		"blog_id":    blogId,
+	}
+}
+
+func NewServiceComment(db *gorm.DB, store cache.Store) *serviceComment {
+	return &serviceComment{db: db, cache: store}
 }
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -8,21 +11,22 @@ import (
 )
 
 type ServiceUserEducation interface {
-	GetAll(userId string) (*models.Educations, error)
-	Create(userId string, data *schemas.SchemaEducation) (*models.Education, error)
-	Update(userId string, id string, data *schemas.SchemaEducation) (*models.Education, error)
-	Reorder(userId string, id string, newIndex int) error
-	Delete(userId string, id string) error
-	GetMetadata(userId string) (interface{}, error)
-	UpdateMetadata(userId string, data *schemas.SchemaEducationMetadata) error
+	GetAll(ctx context.Context, userId string) (*models.Educations, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaEducation) (*models.Education, error)
+	Update(ctx context.Context, userId string, id string, data *schemas.SchemaEducation) (*models.Education, error)
+	Reorder(ctx context.Context, userId string, id string, newIndex int) error
+	Delete(ctx context.Context, userId string, id string) error
+	GetMetadata(ctx context.Context, userId string) (interface{}, error)
+	UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaEducationMetadata) error
 }
 
 type serviceUserEducation struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceUserEducation) GetAll(userId string) (*models.Educations, error) {
-	userEducationRepository := repositories.NewUserEducationRepository(s.db)
+func (s *serviceUserEducation) GetAll(ctx context.Context, userId string) (*models.Educations, error) {
+	userEducationRepository := repositories.NewUserEducationRepository(s.db.WithContext(ctx))
 
 	res, err := userEducationRepository.GetAll(userId)
 	if err != nil {
@@ -32,68 +36,94 @@ func (s *serviceUserEducation) GetAll(userId string) (*models.Educations, error)
 	return res, nil
 }
 
-func (s *serviceUserEducation) Create(userId string, data *schemas.SchemaEducation) (*models.Education, error) {
-	userEducationRepository := repositories.NewUserEducationRepository(s.db)
+func (s *serviceUserEducation) Create(ctx context.Context, userId string, data *schemas.SchemaEducation) (*models.Education, error) {
+	var edu *models.Education
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userEducationRepository := repositories.NewUserEducationRepository(tx)
+
+		var err error
+		edu, err = userEducationRepository.Create(userId, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "education", "created")
+	})
 
-	edu, err := userEducationRepository.Create(userId, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return edu, nil
 }
 
-func (s *serviceUserEducation) Update(userId string, id string, data *schemas.SchemaEducation) (*models.Education, error) {
-	userEducationRepository := repositories.NewUserEducationRepository(s.db)
+func (s *serviceUserEducation) Update(ctx context.Context, userId string, id string, data *schemas.SchemaEducation) (*models.Education, error) {
+	var edu *models.Education
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userEducationRepository := repositories.NewUserEducationRepository(tx)
+
+		var err error
+		edu, err = userEducationRepository.Update(userId, id, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "education", "updated")
+	})
 
-	edu, err := userEducationRepository.Update(userId, id, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return edu, nil
 }
 
-func (s *serviceUserEducation) Reorder(userId string, id string, newIndex int) error {
+func (s *serviceUserEducation) Reorder(ctx context.Context, userId string, id string, newIndex int) error {
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userEducationRepository := repositories.NewUserEducationRepository(tx)
 
 		if err := userEducationRepository.Reorder(userId, id, newIndex); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "education", "reordered")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 
 }
 
-func (s *serviceUserEducation) Delete(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceUserEducation) Delete(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userEducationRepository := repositories.NewUserEducationRepository(tx)
 
 		if err := userEducationRepository.Delete(userId, id); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "education", "deleted")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceUserEducation) GetMetadata(userId string) (interface{}, error) {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserEducation) GetMetadata(ctx context.Context, userId string) (interface{}, error) {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := userRepository.GetModuleMetadata(userId, "education")
 	if err != nil {
@@ -103,19 +133,28 @@ func (s *serviceUserEducation) GetMetadata(userId string) (interface{}, error) {
 	return res, nil
 }
 
-func (s *serviceUserEducation) UpdateMetadata(userId string, data *schemas.SchemaEducationMetadata) error {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserEducation) UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaEducationMetadata) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userRepository := repositories.NewUserRepository(tx)
+
+		if err := userRepository.AddOrUpdateModuleMetadata(userId, "education", data); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "education", "metadata_updated")
+	})
 
-	err := userRepository.AddOrUpdateModuleMetadata(userId, "education", data)
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func NewUserEducationService(db *gorm.DB) *serviceUserEducation {
+func NewUserEducationService(db *gorm.DB, store cache.Store) *serviceUserEducation {
 	return &serviceUserEducation{
-		db: db,
+		db:    db,
+		cache: store,
 	}
 }
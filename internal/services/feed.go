@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+type ServiceFeed interface {
+	GetFeed(ctx context.Context, userId string, query schemas.FeedQuery) (*schemas.Feed, error)
+}
+
+type serviceFeed struct {
+	db *gorm.DB
+}
+
+// GetFeed returns the blogs and projects of the users and tags userId
+// follows, or trending ones when userId follows neither. A cursor keeps
+// paging the feed it came from.
+func (s *serviceFeed) GetFeed(ctx context.Context, userId string, query schemas.FeedQuery) (*schemas.Feed, error) {
+	source := schemas.FeedSourceFollowing
+	if query.Cursor != nil {
+		source = query.Cursor.Source
+	} else {
+		following, err := repositories.NewUserRepository(s.db.WithContext(ctx)).CountFollowing(userId)
+		if err != nil {
+			return nil, err
+		}
+		if following == 0 {
+			following, err = repositories.NewTagRepository(s.db.WithContext(ctx)).CountFollowed(userId)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if following == 0 {
+			source = schemas.FeedSourceTrending
+		}
+	}
+
+	feedRepository := repositories.NewFeedRepository(s.db.WithContext(ctx))
+
+	var items []schemas.SelectFeedItem
+	var err error
+	if source == schemas.FeedSourceTrending {
+		items, err = feedRepository.GetTrendingFeed(userId, query.Cursor, query.FetchLimit())
+	} else {
+		items, err = feedRepository.GetFollowingFeed(userId, query.Cursor, query.FetchLimit())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewFeed(source, items, query), nil
+}
+
+func NewFeedService(db *gorm.DB) *serviceFeed {
+	return &serviceFeed{
+		db: db,
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -8,21 +11,22 @@ import (
 )
 
 type ServiceUserHackathon interface {
-	GetAll(userId string) (*models.Hackathons, error)
-	Create(userId string, data *schemas.SchemaHackathon) (*models.Hackathon, error)
-	Update(userId string, id string, data *schemas.SchemaHackathon) (*models.Hackathon, error)
-	Reorder(userId string, id string, newIndex int) error
-	Delete(userId string, id string) error
-	GetMetadata(userId string) (interface{}, error)
-	UpdateMetadata(userId string, data *schemas.SchemaHackathonMetadata) error
+	GetAll(ctx context.Context, userId string) (*models.Hackathons, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaHackathon) (*models.Hackathon, error)
+	Update(ctx context.Context, userId string, id string, data *schemas.SchemaHackathon) (*models.Hackathon, error)
+	Reorder(ctx context.Context, userId string, id string, newIndex int) error
+	Delete(ctx context.Context, userId string, id string) error
+	GetMetadata(ctx context.Context, userId string) (interface{}, error)
+	UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaHackathonMetadata) error
 }
 
 type serviceUserHackathon struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceUserHackathon) GetAll(userId string) (*models.Hackathons, error) {
-	userHackathonRepository := repositories.NewUserHackathonRepository(s.db)
+func (s *serviceUserHackathon) GetAll(ctx context.Context, userId string) (*models.Hackathons, error) {
+	userHackathonRepository := repositories.NewUserHackathonRepository(s.db.WithContext(ctx))
 
 	res, err := userHackathonRepository.GetAll(userId)
 	if err != nil {
@@ -32,68 +36,94 @@ func (s *serviceUserHackathon) GetAll(userId string) (*models.Hackathons, error)
 	return res, nil
 }
 
-func (s *serviceUserHackathon) Create(userId string, data *schemas.SchemaHackathon) (*models.Hackathon, error) {
-	userHackathonRepository := repositories.NewUserHackathonRepository(s.db)
+func (s *serviceUserHackathon) Create(ctx context.Context, userId string, data *schemas.SchemaHackathon) (*models.Hackathon, error) {
+	var exp *models.Hackathon
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userHackathonRepository := repositories.NewUserHackathonRepository(tx)
+
+		var err error
+		exp, err = userHackathonRepository.Create(userId, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "hackathon", "created")
+	})
 
-	exp, err := userHackathonRepository.Create(userId, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return exp, nil
 }
 
-func (s *serviceUserHackathon) Update(userId string, id string, data *schemas.SchemaHackathon) (*models.Hackathon, error) {
-	userHackathonRepository := repositories.NewUserHackathonRepository(s.db)
+func (s *serviceUserHackathon) Update(ctx context.Context, userId string, id string, data *schemas.SchemaHackathon) (*models.Hackathon, error) {
+	var exp *models.Hackathon
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userHackathonRepository := repositories.NewUserHackathonRepository(tx)
+
+		var err error
+		exp, err = userHackathonRepository.Update(userId, id, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "hackathon", "updated")
+	})
 
-	exp, err := userHackathonRepository.Update(userId, id, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return exp, nil
 }
 
-func (s *serviceUserHackathon) Reorder(userId string, id string, newIndex int) error {
+func (s *serviceUserHackathon) Reorder(ctx context.Context, userId string, id string, newIndex int) error {
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userHackathonRepository := repositories.NewUserHackathonRepository(tx)
 
 		if err := userHackathonRepository.Reorder(userId, id, newIndex); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "hackathon", "reordered")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 
 }
 
-func (s *serviceUserHackathon) Delete(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceUserHackathon) Delete(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userHackathonRepository := repositories.NewUserHackathonRepository(tx)
 
 		if err := userHackathonRepository.Delete(userId, id); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "hackathon", "deleted")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceUserHackathon) GetMetadata(userId string) (interface{}, error) {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserHackathon) GetMetadata(ctx context.Context, userId string) (interface{}, error) {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := userRepository.GetModuleMetadata(userId, "hackathon")
 	if err != nil {
@@ -103,19 +133,28 @@ func (s *serviceUserHackathon) GetMetadata(userId string) (interface{}, error) {
 	return res, nil
 }
 
-func (s *serviceUserHackathon) UpdateMetadata(userId string, data *schemas.SchemaHackathonMetadata) error {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserHackathon) UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaHackathonMetadata) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userRepository := repositories.NewUserRepository(tx)
+
+		if err := userRepository.AddOrUpdateModuleMetadata(userId, "hackathon", data); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "hackathon", "metadata_updated")
+	})
 
-	err := userRepository.AddOrUpdateModuleMetadata(userId, "hackathon", data)
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func NewUserHackathonService(db *gorm.DB) *serviceUserHackathon {
+func NewUserHackathonService(db *gorm.DB, store cache.Store) *serviceUserHackathon {
 	return &serviceUserHackathon{
-		db: db,
+		db:    db,
+		cache: store,
 	}
 }
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/jobs"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	JobWebhookEvent = "webhook.event"
+	JobPrune        = "maintenance.prune"
+	JobScores       = "maintenance.scores"
+)
+
+// pruneInterval is how often expired tokens and old deliveries, jobs and
+// views are deleted.
+const pruneInterval = time.Hour
+
+// RegisterJobs registers the handlers of the jobs enqueued by the services,
+// and the recurring maintenance jobs.
+func RegisterJobs(registry *jobs.Registry, db *gorm.DB, conf *config.GlobalConfiguration) {
+	jobs.Handle(registry, JobWebhookEvent, func(ctx context.Context, payload schemas.JobWebhookEvent) error {
+		data, err := json.Marshal(payload.Event)
+		if err != nil {
+			return jobs.Permanent(err)
+		}
+
+		// Retried as a whole, so the deliveries are created once or not at
+		// all.
+		repository := repositories.NewWebhookRepository(db.WithContext(ctx))
+		return repository.Enqueue(payload.UserId, payload.Event.Type, payload.Event.ID, data)
+	})
+
+	jobs.Handle(registry, JobPrune, func(ctx context.Context, _ struct{}) error {
+		return prune(ctx, db, conf)
+	})
+	registry.Schedule(JobPrune, pruneInterval)
+
+	jobs.Handle(registry, JobScores, func(ctx context.Context, _ struct{}) error {
+		return updateScores(ctx, db)
+	})
+	registry.Schedule(JobScores, conf.Jobs.ScoreInterval)
+}
+
+func prune(ctx context.Context, db *gorm.DB, conf *config.GlobalConfiguration) error {
+	db = db.WithContext(ctx)
+	now := time.Now()
+
+	revocations, err := repositories.NewRevocationRepository(db).DeleteExpired()
+	if err != nil {
+		return err
+	}
+
+	tokens, err := repositories.NewAuthRepository(db).DeleteExpiredTokens()
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := repositories.NewWebhookRepository(db).DeleteFinishedDeliveries(now.Add(-conf.Webhook.Retention))
+	if err != nil {
+		return err
+	}
+
+	finishedJobs, err := repositories.NewJobRepository(db).DeleteFinished(now.Add(-conf.Jobs.Retention))
+	if err != nil {
+		return err
+	}
+
+	analytics := repositories.NewAnalyticsRepository(db)
+
+	views, err := analytics.DeleteBlogViews(now.Add(-conf.Analytics.Retention))
+	if err != nil {
+		return err
+	}
+
+	visits, err := analytics.DeletePortfolioVisits(now.Add(-conf.Analytics.Retention))
+	if err != nil {
+		return err
+	}
+
+	// Views of yesterday may still be being flushed.
+	salts, err := analytics.DeleteSalts(now.UTC().AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"component":   "jobs",
+		"revocations": revocations,
+		"tokens":      tokens,
+		"deliveries":  deliveries,
+		"jobs":        finishedJobs,
+		"views":       views,
+		"visits":      visits,
+		"salts":       salts,
+	}).Info("pruned expired records")
+
+	return nil
+}
+
+// updateScores recomputes the scores the blog and portfolio lists are sorted
+// by and the usage counts typeahead suggestions are ranked by.
+func updateScores(ctx context.Context, db *gorm.DB) error {
+	repository := repositories.NewRankingRepository(db.WithContext(ctx))
+
+	blogs, err := repository.UpdateBlogScores()
+	if err != nil {
+		return err
+	}
+
+	portfolios, err := repository.UpdatePortfolioScores()
+	if err != nil {
+		return err
+	}
+
+	skills, err := repository.UpdateSkillUsage()
+	if err != nil {
+		return err
+	}
+
+	tags, err := repository.UpdateTagUsage()
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"component":  "jobs",
+		"blogs":      blogs,
+		"portfolios": portfolios,
+		"skills":     skills,
+		"tags":       tags,
+	}).Debug("updated scores")
+
+	return nil
+}
@@ -1,33 +1,54 @@
 package services
 
 import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 	"gorm.io/gorm"
 )
 
 type ServiceMetadata interface {
-	GetAllSkills(query *string, cursor int, limit int) (any, error)
+	GetAllSkills(ctx context.Context, query *string, page schemas.PageQuery) (*schemas.Page[models.Skill], error)
+	Suggest(ctx context.Context, kind string, q string, limit int) ([]schemas.SelectSuggestion, error)
 }
 
 type serviceMetadata struct {
 	db *gorm.DB
 }
 
-func (s *serviceMetadata) GetAllSkills(query *string, cursor int, limit int) (any, error) {
-	skillRepository := repositories.NewRepositorySkill(s.db)
+func (s *serviceMetadata) GetAllSkills(ctx context.Context, query *string, page schemas.PageQuery) (*schemas.Page[models.Skill], error) {
+	skillRepository := repositories.NewRepositorySkill(s.db.WithContext(ctx))
+
+	res, err := skillRepository.GetAll(query, page.Cursor, page.FetchLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if page.IncludeTotal {
+		count, err := skillRepository.CountAll(query)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return schemas.NewPage(*res, page, total), nil
+}
 
-	res, err := skillRepository.GetAll(query, cursor, limit)
+func (s *serviceMetadata) Suggest(ctx context.Context, kind string, q string, limit int) ([]schemas.SelectSuggestion, error) {
+	suggestions, err := repositories.NewTypeaheadRepository(s.db.WithContext(ctx)).Suggest(kind, q, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	if suggestions == nil {
+		suggestions = []schemas.SelectSuggestion{}
 	}
 
-	return map[string]any{"list": res, "cursor": nextCursor}, nil
+	return suggestions, nil
 }
 
 func NewMetadataService(db *gorm.DB) *serviceMetadata {
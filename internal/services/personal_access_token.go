@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, which tells
+// them apart from JWTs and makes leaked ones easy to scan for.
+const PersonalAccessTokenPrefix = "dp_pat_"
+
+var (
+	ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+	ErrInvalidPersonalAccessToken  = errors.New("personal access token is invalid or has expired")
+)
+
+type ServicePersonalAccessToken interface {
+	GetAll(ctx context.Context, userId string) ([]models.PersonalAccessToken, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaPersonalAccessToken) (*schemas.SelectPersonalAccessTokenCreated, error)
+	Delete(ctx context.Context, userId string, id string) error
+	Authenticate(ctx context.Context, token string) (*models.PersonalAccessToken, error)
+}
+
+type servicePersonalAccessToken struct {
+	db *gorm.DB
+}
+
+func (s *servicePersonalAccessToken) GetAll(ctx context.Context, userId string) ([]models.PersonalAccessToken, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, errors.New("failed to parse user id")
+	}
+
+	repository := repositories.NewPersonalAccessTokenRepository(s.db.WithContext(ctx))
+	return repository.GetAll(userIdUUID)
+}
+
+func (s *servicePersonalAccessToken) Create(ctx context.Context, userId string, data *schemas.SchemaPersonalAccessToken) (*schemas.SelectPersonalAccessTokenCreated, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, errors.New("failed to parse user id")
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	token := PersonalAccessTokenPrefix + secret
+
+	pat := models.PersonalAccessToken{
+		UserId:      userIdUUID,
+		Name:        data.Name,
+		TokenPrefix: token[:len(PersonalAccessTokenPrefix)+4],
+		TokenHash:   hashToken(token),
+		Scopes:      data.Scopes,
+		ExpiresAt:   data.ExpiresAt,
+	}
+
+	repository := repositories.NewPersonalAccessTokenRepository(s.db.WithContext(ctx))
+	if err := repository.Create(&pat); err != nil {
+		return nil, err
+	}
+
+	return &schemas.SelectPersonalAccessTokenCreated{PersonalAccessToken: pat, Token: token}, nil
+}
+
+func (s *servicePersonalAccessToken) Delete(ctx context.Context, userId string, id string) error {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return errors.New("failed to parse user id")
+	}
+
+	tokenId, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return ErrPersonalAccessTokenNotFound
+	}
+
+	repository := repositories.NewPersonalAccessTokenRepository(s.db.WithContext(ctx))
+	deleted, err := repository.Delete(userIdUUID, uint(tokenId))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrPersonalAccessTokenNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the unexpired token matching token.
+func (s *servicePersonalAccessToken) Authenticate(ctx context.Context, token string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	repository := repositories.NewPersonalAccessTokenRepository(s.db.WithContext(ctx))
+
+	pat, err := repository.GetActiveByHash(hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidPersonalAccessToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := repository.TouchLastUsed(pat.ID); err != nil {
+		logrus.WithError(err).WithField("token_id", pat.ID).Warn("unable to record personal access token use")
+	}
+
+	return pat, nil
+}
+
+func NewPersonalAccessTokenService(db *gorm.DB) *servicePersonalAccessToken {
+	return &servicePersonalAccessToken{
+		db: db,
+	}
+}
@@ -1,48 +1,71 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
 	"gorm.io/gorm"
 )
 
+var ErrPortfolioNotFound = errors.New("portfolio not found")
+
+// maxFacetValues bounds the values counted for each portfolio facet.
+const maxFacetValues = 20
+
 type ServicePortfolio interface {
-	GetAll(userId *string, query *string, cursor int, limit int) (interface{}, error)
-	GetPortfolio(slug string) (interface{}, error)
-	GetSubModule(slug string, module string) (interface{}, error)
-	GetUserPortfolio(userId string) (interface{}, error)
-	GetSkills(userId string) (any, error)
-	UpsertSkills(userId string, data *schemas.SchemaSkills) error
-	UpsertResume(userId string, url *string) error
-	UpdateStatus(userId string, status string) error
-	UpdateProfileAttachment(userId string, data *schemas.SchemaProfileAttachment) error
+	GetAll(ctx context.Context, userId *string, filters *schemas.SchemaPortfolioFilters, sort string, page schemas.PageQuery) (*schemas.PortfolioPage, error)
+	GetPortfolio(ctx context.Context, slug string, query *schemas.SchemaPortfolioQuery) (interface{}, error)
+	GetSubModule(ctx context.Context, slug string, module string) (interface{}, error)
+	GetSimilar(ctx context.Context, slug string, limit int) ([]schemas.SelectSimilarPortfolio, error)
+	GetUserPortfolio(ctx context.Context, userId string) (interface{}, error)
+	GetSkills(ctx context.Context, userId string) (any, error)
+	UpsertSkills(ctx context.Context, userId string, data *schemas.SchemaSkills) error
+	UpsertResume(ctx context.Context, userId string, url *string) error
+	UpdateStatus(ctx context.Context, userId string, status string) error
+	UpdateProfileAttachment(ctx context.Context, userId string, data *schemas.SchemaProfileAttachment) error
 }
 
 type servicePortfolio struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *servicePortfolio) GetAll(userId *string, query *string, cursor int, limit int) (interface{}, error) {
-	portfolioRepository := repositories.NewPortfolioRepository(s.db)
+func (s *servicePortfolio) GetAll(ctx context.Context, userId *string, filters *schemas.SchemaPortfolioFilters, sort string, page schemas.PageQuery) (*schemas.PortfolioPage, error) {
+	portfolioRepository := repositories.NewPortfolioRepository(s.db.WithContext(ctx))
 
-	res, err := portfolioRepository.GetAll(userId, query, cursor, limit)
+	res, err := portfolioRepository.GetAll(userId, filters, sort, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	var total *int64
+	if page.IncludeTotal {
+		count, err := portfolioRepository.CountAll(filters)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	facets, err := portfolioRepository.GetFacets(filters, maxFacetValues)
+	if err != nil {
+		return nil, err
 	}
 
-	return map[string]interface{}{"list": res, "cursor": nextCursor}, nil
+	return schemas.NewPortfolioPage(*res, facets, page, total), nil
 }
 
-func (s *servicePortfolio) GetPortfolio(slug string) (interface{}, error) {
-	portfolioRepository := repositories.NewPortfolioRepository(s.db)
+func (s *servicePortfolio) GetPortfolio(ctx context.Context, slug string, query *schemas.SchemaPortfolioQuery) (interface{}, error) {
+	portfolioRepository := repositories.NewPortfolioRepository(s.db.WithContext(ctx))
+
+	if !query.IsEmpty() {
+		return portfolioRepository.GetPortfolioWith(slug, query)
+	}
 
 	res, err := portfolioRepository.GetPortfolio(slug)
 	if err != nil {
@@ -52,37 +75,42 @@ func (s *servicePortfolio) GetPortfolio(slug string) (interface{}, error) {
 	return res, nil
 }
 
-func (s *servicePortfolio) GetSubModule(slug string, module string) (interface{}, error) {
-	portfolioRepository := repositories.NewPortfolioRepository(s.db)
-
-	var res any
-	var err error
-	switch module {
-	case "educations":
-		res, err = portfolioRepository.GetEducations(slug)
-	case "work_experiences":
-		res, err = portfolioRepository.GetWorkExperiences(slug)
-	case "certifications":
-		res, err = portfolioRepository.GetCertifications(slug)
-	case "hackathons":
-		res, err = portfolioRepository.GetHackathons(slug)
-	case "works":
-		res, err = portfolioRepository.GetTechProjects(slug)
-	case "skills":
-		res, err = portfolioRepository.GetSkills(slug)
-	default:
-		return nil, errors.New("invalid module")
+func (s *servicePortfolio) GetSubModule(ctx context.Context, slug string, module string) (interface{}, error) {
+	portfolioRepository := repositories.NewPortfolioRepository(s.db.WithContext(ctx))
+
+	res, err := portfolioRepository.GetModule(slug, module, 0)
+	if err != nil {
+		return nil, err
 	}
 
+	return res, nil
+}
+
+// GetSimilar suggests active portfolios like the portfolio slug.
+func (s *servicePortfolio) GetSimilar(ctx context.Context, slug string, limit int) ([]schemas.SelectSimilarPortfolio, error) {
+	portfolioRepository := repositories.NewPortfolioRepository(s.db.WithContext(ctx))
+
+	userId, err := portfolioRepository.GetIdBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPortfolioNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	res, err := portfolioRepository.GetSimilar(userId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		res = []schemas.SelectSimilarPortfolio{}
+	}
 	return res, nil
 }
 
-func (s *servicePortfolio) GetUserPortfolio(userId string) (interface{}, error) {
-	portfolioRepository := repositories.NewPortfolioRepository(s.db)
+func (s *servicePortfolio) GetUserPortfolio(ctx context.Context, userId string) (interface{}, error) {
+	portfolioRepository := repositories.NewPortfolioRepository(s.db.WithContext(ctx))
 
 	res, err := portfolioRepository.GetUserPortfolio(userId)
 	if err != nil {
@@ -92,8 +120,8 @@ func (s *servicePortfolio) GetUserPortfolio(userId string) (interface{}, error)
 	return res, nil
 }
 
-func (s *servicePortfolio) GetSkills(userId string) (any, error) {
-	repository := repositories.NewRepositorySkill(s.db)
+func (s *servicePortfolio) GetSkills(ctx context.Context, userId string) (any, error) {
+	repository := repositories.NewRepositorySkill(s.db.WithContext(ctx))
 
 	res, err := repository.GetUserSkills(userId)
 	if err != nil {
@@ -103,46 +131,95 @@ func (s *servicePortfolio) GetSkills(userId string) (any, error) {
 	return res, nil
 }
 
-func (s *servicePortfolio) UpsertSkills(userId string, data *schemas.SchemaSkills) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *servicePortfolio) UpsertSkills(ctx context.Context, userId string, data *schemas.SchemaSkills) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewUserRepository(tx)
 
-	if err := repository.UpsertSkills(userId, data); err != nil {
+		if err := repository.UpsertSkills(userId, data); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "skills", "updated")
+	})
+
+	if err != nil {
 		return err
 	}
+
+	s.cache.Invalidate(cache.UserTag(userId), cache.SkillsTag)
 	return nil
 }
 
-func (s *servicePortfolio) UpsertResume(userId string, url *string) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *servicePortfolio) UpsertResume(ctx context.Context, userId string, url *string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewUserRepository(tx)
+
+		if err := repository.UpsertResume(userId, url); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "resume", "updated")
+	})
 
-	if err := repository.UpsertResume(userId, url); err != nil {
+	if err != nil {
 		return err
 	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *servicePortfolio) UpdateProfileAttachment(userId string, data *schemas.SchemaProfileAttachment) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *servicePortfolio) UpdateProfileAttachment(ctx context.Context, userId string, data *schemas.SchemaProfileAttachment) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewUserRepository(tx)
+
+		if err := repository.UpdateProfileAttachment(userId, data.Module, &data.Url); err != nil {
+			return err
+		}
 
-	if err := repository.UpdateProfileAttachment(userId, data.Module, &data.Url); err != nil {
+		return enqueueModuleUpdated(tx, userId, data.Module, "updated")
+	})
+
+	if err != nil {
 		return err
 	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *servicePortfolio) UpdateStatus(userId string, status string) error {
-	repository := repositories.NewUserRepository(s.db)
-
+func (s *servicePortfolio) UpdateStatus(ctx context.Context, userId string, status string) error {
+	var newStatus models.PortfolioStatus
 	switch status {
 	case "publish":
-		return repository.UpdateStatus(userId, "ACTIVE")
+		newStatus = models.Active
 	case "takedown":
-		return repository.UpdateStatus(userId, "IN_ACTIVE")
+		newStatus = models.InActive
 	default:
 		return errors.New("invalid status")
 	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewUserRepository(tx)
+
+		if err := repository.UpdateStatus(userId, newStatus); err != nil {
+			return err
+		}
+
+		return enqueueWebhookEvent(tx, userId, models.WebhookEventPortfolioStatusChanged, map[string]any{
+			"user_id": userId,
+			"status":  newStatus,
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
+	return nil
 }
 
-func NewPortfolioService(db *gorm.DB) *servicePortfolio {
-	return &servicePortfolio{db: db}
+func NewPortfolioService(db *gorm.DB, store cache.Store) *servicePortfolio {
+	return &servicePortfolio{db: db, cache: store}
 }
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserBanned     = errors.New("user is banned")
+	ErrSessionRevoked = errors.New("session has been revoked")
+	ErrUserNotBanned  = errors.New("user is not banned")
+	ErrInvalidUserId  = errors.New("invalid user id")
+	ErrUserNotFound   = errors.New("user not found")
+)
+
+// revocationCacheSize bounds the number of tokens whose status is cached.
+const revocationCacheSize = 10000
+
+// Cached token statuses.
+var (
+	statusValid   = []byte("valid")
+	statusBanned  = []byte("banned")
+	statusRevoked = []byte("revoked")
+)
+
+type ServiceRevocation interface {
+	Check(ctx context.Context, claims *config.AccessTokenClaims) error
+	CheckBanned(ctx context.Context, userId string) error
+	RevokeSession(ctx context.Context, userId string, sessionId string) error
+	RevokeSessions(ctx context.Context, userId string, data *schemas.SchemaRevokeSessions) error
+	Ban(ctx context.Context, adminId string, userId string, data *schemas.SchemaBanUser) error
+	Unban(ctx context.Context, userId string) error
+}
+
+type serviceRevocation struct {
+	db     *gorm.DB
+	config *config.JWTConfiguration
+	cache  cache.Store
+}
+
+// Check fails with ErrUserBanned or ErrSessionRevoked for tokens that are
+// validly signed but no longer honoured. Results are cached for
+// RevocationCacheTTL.
+func (s *serviceRevocation) Check(ctx context.Context, claims *config.AccessTokenClaims) error {
+	key := strings.Join([]string{"token", claims.Subject, claims.SessionId, claims.Id, strconv.FormatInt(claims.IssuedAt, 10)}, ":")
+
+	status, ok := s.cache.Get(key)
+	if !ok {
+		repository := repositories.NewRevocationRepository(s.db.WithContext(ctx))
+
+		banned, revoked, err := repository.Status(claims.Subject, claims.SessionId, claims.Id, time.Unix(claims.IssuedAt, 0))
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case banned:
+			status = statusBanned
+		case revoked:
+			status = statusRevoked
+		default:
+			status = statusValid
+		}
+		s.cache.Set(key, status, []string{cache.UserTag(claims.Subject)}, s.config.RevocationCacheTTL)
+	}
+
+	return statusError(status)
+}
+
+// CheckBanned is Check for credentials that aren't tied to a session, such
+// as personal access tokens and passwords.
+func (s *serviceRevocation) CheckBanned(ctx context.Context, userId string) error {
+	key := "banned:" + userId
+
+	status, ok := s.cache.Get(key)
+	if !ok {
+		repository := repositories.NewRevocationRepository(s.db.WithContext(ctx))
+
+		banned, err := repository.IsBanned(userId)
+		if err != nil {
+			return err
+		}
+
+		status = statusValid
+		if banned {
+			status = statusBanned
+		}
+		s.cache.Set(key, status, []string{cache.UserTag(userId)}, s.config.RevocationCacheTTL)
+	}
+
+	return statusError(status)
+}
+
+func (s *serviceRevocation) RevokeSession(ctx context.Context, userId string, sessionId string) error {
+	return s.RevokeSessions(ctx, userId, &schemas.SchemaRevokeSessions{SessionId: sessionId})
+}
+
+// RevokeSessions revokes a single session or token, or every token issued to
+// the user so far when neither is given.
+func (s *serviceRevocation) RevokeSessions(ctx context.Context, userId string, data *schemas.SchemaRevokeSessions) error {
+	userIdUUID, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.config.RevocationRetention)
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewRevocationRepository(tx)
+
+		if data.SessionId != "" {
+			if err := repository.Revoke(models.RevokeSession, data.SessionId, &expiresAt); err != nil {
+				return err
+			}
+			if sessionId, err := uuid.Parse(data.SessionId); err == nil {
+				if err := repositories.NewAuthRepository(tx).DeleteSession(sessionId); err != nil {
+					return err
+				}
+			}
+		}
+
+		if data.JTI != "" {
+			if err := repository.Revoke(models.RevokeJTI, data.JTI, &expiresAt); err != nil {
+				return err
+			}
+		}
+
+		if data.SessionId == "" && data.JTI == "" {
+			if err := repository.Revoke(models.RevokeUser, userId, nil); err != nil {
+				return err
+			}
+			_, err := repositories.NewAuthRepository(tx).DeleteUserSessions(userIdUUID, nil)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
+	return nil
+}
+
+// Ban bans the user and revokes all their sessions.
+func (s *serviceRevocation) Ban(ctx context.Context, adminId string, userId string, data *schemas.SchemaBanUser) error {
+	userIdUUID, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	ban := models.UserBan{
+		UserId:      userIdUUID,
+		BannedUntil: data.BannedUntil,
+	}
+	if data.Reason != "" {
+		ban.Reason = &data.Reason
+	}
+	if adminIdUUID, err := uuid.Parse(adminId); err == nil {
+		ban.CreatedBy = &adminIdUUID
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := repositories.NewRevocationRepository(tx).Ban(&ban); err != nil {
+			return err
+		}
+
+		if err := repositories.NewRevocationRepository(tx).Revoke(models.RevokeUser, userId, nil); err != nil {
+			return err
+		}
+
+		_, err := repositories.NewAuthRepository(tx).DeleteUserSessions(userIdUUID, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
+	return nil
+}
+
+func (s *serviceRevocation) Unban(ctx context.Context, userId string) error {
+	userIdUUID, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	repository := repositories.NewRevocationRepository(s.db.WithContext(ctx))
+
+	deleted, err := repository.Unban(userIdUUID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrUserNotBanned
+	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
+	return nil
+}
+
+// findUser parses userId, failing with ErrInvalidUserId when it isn't a UUID
+// and with ErrUserNotFound when there is no such user.
+func (s *serviceRevocation) findUser(ctx context.Context, userId string) (uuid.UUID, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return uuid.Nil, ErrInvalidUserId
+	}
+
+	if _, err := repositories.NewAuthRepository(s.db.WithContext(ctx)).GetUserById(userIdUUID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrUserNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	return userIdUUID, nil
+}
+
+func statusError(status []byte) error {
+	switch string(status) {
+	case string(statusBanned):
+		return ErrUserBanned
+	case string(statusRevoked):
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+func NewRevocationService(db *gorm.DB, config *config.JWTConfiguration) *serviceRevocation {
+	return &serviceRevocation{
+		db:     db,
+		config: config,
+		cache:  cache.NewLRU(revocationCacheSize),
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+type ServiceSearch interface {
+	Search(ctx context.Context, query schemas.SearchQuery) (*schemas.SearchResults, error)
+}
+
+type serviceSearch struct {
+	db *gorm.DB
+}
+
+func (s *serviceSearch) Search(ctx context.Context, query schemas.SearchQuery) (*schemas.SearchResults, error) {
+	searchRepository := repositories.NewSearchRepository(s.db.WithContext(ctx))
+
+	results, facets, err := searchRepository.Search(query.Q, query.Type, query.Cursor, query.FetchLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	return schemas.NewSearchResults(results, facets, query), nil
+}
+
+func NewSearchService(db *gorm.DB) *serviceSearch {
+	return &serviceSearch{
+		db: db,
+	}
+}
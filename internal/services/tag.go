@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagAliasNotFound = errors.New("tag alias not found")
+	ErrTagExists        = errors.New("tag or alias already exists")
+	ErrTagMergeSelf     = errors.New("tag merged into itself")
+)
+
+// TagDeprecatedError reports a deprecated tag added to a blog.
+type TagDeprecatedError struct {
+	Name string
+}
+
+func (e *TagDeprecatedError) Error() string {
+	return "tag " + e.Name + " is deprecated"
+}
+
+type ServiceTag interface {
+	GetTag(ctx context.Context, userId *string, name string, sort string, page schemas.PageQuery) (*schemas.TagPage, error)
+	GetPopular(ctx context.Context, limit int) ([]schemas.SelectTag, error)
+	GetFollowed(ctx context.Context, userId string, page schemas.PageQuery) (*schemas.Page[schemas.SelectTag], error)
+	Follow(ctx context.Context, userId string, name string) error
+	Unfollow(ctx context.Context, userId string, name string) error
+	Update(ctx context.Context, name string, data *schemas.SchemaUpdateTag) (*schemas.SelectTag, error)
+	Merge(ctx context.Context, name string, data *schemas.SchemaMergeTag) (*schemas.SelectTag, error)
+	AddAlias(ctx context.Context, name string, data *schemas.SchemaTagAlias) (*schemas.SelectTag, error)
+	RemoveAlias(ctx context.Context, name string, alias string) error
+}
+
+type serviceTag struct {
+	db    *gorm.DB
+	cache cache.Store
+}
+
+// GetTag returns the tag named name, or having it as an alias, with a page of
+// the published blogs carrying it.
+func (s *serviceTag) GetTag(ctx context.Context, userId *string, name string, sort string, page schemas.PageQuery) (*schemas.TagPage, error) {
+	tagRepository := repositories.NewTagRepository(s.db.WithContext(ctx))
+	blogRepository := repositories.NewBlogRepository(s.db.WithContext(ctx))
+
+	tag, err := s.getByName(tagRepository, name)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tagRepository.GetTag(userId, tag.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	blogs, err := blogRepository.GetAll(userId, nil, &tag.ID, sort, page.Cursor, page.FetchLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if page.IncludeTotal {
+		count, err := blogRepository.CountAll(nil, &tag.ID)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return &schemas.TagPage{Tag: res, Page: schemas.NewPage(*blogs, page, total)}, nil
+}
+
+func (s *serviceTag) GetPopular(ctx context.Context, limit int) ([]schemas.SelectTag, error) {
+	res, err := repositories.NewTagRepository(s.db.WithContext(ctx)).GetPopular(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		res = []schemas.SelectTag{}
+	}
+	return res, nil
+}
+
+func (s *serviceTag) GetFollowed(ctx context.Context, userId string, page schemas.PageQuery) (*schemas.Page[schemas.SelectTag], error) {
+	tagRepository := repositories.NewTagRepository(s.db.WithContext(ctx))
+
+	res, err := tagRepository.GetFollowed(userId, page.Cursor, page.FetchLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if page.IncludeTotal {
+		count, err := tagRepository.CountFollowed(userId)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return schemas.NewPage(res, page, total), nil
+}
+
+func (s *serviceTag) Follow(ctx context.Context, userId string, name string) error {
+	tagRepository := repositories.NewTagRepository(s.db.WithContext(ctx))
+
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return errors.New("failed to parse user id")
+	}
+
+	tag, err := s.getByName(tagRepository, name)
+	if err != nil {
+		return err
+	}
+
+	return tagRepository.Follow(userUUID, tag.ID)
+}
+
+func (s *serviceTag) Unfollow(ctx context.Context, userId string, name string) error {
+	tagRepository := repositories.NewTagRepository(s.db.WithContext(ctx))
+
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return errors.New("failed to parse user id")
+	}
+
+	tag, err := s.getByName(tagRepository, name)
+	if err != nil {
+		return err
+	}
+
+	return tagRepository.Unfollow(userUUID, tag.ID)
+}
+
+// Update renames, describes or deprecates the tag. A renamed tag keeps its
+// old name as an alias.
+func (s *serviceTag) Update(ctx context.Context, name string, data *schemas.SchemaUpdateTag) (*schemas.SelectTag, error) {
+	var tag *models.Tag
+	var owners []string
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagRepository := repositories.NewTagRepository(tx)
+
+		var err error
+		tag, err = s.getByName(tagRepository, name)
+		if err != nil {
+			return err
+		}
+
+		values := map[string]any{}
+
+		if data.Name != nil {
+			newName := schemas.NormalizeTag(*data.Name)
+			oldName := schemas.NormalizeTag(tag.Name)
+
+			if newName != oldName {
+				existing, err := tagRepository.GetByName(newName)
+				if err == nil && existing.ID != tag.ID {
+					return ErrTagExists
+				} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+
+				// The new name may have been an alias of the tag.
+				if _, err := tagRepository.RemoveAlias(tag.ID, newName); err != nil {
+					return err
+				}
+				if err := tagRepository.AddAlias(tag.ID, oldName); err != nil {
+					return err
+				}
+
+				if owners, err = tagRepository.GetBlogOwnerIds(tag.ID); err != nil {
+					return err
+				}
+			}
+			values["name"] = newName
+		}
+
+		if data.Description != nil {
+			values["description"] = *data.Description
+		}
+
+		if data.Deprecated != nil {
+			if !*data.Deprecated {
+				values["deprecated_at"] = nil
+			} else if tag.DeprecatedAt == nil {
+				values["deprecated_at"] = time.Now()
+			}
+		}
+
+		if len(values) == 0 {
+			return nil
+		}
+		return tagRepository.Update(tag.ID, values)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateOwners(owners)
+	return repositories.NewTagRepository(s.db.WithContext(ctx)).GetTag(nil, tag.ID)
+}
+
+// Merge moves the blogs, followers and aliases of the tag into data.Into and
+// deletes it, keeping its name as an alias of data.Into.
+func (s *serviceTag) Merge(ctx context.Context, name string, data *schemas.SchemaMergeTag) (*schemas.SelectTag, error) {
+	var target *models.Tag
+	var owners []string
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagRepository := repositories.NewTagRepository(tx)
+
+		source, err := s.getByName(tagRepository, name)
+		if err != nil {
+			return err
+		}
+
+		target, err = s.getByName(tagRepository, data.Into)
+		if err != nil {
+			return err
+		}
+
+		if source.ID == target.ID {
+			return ErrTagMergeSelf
+		}
+
+		if owners, err = tagRepository.GetBlogOwnerIds(source.ID); err != nil {
+			return err
+		}
+
+		return tagRepository.Merge(source.ID, target.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateOwners(owners)
+	return repositories.NewTagRepository(s.db.WithContext(ctx)).GetTag(nil, target.ID)
+}
+
+func (s *serviceTag) AddAlias(ctx context.Context, name string, data *schemas.SchemaTagAlias) (*schemas.SelectTag, error) {
+	var tag *models.Tag
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagRepository := repositories.NewTagRepository(tx)
+
+		var err error
+		tag, err = s.getByName(tagRepository, name)
+		if err != nil {
+			return err
+		}
+
+		alias := schemas.NormalizeTag(data.Name)
+		if _, err := tagRepository.GetByName(alias); err == nil {
+			return ErrTagExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tagRepository.AddAlias(tag.ID, alias)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repositories.NewTagRepository(s.db.WithContext(ctx)).GetTag(nil, tag.ID)
+}
+
+func (s *serviceTag) RemoveAlias(ctx context.Context, name string, alias string) error {
+	tagRepository := repositories.NewTagRepository(s.db.WithContext(ctx))
+
+	tag, err := s.getByName(tagRepository, name)
+	if err != nil {
+		return err
+	}
+
+	removed, err := tagRepository.RemoveAlias(tag.ID, schemas.NormalizeTag(alias))
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrTagAliasNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceTag) getByName(tagRepository repositories.RepositoryTag, name string) (*models.Tag, error) {
+	tag, err := tagRepository.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+
+	return tag, err
+}
+
+// invalidateOwners drops the cached pages of users whose blogs were retagged.
+func (s *serviceTag) invalidateOwners(userIds []string) {
+	for _, userId := range userIds {
+		s.cache.Invalidate(cache.UserTag(userId))
+	}
+}
+
+// resolveBlogTags returns the tags named names, refusing deprecated tags the
+// blog doesn't already carry. current holds the ids of the tags it carries.
+func resolveBlogTags(tx *gorm.DB, names []string, current []uint) (*models.Tags, error) {
+	var normalized []string
+	for _, name := range names {
+		if name = schemas.NormalizeTag(name); name != "" && !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+
+	tags, err := repositories.NewTagRepository(tx).FindOrCreate(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range *tags {
+		if tag.DeprecatedAt != nil && !slices.Contains(current, tag.ID) {
+			return nil, &TagDeprecatedError{Name: tag.Name}
+		}
+	}
+
+	return tags, nil
+}
+
+func NewTagService(db *gorm.DB, cache cache.Store) *serviceTag {
+	return &serviceTag{
+		db:    db,
+		cache: cache,
+	}
+}
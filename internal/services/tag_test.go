@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/lib/pq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestTags holds go, with the alias golang, rust and the deprecated
+// perl. Blog 10 of alice is tagged go and rust, blog 11 of bob rust.
+func newTestTags(t *testing.T) (*gorm.DB, *fakeTagState) {
+	db, state := openFakeTagDB(t)
+
+	deprecatedAt := time.Now()
+	state.tags = map[uint]*fakeTag{
+		1: {name: "Go"},
+		2: {name: "rust"},
+		3: {name: "perl", deprecatedAt: &deprecatedAt},
+	}
+	state.nextId = 4
+	state.aliases = map[string]uint{"golang": 1}
+	state.blogTags = [][2]uint{{10, 1}, {10, 2}, {11, 2}}
+	state.blogOwners = map[uint]string{10: "alice", 11: "bob"}
+	state.follows = map[[2]string]bool{{"carol", "1"}: true, {"carol", "2"}: true, {"dave", "2"}: true}
+
+	return db, state
+}
+
+func TestTagMerge(t *testing.T) {
+	db, state := newTestTags(t)
+	store := cache.NewLRU(10)
+	for _, user := range []string{"alice", "bob", "erin"} {
+		store.Set(user, []byte("page"), []string{cache.UserTag(user)}, 0)
+	}
+
+	// Tags are looked up ignoring case and through their aliases.
+	tag, err := NewTagService(db, store).Merge(context.Background(), "Rust", &schemas.SchemaMergeTag{Into: "golang"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tag.ID != 1 || tag.Name != "Go" || !slices.Equal(tag.Aliases, []string{"golang", "rust"}) {
+		t.Errorf("Merge() = %+v, want go with the aliases golang and rust", tag)
+	}
+
+	if _, ok := state.tags[2]; ok {
+		t.Error("merged tag kept")
+	}
+	if want := [][2]uint{{10, 1}, {11, 1}}; !slices.Equal(state.sortedBlogTags(), want) {
+		t.Errorf("blog tags = %v, want %v", state.sortedBlogTags(), want)
+	}
+	if want := map[[2]string]bool{{"carol", "1"}: true, {"dave", "1"}: true}; !maps.Equal(state.follows, want) {
+		t.Errorf("follows = %v, want %v", state.follows, want)
+	}
+
+	// The pages of both authors listed the merged tag.
+	for user, want := range map[string]bool{"alice": false, "bob": false, "erin": true} {
+		if _, ok := store.Get(user); ok != want {
+			t.Errorf("page of %s cached = %v, want %v", user, ok, want)
+		}
+	}
+
+	// The merged name now resolves to the tag it was merged into.
+	tags, err := resolveBlogTags(db, []string{"RUST"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*tags) != 1 || (*tags)[0].ID != 1 {
+		t.Errorf("resolveBlogTags(RUST) = %+v, want go", *tags)
+	}
+}
+
+func TestTagMergeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		into string
+		want error
+	}{
+		{name: "golang", into: "go", want: ErrTagMergeSelf},
+		{name: "python", into: "go", want: ErrTagNotFound},
+		{name: "rust", into: "python", want: ErrTagNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" into "+tt.into, func(t *testing.T) {
+			db, state := newTestTags(t)
+
+			_, err := NewTagService(db, cache.NewNoop()).Merge(context.Background(), tt.name, &schemas.SchemaMergeTag{Into: tt.into})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Merge() error = %v, want %v", err, tt.want)
+			}
+			if len(state.tags) != 3 || len(state.blogTags) != 3 {
+				t.Error("tags changed by a failed merge")
+			}
+		})
+	}
+}
+
+func TestResolveBlogTags(t *testing.T) {
+	db, state := newTestTags(t)
+
+	tags, err := resolveBlogTags(db, []string{"GoLang", " go ", "Rust", "Web   Dev", "web dev", ""}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, tag := range *tags {
+		got = append(got, fmt.Sprintf("%d %s", tag.ID, tag.Name))
+	}
+	if want := []string{"1 Go", "2 rust", "4 web dev"}; !slices.Equal(got, want) {
+		t.Errorf("resolveBlogTags() = %v, want %v", got, want)
+	}
+	if len(state.tags) != 4 {
+		t.Errorf("%d tags, want the new one created once", len(state.tags))
+	}
+}
+
+func TestResolveBlogTagsDeprecated(t *testing.T) {
+	db, _ := newTestTags(t)
+
+	_, err := resolveBlogTags(db, []string{"go", "Perl"}, nil)
+	var deprecated *TagDeprecatedError
+	if !errors.As(err, &deprecated) || deprecated.Name != "perl" {
+		t.Errorf("resolveBlogTags() error = %v, want perl deprecated", err)
+	}
+
+	// Blogs already carrying it keep it.
+	tags, err := resolveBlogTags(db, []string{"go", "Perl"}, []uint{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*tags) != 2 || (*tags)[1].ID != 3 {
+		t.Errorf("resolveBlogTags() = %+v, want go and perl", *tags)
+	}
+}
+
+var (
+	fakeTagRegister sync.Once
+	fakeTagStates   sync.Map
+)
+
+type fakeTag struct {
+	name         string
+	deprecatedAt *time.Time
+}
+
+// fakeTagState holds the tables the tag repository uses. Deleting a tag
+// cascades as the foreign keys do.
+type fakeTagState struct {
+	mu         sync.Mutex
+	tags       map[uint]*fakeTag
+	nextId     uint
+	aliases    map[string]uint
+	blogTags   [][2]uint
+	blogOwners map[uint]string
+	// follows are keyed by user and tag id.
+	follows map[[2]string]bool
+}
+
+func (s *fakeTagState) sortedBlogTags() [][2]uint {
+	sorted := slices.Clone(s.blogTags)
+	slices.SortFunc(sorted, func(a, b [2]uint) int {
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		return int(a[1]) - int(b[1])
+	})
+	return sorted
+}
+
+func (s *fakeTagState) hasBlogTag(blogTag [2]uint) bool {
+	return slices.Contains(s.blogTags, blogTag)
+}
+
+func (s *fakeTagState) tagRow(id uint) []driver.Value {
+	tag := s.tags[id]
+	var deprecatedAt driver.Value
+	if tag.deprecatedAt != nil {
+		deprecatedAt = *tag.deprecatedAt
+	}
+	return []driver.Value{int64(id), tag.name, []byte("{}"), deprecatedAt}
+}
+
+func openFakeTagDB(t *testing.T) (*gorm.DB, *fakeTagState) {
+	fakeTagRegister.Do(func() { sql.Register("tagfake", fakeTagDriver{}) })
+
+	state := &fakeTagState{}
+	fakeTagStates.Store(t.Name(), state)
+	t.Cleanup(func() { fakeTagStates.Delete(t.Name()) })
+
+	sqlDB, err := sql.Open("tagfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, state
+}
+
+type fakeTagDriver struct{}
+
+func (fakeTagDriver) Open(name string) (driver.Conn, error) {
+	state, ok := fakeTagStates.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("no fake database %q", name)
+	}
+	return &fakeTagConn{state: state.(*fakeTagState)}, nil
+}
+
+type fakeTagConn struct {
+	state *fakeTagState
+}
+
+func (c *fakeTagConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *fakeTagConn) Close() error { return nil }
+
+func (c *fakeTagConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeTagConn) Commit() error { return nil }
+
+func (c *fakeTagConn) Rollback() error { return nil }
+
+func argUint(arg driver.NamedValue) uint {
+	return uint(arg.Value.(int64))
+}
+
+// ExecContext runs the statements of a merge, where the target is bound
+// before the source.
+func (c *fakeTagConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "DELETE FROM tags WHERE id ="):
+		source := argUint(args[0])
+		delete(s.tags, source)
+		s.blogTags = slices.DeleteFunc(s.blogTags, func(blogTag [2]uint) bool { return blogTag[1] == source })
+		maps.DeleteFunc(s.aliases, func(_ string, tagId uint) bool { return tagId == source })
+		maps.DeleteFunc(s.follows, func(follow [2]string, _ bool) bool { return follow[1] == fmt.Sprint(source) })
+		return driver.RowsAffected(1), nil
+	}
+
+	target, source := argUint(args[0]), argUint(args[1])
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO blog_tags"):
+		for _, blogTag := range slices.Clone(s.blogTags) {
+			if moved := [2]uint{blogTag[0], target}; blogTag[1] == source && !s.hasBlogTag(moved) {
+				s.blogTags = append(s.blogTags, moved)
+			}
+		}
+	case strings.HasPrefix(query, "INSERT INTO tag_follows"):
+		for follow := range maps.Clone(s.follows) {
+			if follow[1] == fmt.Sprint(source) {
+				s.follows[[2]string{follow[0], fmt.Sprint(target)}] = true
+			}
+		}
+	case strings.HasPrefix(query, "UPDATE tag_aliases SET tag_id ="):
+		for name, tagId := range s.aliases {
+			if tagId == source {
+				s.aliases[name] = target
+			}
+		}
+	case strings.HasPrefix(query, "INSERT INTO tag_aliases (name, tag_id) SELECT lower(name)"):
+		s.aliases[strings.ToLower(s.tags[source].name)] = target
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+var (
+	tagColumns       = []string{"id", "name", "attributes", "deprecated_at"}
+	insertTagPattern = regexp.MustCompile(`^INSERT INTO "tags" \(([^)]*)\)`)
+	existingPattern  = regexp.MustCompile(`lower\(name\) IN \(([^)]*)\)`)
+)
+
+func (c *fakeTagConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	// GetByName
+	case strings.HasPrefix(query, `SELECT * FROM "tags" WHERE (lower(name) = $1 OR id IN (SELECT tag_id FROM tag_aliases WHERE name = $2))`):
+		name, alias := args[0].Value.(string), args[1].Value.(string)
+		rows := &fakeTagRows{columns: tagColumns}
+		for _, id := range slices.Sorted(maps.Keys(s.tags)) {
+			if strings.ToLower(s.tags[id].name) == name || s.aliases[alias] == id {
+				rows.values = append(rows.values, s.tagRow(id))
+				break
+			}
+		}
+		return rows, nil
+
+	// FindOrCreate
+	case strings.HasPrefix(query, `SELECT * FROM "tag_aliases" WHERE name IN`):
+		rows := &fakeTagRows{columns: []string{"name", "tag_id"}}
+		for _, arg := range args {
+			if tagId, ok := s.aliases[arg.Value.(string)]; ok {
+				rows.values = append(rows.values, []driver.Value{arg.Value, int64(tagId)})
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, `SELECT * FROM "tags" WHERE (lower(name) IN`):
+		names := len(strings.Split(existingPattern.FindStringSubmatch(query)[1], ","))
+		rows := &fakeTagRows{columns: tagColumns}
+		for _, id := range slices.Sorted(maps.Keys(s.tags)) {
+			for i, arg := range args {
+				if (i < names && strings.ToLower(s.tags[id].name) == arg.Value.(string)) || (i >= names && argUint(arg) == id) {
+					rows.values = append(rows.values, s.tagRow(id))
+					break
+				}
+			}
+		}
+		return rows, nil
+
+	case strings.HasPrefix(query, `INSERT INTO "tags"`):
+		columns := strings.Split(insertTagPattern.FindStringSubmatch(query)[1], ",")
+		name := args[slices.Index(columns, `"name"`)].Value.(string)
+		id := s.nextId
+		s.nextId++
+		s.tags[id] = &fakeTag{name: name}
+		return &fakeTagRows{columns: []string{"id"}, values: [][]driver.Value{{int64(id)}}}, nil
+
+	// GetBlogOwnerIds
+	case strings.HasPrefix(query, "SELECT DISTINCT blogs.user_id::text"):
+		owners := map[string]bool{}
+		for _, blogTag := range s.blogTags {
+			if blogTag[1] == argUint(args[0]) {
+				owners[s.blogOwners[blogTag[0]]] = true
+			}
+		}
+		rows := &fakeTagRows{columns: []string{"user_id"}}
+		for _, owner := range slices.Sorted(maps.Keys(owners)) {
+			rows.values = append(rows.values, []driver.Value{owner})
+		}
+		return rows, nil
+
+	// GetTag
+	case strings.Contains(query, "WHERE tags.id = $"):
+		id := argUint(args[len(args)-1])
+		rows := &fakeTagRows{columns: []string{"id", "name", "aliases"}}
+		if _, ok := s.tags[id]; ok {
+			var aliases pq.StringArray
+			for _, name := range slices.Sorted(maps.Keys(s.aliases)) {
+				if s.aliases[name] == id {
+					aliases = append(aliases, name)
+				}
+			}
+			value, _ := aliases.Value()
+			rows.values = append(rows.values, []driver.Value{int64(id), s.tags[id].name, value})
+		}
+		return rows, nil
+	}
+
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type fakeTagRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeTagRows) Columns() []string { return r.columns }
+
+func (r *fakeTagRows) Close() error { return nil }
+
+func (r *fakeTagRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
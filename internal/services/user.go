@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/pkg"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -18,24 +20,26 @@ import (
 )
 
 type ServiceUser interface {
-	GetProfile(userId string) (*models.UserProfile, error)
-	UpsertProfile(userId string, profile *schemas.SchemaProfileBasic) error
-	ProfileSetup(userId string, profile *schemas.SchemaProfileBasic) error
+	GetProfile(ctx context.Context, userId string) (*models.UserProfile, error)
+	UpsertProfile(ctx context.Context, userId string, profile *schemas.SchemaProfileBasic) error
+	ProfileSetup(ctx context.Context, userId string, profile *schemas.SchemaProfileBasic) error
+	UpdatePrivacy(ctx context.Context, userId string, data *schemas.SchemaProfilePrivacy) error
 	GetPostPresignedURLs(ctx context.Context, files []schemas.File) ([]any, error)
-	GetFollowers(userId string, cursor int, limit int) (*[]schemas.SelectFollowers, error)
-	GetFollowing(userId string, cursor int, limit int) (*[]schemas.SelectFollowing, error)
-	FollowUser(userId string, followingUserId string) error
-	UnfollowUser(userId string, followingUserId string) error
-	FollowStatus(userId string, followingUserId string) (any, error)
+	GetFollowers(ctx context.Context, userId string, page schemas.PageQuery) (*schemas.Page[schemas.SelectFollowers], error)
+	GetFollowing(ctx context.Context, userId string, page schemas.PageQuery) (*schemas.Page[schemas.SelectFollowing], error)
+	FollowUser(ctx context.Context, userId string, followingUserId string) error
+	UnfollowUser(ctx context.Context, userId string, followingUserId string) error
+	FollowStatus(ctx context.Context, userId string, followingUserId string) (any, error)
 }
 
 type serviceUser struct {
 	db        *gorm.DB
 	presigner *pkg.Presigner
+	cache     cache.Store
 }
 
-func (s *serviceUser) GetProfile(userId string) (*models.UserProfile, error) {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) GetProfile(ctx context.Context, userId string) (*models.UserProfile, error) {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := repository.GetProfile(userId)
 	if err != nil {
@@ -44,24 +48,50 @@ func (s *serviceUser) GetProfile(userId string) (*models.UserProfile, error) {
 	return res, nil
 }
 
-func (s *serviceUser) ProfileSetup(userId string, profile *schemas.SchemaProfileBasic) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) ProfileSetup(ctx context.Context, userId string, profile *schemas.SchemaProfileBasic) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewUserRepository(tx)
 
-	if err := repository.ProfileSetup(userId, profile); err != nil {
+		if err := repository.ProfileSetup(userId, profile); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "profile", "updated")
+	})
+
+	if err != nil {
 		return err
 	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceUser) UpsertProfile(userId string, profile *schemas.SchemaProfileBasic) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) UpsertProfile(ctx context.Context, userId string, profile *schemas.SchemaProfileBasic) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repository := repositories.NewUserRepository(tx)
+
+		if err := repository.UpsertProfile(userId, profile); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "profile", "updated")
+	})
 
-	if err := repository.UpsertProfile(userId, profile); err != nil {
+	if err != nil {
 		return err
 	}
+
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
+func (s *serviceUser) UpdatePrivacy(ctx context.Context, userId string, data *schemas.SchemaProfilePrivacy) error {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
+
+	return repository.UpdatePrivacy(userId, data)
+}
+
 func (s *serviceUser) GetPostPresignedURLs(ctx context.Context, files []schemas.File) ([]interface{}, error) {
 	var wg sync.WaitGroup
 
@@ -115,28 +145,48 @@ func (s *serviceUser) GetPostPresignedURLs(ctx context.Context, files []schemas.
 	return urls, nil
 }
 
-func (s *serviceUser) GetFollowers(userId string, cursor int, limit int) (*[]schemas.SelectFollowers, error) {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) GetFollowers(ctx context.Context, userId string, page schemas.PageQuery) (*schemas.Page[schemas.SelectFollowers], error) {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
-	res, err := repository.GetFollowers(userId, cursor, limit)
+	res, err := repository.GetFollowers(userId, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+
+	var total *int64
+	if page.IncludeTotal {
+		count, err := repository.CountFollowers(userId)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceUser) GetFollowing(userId string, cursor int, limit int) (*[]schemas.SelectFollowing, error) {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) GetFollowing(ctx context.Context, userId string, page schemas.PageQuery) (*schemas.Page[schemas.SelectFollowing], error) {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
-	res, err := repository.GetFollowing(userId, cursor, limit)
+	res, err := repository.GetFollowing(userId, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+
+	var total *int64
+	if page.IncludeTotal {
+		count, err := repository.CountFollowing(userId)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceUser) FollowUser(userId string, followingUserId string) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) FollowUser(ctx context.Context, userId string, followingUserId string) error {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -148,14 +198,27 @@ func (s *serviceUser) FollowUser(userId string, followingUserId string) error {
 		return err
 	}
 
-	if err := repository.FollowUser(userUUID, followingProfile.UserId); err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := repositories.NewUserRepository(tx).FollowUser(userUUID, followingProfile.UserId); err != nil {
+			return err
+		}
+
+		return enqueueWebhookEvent(tx, followingProfile.UserId.String(), models.WebhookEventFollowCreated, map[string]any{
+			"follower_id":  userId,
+			"following_id": followingProfile.UserId,
+		})
+	})
+
+	if err != nil {
 		return err
 	}
+
+	observability.Follows.Inc()
 	return nil
 }
 
-func (s *serviceUser) UnfollowUser(userId string, followingUserId string) error {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) UnfollowUser(ctx context.Context, userId string, followingUserId string) error {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -173,8 +236,8 @@ func (s *serviceUser) UnfollowUser(userId string, followingUserId string) error
 	return nil
 }
 
-func (s *serviceUser) FollowStatus(userId string, followingUserId string) (any, error) {
-	repository := repositories.NewUserRepository(s.db)
+func (s *serviceUser) FollowStatus(ctx context.Context, userId string, followingUserId string) (any, error) {
+	repository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -198,9 +261,10 @@ func (s *serviceUser) FollowStatus(userId string, followingUserId string) (any,
 	return map[string]any{"is_following": false}, nil
 
 }
-func NewUserService(db *gorm.DB, presigner *pkg.Presigner) *serviceUser {
+func NewUserService(db *gorm.DB, presigner *pkg.Presigner, store cache.Store) *serviceUser {
 	return &serviceUser{
 		db:        db,
 		presigner: presigner,
+		cache:     store,
 	}
 }
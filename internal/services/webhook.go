@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/jobs"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// WebhookSecretPrefix starts every generated webhook signing secret.
+const WebhookSecretPrefix = "whsec_"
+
+// maxWebhookEndpoints bounds the endpoints of a user, and so the fan-out of
+// a single event.
+const maxWebhookEndpoints = 10
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookLimitReached     = errors.New("webhook endpoint limit reached")
+)
+
+type ServiceWebhook interface {
+	GetAll(ctx context.Context, userId string) ([]models.WebhookEndpoint, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaWebhookEndpoint) (*schemas.SelectWebhookEndpointWithSecret, error)
+	Update(ctx context.Context, userId string, id string, data *schemas.SchemaWebhookEndpoint) (any, error)
+	Delete(ctx context.Context, userId string, id string) error
+	GetDeliveries(ctx context.Context, userId string, id string, page schemas.PageQuery) (*schemas.Page[models.WebhookDelivery], error)
+	Redeliver(ctx context.Context, userId string, id string, deliveryId string) (*models.WebhookDelivery, error)
+}
+
+type serviceWebhook struct {
+	db *gorm.DB
+}
+
+func (s *serviceWebhook) GetAll(ctx context.Context, userId string) ([]models.WebhookEndpoint, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, errors.New("failed to parse user id")
+	}
+
+	repository := repositories.NewWebhookRepository(s.db.WithContext(ctx))
+	return repository.GetAll(userIdUUID)
+}
+
+func (s *serviceWebhook) Create(ctx context.Context, userId string, data *schemas.SchemaWebhookEndpoint) (*schemas.SelectWebhookEndpointWithSecret, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, errors.New("failed to parse user id")
+	}
+
+	secret := data.Secret
+	if secret == "" {
+		random, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		secret = WebhookSecretPrefix + random
+	}
+
+	endpoint := models.WebhookEndpoint{
+		UserId:      userIdUUID,
+		URL:         data.URL,
+		Secret:      secret,
+		EventTypes:  data.EventTypes,
+		Active:      data.Active == nil || *data.Active,
+		Description: data.Description,
+	}
+
+	repository := repositories.NewWebhookRepository(s.db.WithContext(ctx))
+
+	count, err := repository.Count(userIdUUID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWebhookEndpoints {
+		return nil, ErrWebhookLimitReached
+	}
+
+	if err := repository.Create(&endpoint); err != nil {
+		return nil, err
+	}
+
+	return &schemas.SelectWebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// Update replaces the endpoint. The secret is only returned when it was
+// rotated.
+func (s *serviceWebhook) Update(ctx context.Context, userId string, id string, data *schemas.SchemaWebhookEndpoint) (any, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, errors.New("failed to parse user id")
+	}
+
+	endpointId, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	values := map[string]any{
+		"url":         data.URL,
+		"event_types": pq.StringArray(data.EventTypes),
+		"description": data.Description,
+	}
+	if data.Active != nil {
+		values["active"] = *data.Active
+	}
+	if data.Secret != "" {
+		values["secret"] = data.Secret
+	}
+
+	repository := repositories.NewWebhookRepository(s.db.WithContext(ctx))
+
+	endpoint, err := repository.Update(userIdUUID, uint(endpointId), values)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if data.Secret != "" {
+		return &schemas.SelectWebhookEndpointWithSecret{WebhookEndpoint: *endpoint, Secret: data.Secret}, nil
+	}
+
+	return endpoint, nil
+}
+
+func (s *serviceWebhook) Delete(ctx context.Context, userId string, id string) error {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return errors.New("failed to parse user id")
+	}
+
+	endpointId, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return ErrWebhookNotFound
+	}
+
+	repository := repositories.NewWebhookRepository(s.db.WithContext(ctx))
+	deleted, err := repository.Delete(userIdUUID, uint(endpointId))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceWebhook) GetDeliveries(ctx context.Context, userId string, id string, page schemas.PageQuery) (*schemas.Page[models.WebhookDelivery], error) {
+	repository := repositories.NewWebhookRepository(s.db.WithContext(ctx))
+
+	endpoint, err := s.getEndpoint(repository, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := repository.GetDeliveries(endpoint.ID, page.Cursor, page.FetchLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if page.IncludeTotal {
+		count, err := repository.CountDeliveries(endpoint.ID)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	return schemas.NewPage(res, page, total), nil
+}
+
+// Redeliver queues a new delivery of the same event, leaving the original
+// one in the log as it was.
+func (s *serviceWebhook) Redeliver(ctx context.Context, userId string, id string, deliveryId string) (*models.WebhookDelivery, error) {
+	repository := repositories.NewWebhookRepository(s.db.WithContext(ctx))
+
+	endpoint, err := s.getEndpoint(repository, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryIdInt, err := strconv.ParseUint(deliveryId, 10, 0)
+	if err != nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	original, err := repository.GetDelivery(endpoint.ID, uint(deliveryIdInt))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookDeliveryNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		EndpointId:    endpoint.ID,
+		EventId:       original.EventId,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}
+
+	if err := repository.CreateDelivery(&delivery); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (s *serviceWebhook) getEndpoint(repository repositories.RepositoryWebhook, userId string, id string) (*models.WebhookEndpoint, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, errors.New("failed to parse user id")
+	}
+
+	endpointId, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	endpoint, err := repository.Get(userIdUUID, uint(endpointId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// enqueueWebhookEvent queues the event for the endpoints of userId
+// subscribed to it. Pass the transaction of the change that caused the
+// event, so the event is sent only if the change is committed.
+func enqueueWebhookEvent(tx *gorm.DB, userId string, eventType string, data any) error {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return errors.New("failed to parse user id")
+	}
+
+	return jobs.Enqueue(tx, JobWebhookEvent, schemas.JobWebhookEvent{
+		UserId: userIdUUID,
+		Event: schemas.WebhookEvent{
+			ID:        uuid.New(),
+			Type:      eventType,
+			CreatedAt: time.Now().UTC(),
+			Data:      data,
+		},
+	})
+}
+
+// enqueueModuleUpdated is called by every write to a portfolio module.
+func enqueueModuleUpdated(tx *gorm.DB, userId string, module string, action string) error {
+	return enqueueWebhookEvent(tx, userId, models.WebhookEventPortfolioModuleUpdated, map[string]any{
+		"user_id": userId,
+		"module":  module,
+		"action":  action,
+	})
+}
+
+func NewWebhookService(db *gorm.DB) *serviceWebhook {
+	return &serviceWebhook{
+		db: db,
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -8,21 +11,22 @@ import (
 )
 
 type ServiceUserExperience interface {
-	GetAll(userId string) (*models.WorkExperiences, error)
-	Create(userId string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error)
-	Update(userId string, id string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error)
-	Reorder(userId string, id string, newIndex int) error
-	Delete(userId string, id string) error
-	GetMetadata(userId string) (interface{}, error)
-	UpdateMetadata(userId string, data *schemas.SchemaWorkExperienceMetadata) error
+	GetAll(ctx context.Context, userId string) (*models.WorkExperiences, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error)
+	Update(ctx context.Context, userId string, id string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error)
+	Reorder(ctx context.Context, userId string, id string, newIndex int) error
+	Delete(ctx context.Context, userId string, id string) error
+	GetMetadata(ctx context.Context, userId string) (interface{}, error)
+	UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaWorkExperienceMetadata) error
 }
 
 type serviceUserExperience struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceUserExperience) GetAll(userId string) (*models.WorkExperiences, error) {
-	userExperienceRepository := repositories.NewUserExperienceRepository(s.db)
+func (s *serviceUserExperience) GetAll(ctx context.Context, userId string) (*models.WorkExperiences, error) {
+	userExperienceRepository := repositories.NewUserExperienceRepository(s.db.WithContext(ctx))
 
 	res, err := userExperienceRepository.GetAll(userId)
 	if err != nil {
@@ -32,68 +36,94 @@ func (s *serviceUserExperience) GetAll(userId string) (*models.WorkExperiences,
 	return res, nil
 }
 
-func (s *serviceUserExperience) Create(userId string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error) {
-	userExperienceRepository := repositories.NewUserExperienceRepository(s.db)
+func (s *serviceUserExperience) Create(ctx context.Context, userId string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error) {
+	var exp *models.WorkExperience
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userExperienceRepository := repositories.NewUserExperienceRepository(tx)
+
+		var err error
+		exp, err = userExperienceRepository.Create(userId, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "work_experience", "created")
+	})
 
-	exp, err := userExperienceRepository.Create(userId, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return exp, nil
 }
 
-func (s *serviceUserExperience) Update(userId string, id string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error) {
-	userExperienceRepository := repositories.NewUserExperienceRepository(s.db)
+func (s *serviceUserExperience) Update(ctx context.Context, userId string, id string, data *schemas.SchemaWorkExperience) (*models.WorkExperience, error) {
+	var exp *models.WorkExperience
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userExperienceRepository := repositories.NewUserExperienceRepository(tx)
+
+		var err error
+		exp, err = userExperienceRepository.Update(userId, id, data)
+		if err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "work_experience", "updated")
+	})
 
-	exp, err := userExperienceRepository.Update(userId, id, data)
 	if err != nil {
 		return nil, err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return exp, nil
 }
 
-func (s *serviceUserExperience) Reorder(userId string, id string, newIndex int) error {
+func (s *serviceUserExperience) Reorder(ctx context.Context, userId string, id string, newIndex int) error {
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userExperienceRepository := repositories.NewUserExperienceRepository(tx)
 
 		if err := userExperienceRepository.Reorder(userId, id, newIndex); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "work_experience", "reordered")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 
 }
 
-func (s *serviceUserExperience) Delete(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceUserExperience) Delete(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userExperienceRepository := repositories.NewUserExperienceRepository(tx)
 
 		if err := userExperienceRepository.Delete(userId, id); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "work_experience", "deleted")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceUserExperience) GetMetadata(userId string) (interface{}, error) {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserExperience) GetMetadata(ctx context.Context, userId string) (interface{}, error) {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := userRepository.GetModuleMetadata(userId, "work_experience")
 	if err != nil {
@@ -103,19 +133,28 @@ func (s *serviceUserExperience) GetMetadata(userId string) (interface{}, error)
 	return res, nil
 }
 
-func (s *serviceUserExperience) UpdateMetadata(userId string, data *schemas.SchemaWorkExperienceMetadata) error {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceUserExperience) UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaWorkExperienceMetadata) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userRepository := repositories.NewUserRepository(tx)
+
+		if err := userRepository.AddOrUpdateModuleMetadata(userId, "work_experience", data); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "work_experience", "metadata_updated")
+	})
 
-	err := userRepository.AddOrUpdateModuleMetadata(userId, "work_experience", data)
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func NewUserExperienceService(db *gorm.DB) *serviceUserExperience {
+func NewUserExperienceService(db *gorm.DB, store cache.Store) *serviceUserExperience {
 	return &serviceUserExperience{
-		db: db,
+		db:    db,
+		cache: store,
 	}
 }
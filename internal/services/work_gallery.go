@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/cache"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
 	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
@@ -8,57 +11,64 @@ import (
 )
 
 type ServiceWorkGallery interface {
-	GetAll(userId *string, query *string, cursor int, limit int) (any, error)
-	GetUserWorkGallery(userId string, query *string, cursor int, limit int) (any, error)
-	Get(userId string, id string) (any, error)
-	Create(userId string, data *schemas.SchemaTechProject) (any, error)
-	Update(userId string, id string, data *schemas.SchemaTechProject) (any, error)
-	Reorder(userId string, id string, newIndex int) error
-	Delete(userId string, id string) error
-	GetMetadata(userId string) (any, error)
-	UpdateMetadata(userId string, data *schemas.SchemaTechProjectMetadata) error
+	GetAll(ctx context.Context, userId *string, query *string, page schemas.PageQuery) (*schemas.Page[schemas.SelectUserTechProject], error)
+	GetUserWorkGallery(ctx context.Context, userId string, query *string, page schemas.PageQuery) (*schemas.Page[schemas.SelectUserTechProject], error)
+	Get(ctx context.Context, userId string, id string) (any, error)
+	Create(ctx context.Context, userId string, data *schemas.SchemaTechProject) (any, error)
+	Update(ctx context.Context, userId string, id string, data *schemas.SchemaTechProject) (any, error)
+	Reorder(ctx context.Context, userId string, id string, newIndex int) error
+	Delete(ctx context.Context, userId string, id string) error
+	GetMetadata(ctx context.Context, userId string) (any, error)
+	UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaTechProjectMetadata) error
 }
 
 type serviceWorkGallery struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache cache.Store
 }
 
-func (s *serviceWorkGallery) GetAll(userId *string, query *string, cursor int, limit int) (any, error) {
-	userTechProjectRepository := repositories.NewUserTechProjectRepository(s.db)
+func (s *serviceWorkGallery) GetAll(ctx context.Context, userId *string, query *string, page schemas.PageQuery) (*schemas.Page[schemas.SelectUserTechProject], error) {
+	userTechProjectRepository := repositories.NewUserTechProjectRepository(s.db.WithContext(ctx))
 
-	res, err := userTechProjectRepository.GetAll(userId, query, cursor, limit)
+	res, err := userTechProjectRepository.GetAll(userId, query, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	var total *int64
+	if page.IncludeTotal {
+		count, err := userTechProjectRepository.CountAll(query)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
-	return map[string]any{"list": res, "cursor": nextCursor}, nil
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceWorkGallery) GetUserWorkGallery(userId string, query *string, cursor int, limit int) (any, error) {
-	userTechProjectRepository := repositories.NewUserTechProjectRepository(s.db)
+func (s *serviceWorkGallery) GetUserWorkGallery(ctx context.Context, userId string, query *string, page schemas.PageQuery) (*schemas.Page[schemas.SelectUserTechProject], error) {
+	userTechProjectRepository := repositories.NewUserTechProjectRepository(s.db.WithContext(ctx))
 
-	res, err := userTechProjectRepository.GetUserTechProjects(userId, query, cursor, limit)
+	res, err := userTechProjectRepository.GetUserTechProjects(userId, query, page.Cursor, page.FetchLimit())
 	if err != nil {
 		return nil, err
 	}
 
-	var nextCursor *int
-	if len(*res) == limit {
-		temp := cursor + limit
-		nextCursor = &temp
+	var total *int64
+	if page.IncludeTotal {
+		count, err := userTechProjectRepository.CountUserTechProjects(userId, query)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
 	}
 
-	return map[string]any{"list": res, "cursor": nextCursor}, nil
+	return schemas.NewPage(*res, page, total), nil
 }
 
-func (s *serviceWorkGallery) Get(userId string, id string) (any, error) {
-	userTechProjectRepository := repositories.NewUserTechProjectRepository(s.db)
+func (s *serviceWorkGallery) Get(ctx context.Context, userId string, id string) (any, error) {
+	userTechProjectRepository := repositories.NewUserTechProjectRepository(s.db.WithContext(ctx))
 
 	res, err := userTechProjectRepository.Get(userId, id)
 	if err != nil {
@@ -68,8 +78,8 @@ func (s *serviceWorkGallery) Get(userId string, id string) (any, error) {
 	return res, nil
 }
 
-func (s *serviceWorkGallery) Create(userId string, data *schemas.SchemaTechProject) (any, error) {
-	tx := s.db.Begin()
+func (s *serviceWorkGallery) Create(ctx context.Context, userId string, data *schemas.SchemaTechProject) (any, error) {
+	tx := s.db.WithContext(ctx).Begin()
 	userTechProjectRepository := repositories.NewUserTechProjectRepository(tx)
 	userAttachmentRepository := repositories.NewAttachmentRepository(tx)
 
@@ -89,6 +99,11 @@ func (s *serviceWorkGallery) Create(userId string, data *schemas.SchemaTechProje
 		}
 	}
 
+	if err := enqueueModuleUpdated(tx, userId, "work_gallery", "created"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	tx.Commit()
 	response := struct {
 		models.TechProject
@@ -98,12 +113,13 @@ func (s *serviceWorkGallery) Create(userId string, data *schemas.SchemaTechProje
 		Attachments: atts,
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return response, nil
 
 }
 
-func (s *serviceWorkGallery) Update(userId string, id string, data *schemas.SchemaTechProject) (any, error) {
-	tx := s.db.Begin()
+func (s *serviceWorkGallery) Update(ctx context.Context, userId string, id string, data *schemas.SchemaTechProject) (any, error) {
+	tx := s.db.WithContext(ctx).Begin()
 	userTechProjectRepository := repositories.NewUserTechProjectRepository(tx)
 	userAttachmentRepository := repositories.NewAttachmentRepository(tx)
 
@@ -119,6 +135,11 @@ func (s *serviceWorkGallery) Update(userId string, id string, data *schemas.Sche
 		return nil, err
 	}
 
+	if err := enqueueModuleUpdated(tx, userId, "work_gallery", "updated"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	tx.Commit()
 	response := struct {
 		models.TechProject
@@ -128,30 +149,32 @@ func (s *serviceWorkGallery) Update(userId string, id string, data *schemas.Sche
 		Attachments: atts,
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return response, nil
 }
 
-func (s *serviceWorkGallery) Reorder(userId string, id string, newIndex int) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceWorkGallery) Reorder(ctx context.Context, userId string, id string, newIndex int) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userTechProjectRepository := repositories.NewUserTechProjectRepository(tx)
 
 		if err := userTechProjectRepository.Reorder(userId, id, newIndex); err != nil {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "work_gallery", "reordered")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 
 }
 
-func (s *serviceWorkGallery) Delete(userId string, id string) error {
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+func (s *serviceWorkGallery) Delete(ctx context.Context, userId string, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		userTechProjectRepository := repositories.NewUserTechProjectRepository(tx)
 		userAttachmentRepository := repositories.NewAttachmentRepository(tx)
 
@@ -163,18 +186,19 @@ func (s *serviceWorkGallery) Delete(userId string, id string) error {
 			return err
 		}
 
-		return nil
+		return enqueueModuleUpdated(tx, userId, "work_gallery", "deleted")
 	})
 
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func (s *serviceWorkGallery) GetMetadata(userId string) (any, error) {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceWorkGallery) GetMetadata(ctx context.Context, userId string) (any, error) {
+	userRepository := repositories.NewUserRepository(s.db.WithContext(ctx))
 
 	res, err := userRepository.GetModuleMetadata(userId, "work_gallery")
 	if err != nil {
@@ -184,19 +208,28 @@ func (s *serviceWorkGallery) GetMetadata(userId string) (any, error) {
 	return res, nil
 }
 
-func (s *serviceWorkGallery) UpdateMetadata(userId string, data *schemas.SchemaTechProjectMetadata) error {
-	userRepository := repositories.NewUserRepository(s.db)
+func (s *serviceWorkGallery) UpdateMetadata(ctx context.Context, userId string, data *schemas.SchemaTechProjectMetadata) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userRepository := repositories.NewUserRepository(tx)
+
+		if err := userRepository.AddOrUpdateModuleMetadata(userId, "work_gallery", data); err != nil {
+			return err
+		}
+
+		return enqueueModuleUpdated(tx, userId, "work_gallery", "metadata_updated")
+	})
 
-	err := userRepository.AddOrUpdateModuleMetadata(userId, "work_gallery", data)
 	if err != nil {
 		return err
 	}
 
+	s.cache.Invalidate(cache.UserTag(userId))
 	return nil
 }
 
-func NewWorkGalleryService(db *gorm.DB) *serviceWorkGallery {
+func NewWorkGalleryService(db *gorm.DB, store cache.Store) *serviceWorkGallery {
 	return &serviceWorkGallery{
-		db: db,
+		db:    db,
+		cache: store,
 	}
 }
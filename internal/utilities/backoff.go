@@ -0,0 +1,15 @@
+package utilities
+
+import "time"
+
+// Backoff returns how long to wait before retrying after the given number of
+// failed attempts: base after the first, doubling after every other one, up
+// to max.
+func Backoff(base time.Duration, max time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+
+	return min(wait, max)
+}
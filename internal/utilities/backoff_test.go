@@ -0,0 +1,29 @@
+package utilities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		base     time.Duration
+		max      time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{base: 10 * time.Second, max: time.Hour, attempts: 0, want: 10 * time.Second},
+		{base: 10 * time.Second, max: time.Hour, attempts: 1, want: 10 * time.Second},
+		{base: 10 * time.Second, max: time.Hour, attempts: 2, want: 20 * time.Second},
+		{base: 10 * time.Second, max: time.Hour, attempts: 5, want: 160 * time.Second},
+		{base: 10 * time.Second, max: time.Hour, attempts: 10, want: time.Hour},
+		{base: 10 * time.Second, max: time.Hour, attempts: 1000, want: time.Hour},
+		{base: time.Hour, max: time.Minute, attempts: 1, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.base, tt.max, tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%v, %v, %d) = %v, want %v", tt.base, tt.max, tt.attempts, got, tt.want)
+		}
+	}
+}
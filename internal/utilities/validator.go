@@ -1,21 +1,180 @@
 package utilities
 
 import (
-	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	"github.com/sirupsen/logrus"
 )
 
-func ValidationErrorsToJSON(err error) map[string]string {
-	errors := make(map[string]string)
-	for _, err := range err.(validator.ValidationErrors) {
-		errors[err.Field()] = fmt.Sprintf("field %s: %s\n", err.Error(), err.Tag())
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+
+	universalTranslator = ut.New(en.New(), en.New(), es.New(), fr.New())
+	fallbackTranslator  = universalTranslator.GetFallback()
+)
+
+// customTranslations holds the messages for the validators registered by this
+// package, per locale. {0} is the field name and {1} the rule parameter.
+var customTranslations = map[string]map[string]string{
+	"en": {
+		"year_in_range":   "{0} must be a year within the allowed range",
+		"work_domain":     "{0} contains an unknown work domain",
+		"social_platform": "{0} is not a supported social platform",
+		"college_degree":  "{0} is not a supported degree",
+	},
+	"es": {
+		"year_in_range":   "{0} debe ser un año dentro del rango permitido",
+		"work_domain":     "{0} contiene un dominio de trabajo desconocido",
+		"social_platform": "{0} no es una plataforma social admitida",
+		"college_degree":  "{0} no es un título admitido",
+	},
+	"fr": {
+		"year_in_range":   "{0} doit être une année dans la plage autorisée",
+		"work_domain":     "{0} contient un domaine de travail inconnu",
+		"social_platform": "{0} n'est pas une plateforme sociale prise en charge",
+		"college_degree":  "{0} n'est pas un diplôme pris en charge",
+	},
+}
+
+// FieldError describes a single failed validation rule in terms of the JSON
+// request body.
+type FieldError struct {
+	Field    string `json:"field"`
+	JSONPath string `json:"json_path"`
+	Rule     string `json:"rule"`
+	Param    string `json:"param,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Validator returns the shared validator used by every request schema, with
+// the custom validations and translations registered.
+func Validator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		ConfigureValidator(validate)
+	})
+
+	return validate
+}
+
+// ConfigureValidator makes v report JSON field names and registers the
+// project's custom validations and their translations. It is also applied to
+// gin's binding validator so binding:"..." failures look the same.
+func ConfigureValidator(v *validator.Validate) {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("year_in_range", YearWithinValidRangeValidator)
+	v.RegisterValidation("work_domain", WorkDomainsValidator)
+	v.RegisterValidation("social_platform", SocialPlatformValidator)
+	v.RegisterValidation("college_degree", CollegeDegreeValidator)
+
+	registerTranslations(v)
+}
+
+func registerTranslations(v *validator.Validate) {
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"es": esTranslations.RegisterDefaultTranslations,
+		"fr": frTranslations.RegisterDefaultTranslations,
+	}
+
+	for locale, register := range defaults {
+		trans, _ := universalTranslator.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			logrus.WithError(err).Errorf("unable to register %s validation translations", locale)
+			continue
+		}
+
+		for tag, message := range customTranslations[locale] {
+			message := message
+			err := v.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+				return ut.Add(tag, message, true)
+			}, func(ut ut.Translator, fe validator.FieldError) string {
+				t, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
+				if err != nil {
+					return fe.Error()
+				}
+				return t
+			})
+			if err != nil {
+				logrus.WithError(err).Errorf("unable to register %s translation for %s", locale, tag)
+			}
+		}
+	}
+}
+
+// Translator picks the best supported translator for an Accept-Language
+// header value, falling back to English.
+func Translator(acceptLanguage string) ut.Translator {
+	var locales []string
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		tag = strings.ReplaceAll(tag, "-", "_")
+		locales = append(locales, tag)
+		if base, _, found := strings.Cut(tag, "_"); found {
+			locales = append(locales, base)
+		}
+	}
+
+	trans, _ := universalTranslator.FindTranslator(locales...)
+	return trans
+}
+
+// ValidationErrorsToFieldErrors converts validator errors into FieldErrors
+// using JSON names, e.g. social_profiles[1].url, with messages in the
+// translator's locale.
+func ValidationErrorsToFieldErrors(errs validator.ValidationErrors, trans ut.Translator) []FieldError {
+	fieldErrors := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		path := fe.Namespace()
+		// drop the name of the top level struct
+		if _, rest, found := strings.Cut(path, "."); found {
+			path = rest
+		}
+
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+		// not every locale covers every rule
+		if message == fe.Error() {
+			message = fe.Translate(fallbackTranslator)
+		}
+
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:    fe.Field(),
+			JSONPath: path,
+			Rule:     fe.Tag(),
+			Param:    fe.Param(),
+			Message:  message,
+		})
 	}
-	return errors
+	return fieldErrors
 }
 
 func YearWithinValidRangeValidator(f1 validator.FieldLevel) bool {
@@ -0,0 +1,89 @@
+package utilities
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type testSocialProfile struct {
+	Platform string `json:"platform" validate:"required,social_platform"`
+	URL      string `json:"url" validate:"required,url"`
+}
+
+type testProfile struct {
+	FullName       string              `json:"full_name" validate:"required"`
+	Skills         []string            `json:"skills" validate:"dive,min=2"`
+	WorkDomains    []string            `json:"work_domains" validate:"work_domain"`
+	SocialProfiles []testSocialProfile `json:"social_profiles" validate:"dive"`
+	Internal       string              `json:"-"`
+}
+
+func validationErrors(t *testing.T, v any) validator.ValidationErrors {
+	t.Helper()
+
+	var errs validator.ValidationErrors
+	if err := Validator().Struct(v); !errors.As(err, &errs) {
+		t.Fatalf("Validator().Struct() = %v, want validation errors", err)
+	}
+	return errs
+}
+
+func TestValidationErrorsToFieldErrors(t *testing.T) {
+	errs := validationErrors(t, testProfile{
+		Skills:      []string{"Go", "C"},
+		WorkDomains: []string{"Backend Developer", "Astronaut"},
+		SocialProfiles: []testSocialProfile{
+			{Platform: "Github", URL: "https://github.com/someone"},
+			{Platform: "Myspace", URL: "not a url"},
+		},
+	})
+
+	got := ValidationErrorsToFieldErrors(errs, Translator("en"))
+
+	want := []FieldError{
+		{Field: "full_name", JSONPath: "full_name", Rule: "required", Message: "full_name is a required field"},
+		{Field: "skills[1]", JSONPath: "skills[1]", Rule: "min", Param: "2", Message: "skills[1] must be at least 2 characters in length"},
+		{Field: "work_domains", JSONPath: "work_domains", Rule: "work_domain", Message: "work_domains contains an unknown work domain"},
+		{Field: "platform", JSONPath: "social_profiles[1].platform", Rule: "social_platform", Message: "platform is not a supported social platform"},
+		{Field: "url", JSONPath: "social_profiles[1].url", Rule: "url", Message: "url must be a valid URL"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidationErrorsToFieldErrors() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestValidationErrorsToFieldErrorsLocale(t *testing.T) {
+	errs := validationErrors(t, testProfile{
+		FullName:    "Someone",
+		WorkDomains: []string{"Astronaut"},
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{acceptLanguage: "", want: "work_domains contains an unknown work domain"},
+		{acceptLanguage: "fr-CA,en;q=0.8", want: "work_domains contient un domaine de travail inconnu"},
+		{acceptLanguage: "es", want: "work_domains contiene un dominio de trabajo desconocido"},
+		{acceptLanguage: "de,*;q=0.5", want: "work_domains contains an unknown work domain"},
+	}
+
+	for _, tt := range tests {
+		got := ValidationErrorsToFieldErrors(errs, Translator(tt.acceptLanguage))
+		if len(got) != 1 || got[0].Message != tt.want {
+			t.Errorf("Accept-Language %q: ValidationErrorsToFieldErrors() = %+v, want the message %q", tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestValidationErrorsToFieldErrorsWithoutTranslator(t *testing.T) {
+	errs := validationErrors(t, testProfile{})
+
+	got := ValidationErrorsToFieldErrors(errs, nil)
+	if len(got) != 1 || got[0].Message != "full_name is a required field" {
+		t.Errorf("ValidationErrorsToFieldErrors() = %+v, want the English message", got)
+	}
+}
@@ -0,0 +1,228 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/config"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/models"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/observability"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/repositories"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/schemas"
+	"github.com/hiumesh/dynamic-portfolio-REST-API/internal/utilities"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	SignatureHeader = "X-Dp-Signature"
+	EventHeader     = "X-Dp-Event"
+	DeliveryHeader  = "X-Dp-Delivery"
+)
+
+// maxResponseBody is how much of a response is kept in the delivery log.
+const maxResponseBody = 2048
+
+var errPrivateAddress = errors.New("webhook target resolves to a private address")
+
+// Sign returns the X-Dp-Signature header value for body sent at timestamp:
+// the HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher sends pending webhook deliveries and schedules retries of the
+// failed ones. Deliveries are claimed with SKIP LOCKED, so any number of
+// dispatchers can run against the same database.
+type Dispatcher struct {
+	db     *gorm.DB
+	config *config.WebhookConfiguration
+	client *http.Client
+}
+
+func NewDispatcher(db *gorm.DB, config *config.WebhookConfiguration) *Dispatcher {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateTargets {
+		// Checked on the resolved address, so DNS can't be used to reach
+		// internal services.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return errPrivateAddress
+			}
+
+			return nil
+		}
+	}
+
+	return &Dispatcher{
+		db:     db,
+		config: config,
+		client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: config.Timeout},
+			// A redirect is reported as a failed delivery rather than followed.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Run dispatches due deliveries every poll interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log := logrus.WithField("component", "webhooks")
+	log.Info("webhook dispatcher started")
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+			if err := d.DispatchDue(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				log.WithError(err).Error("unable to dispatch webhooks")
+			}
+		}
+	}
+}
+
+// DispatchDue sends one batch of due deliveries.
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	repository := repositories.NewWebhookRepository(d.db.WithContext(ctx))
+
+	// The lease outlasts the request, so a slow endpoint isn't sent the
+	// same delivery twice at once.
+	deliveries, err := repository.ClaimDue(d.config.BatchSize, 2*d.config.Timeout+time.Minute)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *schemas.SelectPendingWebhookDelivery) {
+			defer wg.Done()
+
+			d.deliver(ctx, delivery)
+
+			if err := repository.RecordAttempt(&delivery.WebhookDelivery); err != nil {
+				logrus.WithError(err).WithField("delivery_id", delivery.ID).Error("unable to record webhook delivery")
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// deliver sends the delivery once and updates it with the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *schemas.SelectPendingWebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = nil
+	delivery.Error = nil
+
+	log := logrus.WithFields(logrus.Fields{
+		"component":   "webhooks",
+		"delivery_id": delivery.ID,
+		"endpoint_id": delivery.EndpointId,
+		"event_type":  delivery.EventType,
+		"attempt":     delivery.Attempts,
+	})
+
+	if !delivery.Active {
+		message := "endpoint is disabled"
+		delivery.Error = &message
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		observability.WebhookDeliveries.WithLabelValues(string(delivery.Status)).Inc()
+		return
+	}
+
+	err := d.send(ctx, delivery, now)
+	if err == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = nil
+		observability.WebhookDeliveries.WithLabelValues(string(delivery.Status)).Inc()
+		return
+	}
+
+	message := err.Error()
+	delivery.Error = &message
+
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		log.WithError(err).Warn("webhook delivery failed, giving up")
+	} else {
+		next := now.Add(utilities.Backoff(d.config.BackoffBase, d.config.BackoffMax, delivery.Attempts))
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = &next
+		log.WithError(err).Info("webhook delivery failed, retrying")
+	}
+	observability.WebhookDeliveries.WithLabelValues(string(delivery.Status)).Inc()
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *schemas.SelectPendingWebhookDelivery, now time.Time) error {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dynamic-portfolio-webhooks/"+utilities.Version)
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, now, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	// Postgres text can't hold NUL bytes or invalid UTF-8.
+	text := strings.ReplaceAll(strings.ToValidUTF8(string(responseBody), ""), "\x00", "")
+	delivery.ResponseStatus = &res.StatusCode
+	delivery.ResponseBody = &text
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1760000000, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		want   string
+	}{
+		{
+			name:   "event",
+			secret: "secret",
+			body:   `{"id":"1","type":"blog.published"}`,
+			want:   "t=1760000000,v1=" + hexHMAC("secret", `1760000000.{"id":"1","type":"blog.published"}`),
+		},
+		{
+			name:   "empty body",
+			secret: "secret",
+			want:   "t=1760000000,v1=" + hexHMAC("secret", "1760000000."),
+		},
+		{
+			name:   "other secret",
+			secret: "other",
+			body:   "{}",
+			want:   "t=1760000000,v1=" + hexHMAC("other", "1760000000.{}"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignFormat(t *testing.T) {
+	got := Sign("secret", time.Unix(1760000000, 999_000_000), []byte("{}"))
+
+	t0, v1, ok := strings.Cut(got, ",")
+	if !ok || t0 != "t=1760000000" {
+		t.Fatalf("Sign() = %q, want whole seconds in t", got)
+	}
+	signature, ok := strings.CutPrefix(v1, "v1=")
+	if !ok || len(signature) != 2*sha256.Size {
+		t.Fatalf("Sign() = %q, want a hex SHA-256 in v1", got)
+	}
+	if _, err := hex.DecodeString(signature); err != nil {
+		t.Errorf("v1 is not lowercase hex: %v", err)
+	}
+}
+
+func hexHMAC(secret string, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -10,6 +10,9 @@ create table
     created_at timestamptz not null,
     updated_at timestamptz not null,
     deleted_at timestamptz,
+    fts tsvector GENERATED ALWAYS as (
+      to_tsvector('english'::regconfig, ((title || ' '::text) || description))
+    ) STORED null,
     constraint tech_projects_pkey primary key (id),
     constraint tech_projects_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade,
     constraint tech_projects_user_id_and_order_index_composite_key unique (user_id, order_index) deferrable initially deferred
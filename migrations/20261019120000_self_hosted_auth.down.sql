@@ -0,0 +1,3 @@
+drop table if exists auth_one_time_tokens;
+drop table if exists auth_refresh_tokens;
+drop table if exists auth_sessions;
@@ -0,0 +1,43 @@
+-- tables
+
+create table public.auth_sessions (
+    id uuid not null default gen_random_uuid(),
+    user_id uuid not null,
+    user_agent text null,
+    ip text null,
+    created_at timestamp with time zone not null default now(),
+    refreshed_at timestamp with time zone null,
+    constraint auth_sessions_pkey primary key (id),
+    constraint auth_sessions_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade
+);
+
+create table public.auth_refresh_tokens (
+    id bigserial not null,
+    session_id uuid not null,
+    token_hash text not null,
+    revoked boolean not null default false,
+    created_at timestamp with time zone not null default now(),
+    expires_at timestamp with time zone not null,
+    constraint auth_refresh_tokens_pkey primary key (id),
+    constraint auth_refresh_tokens_token_hash_ukey unique (token_hash),
+    constraint auth_refresh_tokens_session_id_fkey foreign key (session_id) references auth_sessions (id) on delete cascade
+);
+
+create table public.auth_one_time_tokens (
+    id bigserial not null,
+    user_id uuid not null,
+    token_type text not null,
+    token_hash text not null,
+    created_at timestamp with time zone not null default now(),
+    expires_at timestamp with time zone not null,
+    constraint auth_one_time_tokens_pkey primary key (id),
+    constraint auth_one_time_tokens_token_hash_ukey unique (token_hash),
+    constraint auth_one_time_tokens_token_type_check check (token_type in ('signup', 'recovery')),
+    constraint auth_one_time_tokens_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade
+);
+
+-- indexes
+
+create index auth_sessions_user_id_idx on auth_sessions (user_id);
+create index auth_refresh_tokens_session_id_idx on auth_refresh_tokens (session_id);
+create index auth_one_time_tokens_user_id_idx on auth_one_time_tokens (user_id, token_type);
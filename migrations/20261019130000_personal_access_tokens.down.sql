@@ -0,0 +1 @@
+drop table if exists personal_access_tokens;
@@ -0,0 +1,20 @@
+-- tables
+
+create table public.personal_access_tokens (
+    id bigserial not null,
+    user_id uuid not null,
+    name text not null,
+    token_prefix text not null,
+    token_hash text not null,
+    scopes text[] not null default '{}',
+    expires_at timestamp with time zone null,
+    last_used_at timestamp with time zone null,
+    created_at timestamp with time zone not null default now(),
+    constraint personal_access_tokens_pkey primary key (id),
+    constraint personal_access_tokens_token_hash_ukey unique (token_hash),
+    constraint personal_access_tokens_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade
+);
+
+-- indexes
+
+create index personal_access_tokens_user_id_idx on personal_access_tokens (user_id);
@@ -0,0 +1,2 @@
+drop table if exists user_bans;
+drop table if exists token_revocations;
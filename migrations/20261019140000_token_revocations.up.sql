@@ -0,0 +1,24 @@
+-- tables
+
+create table public.token_revocations (
+    kind text not null,
+    key text not null,
+    revoked_at timestamp with time zone not null default now(),
+    expires_at timestamp with time zone null,
+    constraint token_revocations_pkey primary key (kind, key),
+    constraint token_revocations_kind_check check (kind in ('session', 'jti', 'user'))
+);
+
+create table public.user_bans (
+    user_id uuid not null,
+    reason text null,
+    banned_until timestamp with time zone null,
+    created_by uuid null,
+    created_at timestamp with time zone not null default now(),
+    constraint user_bans_pkey primary key (user_id),
+    constraint user_bans_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade
+);
+
+-- indexes
+
+create index token_revocations_expires_at_idx on token_revocations (expires_at);
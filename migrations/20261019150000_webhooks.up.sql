@@ -0,0 +1,40 @@
+-- tables
+
+create table public.webhook_endpoints (
+    id bigserial not null,
+    user_id uuid not null,
+    url text not null,
+    secret text not null,
+    event_types text[] not null default '{}',
+    active boolean not null default true,
+    description text null,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+    constraint webhook_endpoints_pkey primary key (id),
+    constraint webhook_endpoints_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade
+);
+
+create table public.webhook_deliveries (
+    id bigserial not null,
+    endpoint_id bigint not null,
+    event_id uuid not null,
+    event_type text not null,
+    payload jsonb not null,
+    status text not null default 'pending',
+    attempts integer not null default 0,
+    next_attempt_at timestamp with time zone null default now(),
+    last_attempt_at timestamp with time zone null,
+    response_status integer null,
+    response_body text null,
+    error text null,
+    created_at timestamp with time zone not null default now(),
+    constraint webhook_deliveries_pkey primary key (id),
+    constraint webhook_deliveries_endpoint_id_fkey foreign key (endpoint_id) references webhook_endpoints (id) on delete cascade,
+    constraint webhook_deliveries_status_check check (status in ('pending', 'succeeded', 'failed'))
+);
+
+-- indexes
+
+create index webhook_endpoints_user_id_idx on webhook_endpoints (user_id);
+create index webhook_deliveries_endpoint_id_idx on webhook_deliveries (endpoint_id, created_at desc);
+create index webhook_deliveries_pending_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
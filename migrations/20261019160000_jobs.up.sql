@@ -0,0 +1,26 @@
+-- tables
+
+create table public.jobs (
+    id bigserial not null,
+    type text not null,
+    payload jsonb not null default '{}',
+    status text not null default 'pending',
+    attempts integer not null default 0,
+    max_attempts integer null,
+    run_at timestamp with time zone not null default now(),
+    locked_until timestamp with time zone null,
+    last_error text null,
+    unique_key text null,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+    finished_at timestamp with time zone null,
+    constraint jobs_pkey primary key (id),
+    constraint jobs_unique_key_ukey unique (unique_key),
+    constraint jobs_status_check check (status in ('pending', 'running', 'succeeded', 'dead'))
+);
+
+-- indexes
+
+create index jobs_pending_idx on jobs (run_at) where status = 'pending';
+create index jobs_running_idx on jobs (locked_until) where status = 'running';
+create index jobs_finished_at_idx on jobs (finished_at) where finished_at is not null;
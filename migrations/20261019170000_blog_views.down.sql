@@ -0,0 +1,3 @@
+drop index if exists blog_reactions_blog_id_created_at_idx;
+alter table public.blog_reactions drop column if exists created_at;
+drop table if exists blog_views;
@@ -0,0 +1,19 @@
+-- tables
+
+create table public.blog_views (
+    blog_id bigint not null,
+    day date not null,
+    viewer text not null,
+    referrer text null,
+    created_at timestamp with time zone not null default now(),
+    constraint blog_views_pkey primary key (blog_id, day, viewer),
+    constraint blog_views_blog_id_fkey foreign key (blog_id) references public.blogs (id) on delete cascade
+);
+
+-- Reactions had no timestamps; existing ones are dated to the migration.
+alter table public.blog_reactions add column created_at timestamp with time zone not null default now();
+
+-- indexes
+
+create index blog_views_day_idx on blog_views (day);
+create index blog_reactions_blog_id_created_at_idx on blog_reactions (blog_id, created_at);
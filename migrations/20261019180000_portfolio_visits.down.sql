@@ -0,0 +1,3 @@
+alter table public.user_profiles drop column if exists share_portfolio_views;
+drop table if exists portfolio_visits;
+drop table if exists analytics_salts;
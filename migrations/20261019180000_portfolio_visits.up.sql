@@ -0,0 +1,30 @@
+-- tables
+
+-- One random salt per day for hashing visitors; deleted once the day is
+-- over, after which the hashes can't be linked to anyone.
+create table public.analytics_salts (
+    day date not null,
+    salt bytea not null,
+    constraint analytics_salts_pkey primary key (day)
+);
+
+create table public.portfolio_visits (
+    user_id uuid not null,
+    day date not null,
+    visitor text not null,
+    module text not null,
+    viewer_id uuid null,
+    referrer_host text null,
+    country text null,
+    created_at timestamp with time zone not null default now(),
+    constraint portfolio_visits_pkey primary key (user_id, day, visitor, module),
+    constraint portfolio_visits_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade,
+    constraint portfolio_visits_viewer_id_fkey foreign key (viewer_id) references auth.users (id) on delete set null
+);
+
+alter table public.user_profiles add column share_portfolio_views boolean not null default false;
+
+-- indexes
+
+create index portfolio_visits_day_idx on portfolio_visits (day);
+create index portfolio_visits_viewer_id_idx on portfolio_visits (user_id, viewer_id) where viewer_id is not null;
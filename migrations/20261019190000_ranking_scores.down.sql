@@ -0,0 +1,20 @@
+drop index if exists user_profiles_score_all_idx;
+drop index if exists user_profiles_score_month_idx;
+drop index if exists user_profiles_score_week_idx;
+drop index if exists user_profiles_score_trending_idx;
+drop index if exists blogs_score_all_idx;
+drop index if exists blogs_score_month_idx;
+drop index if exists blogs_score_week_idx;
+drop index if exists blogs_score_trending_idx;
+alter table public.user_follows drop column if exists created_at;
+alter table public.blog_bookmarks drop column if exists created_at;
+alter table public.user_profiles
+    drop column if exists score_all,
+    drop column if exists score_month,
+    drop column if exists score_week,
+    drop column if exists score_trending;
+alter table public.blogs
+    drop column if exists score_all,
+    drop column if exists score_month,
+    drop column if exists score_week,
+    drop column if exists score_trending;
@@ -0,0 +1,31 @@
+-- Scores are recomputed by the maintenance.scores job; see the ranking
+-- repository for how they are computed.
+
+alter table public.blogs
+    add column score_trending double precision not null default 0,
+    add column score_week double precision not null default 0,
+    add column score_month double precision not null default 0,
+    add column score_all double precision not null default 0;
+
+alter table public.user_profiles
+    add column score_trending double precision not null default 0,
+    add column score_week double precision not null default 0,
+    add column score_month double precision not null default 0,
+    add column score_all double precision not null default 0;
+
+-- Bookmarks and follows had no timestamps; existing ones are dated to the
+-- migration.
+alter table public.blog_bookmarks add column created_at timestamp with time zone not null default now();
+alter table public.user_follows add column created_at timestamp with time zone not null default now();
+
+-- indexes
+
+create index blogs_score_trending_idx on blogs (score_trending desc, id desc) where published_at is not null;
+create index blogs_score_week_idx on blogs (score_week desc, id desc) where published_at is not null;
+create index blogs_score_month_idx on blogs (score_month desc, id desc) where published_at is not null;
+create index blogs_score_all_idx on blogs (score_all desc, id desc) where published_at is not null;
+
+create index user_profiles_score_trending_idx on user_profiles (score_trending desc, user_id desc) where portfolio_status = 'ACTIVE';
+create index user_profiles_score_week_idx on user_profiles (score_week desc, user_id desc) where portfolio_status = 'ACTIVE';
+create index user_profiles_score_month_idx on user_profiles (score_month desc, user_id desc) where portfolio_status = 'ACTIVE';
+create index user_profiles_score_all_idx on user_profiles (score_all desc, user_id desc) where portfolio_status = 'ACTIVE';
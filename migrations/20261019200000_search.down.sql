@@ -0,0 +1,6 @@
+drop index if exists skills_name_trgm_idx;
+drop index if exists tech_projects_title_trgm_idx;
+drop index if exists blogs_title_trgm_idx;
+drop index if exists user_profiles_full_name_trgm_idx;
+-- pg_trgm is left installed: it may predate this migration, and other
+-- objects, such as the typeahead indexes, depend on it.
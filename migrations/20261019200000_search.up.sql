@@ -0,0 +1,14 @@
+create extension if not exists pg_trgm;
+
+-- Databases created before tech_projects.fts was part of its migration.
+alter table public.tech_projects add column if not exists fts tsvector GENERATED ALWAYS as (
+  to_tsvector('english'::regconfig, ((title || ' '::text) || description))
+) STORED null;
+
+-- indexes
+
+create index if not exists tech_projects_fts_idx on tech_projects using gin (fts);
+create index user_profiles_full_name_trgm_idx on user_profiles using gin (full_name gin_trgm_ops);
+create index blogs_title_trgm_idx on blogs using gin (title gin_trgm_ops);
+create index tech_projects_title_trgm_idx on tech_projects using gin (title gin_trgm_ops);
+create index skills_name_trgm_idx on skills using gin (name gin_trgm_ops);
@@ -0,0 +1,8 @@
+drop index if exists user_follows_following_id_idx;
+drop index if exists user_profiles_slug_trgm_idx;
+drop index if exists work_experiences_company_name_trgm_idx;
+drop index if exists educations_institute_name_trgm_idx;
+drop index if exists tags_name_trgm_idx;
+drop index if exists skills_usage_count_idx;
+alter table public.tags drop column if exists usage_count;
+alter table public.skills drop column if exists usage_count;
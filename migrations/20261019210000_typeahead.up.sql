@@ -0,0 +1,11 @@
+alter table public.skills add column usage_count bigint not null default 0;
+alter table public.tags add column usage_count bigint not null default 0;
+
+-- indexes
+
+create index skills_usage_count_idx on skills (usage_count desc, name);
+create index tags_name_trgm_idx on tags using gin (name gin_trgm_ops);
+create index educations_institute_name_trgm_idx on educations using gin (institute_name gin_trgm_ops) where deleted_at is null;
+create index work_experiences_company_name_trgm_idx on work_experiences using gin (company_name gin_trgm_ops) where deleted_at is null;
+create index user_profiles_slug_trgm_idx on user_profiles using gin (slug gin_trgm_ops) where portfolio_status = 'ACTIVE';
+create index user_follows_following_id_idx on user_follows (following_id);
@@ -0,0 +1,11 @@
+drop index if exists tag_follows_tag_id_idx;
+drop index if exists tag_aliases_tag_id_idx;
+drop index if exists blog_tags_tag_id_idx;
+drop index if exists tags_usage_count_idx;
+drop index if exists tags_lower_name_idx;
+drop table if exists public.tag_follows;
+drop table if exists public.tag_aliases;
+alter table public.tags drop column if exists deprecated_at;
+alter table public.tags drop constraint tags_user_id_fkey;
+alter table public.tags
+    add constraint tags_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade;
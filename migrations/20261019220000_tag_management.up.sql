@@ -0,0 +1,31 @@
+-- Tags are global: deleting the user who first used one no longer deletes it.
+alter table public.tags drop constraint tags_user_id_fkey;
+alter table public.tags
+    add constraint tags_user_id_fkey foreign key (user_id) references auth.users (id) on delete set null;
+
+alter table public.tags add column deprecated_at timestamptz;
+
+create table public.tag_aliases (
+    name text not null,
+    tag_id bigint not null,
+    created_at timestamptz not null default now(),
+    constraint tag_aliases_pkey primary key (name),
+    constraint tag_aliases_tag_id_fkey foreign key (tag_id) references public.tags (id) on delete cascade
+) tablespace pg_default;
+
+create table public.tag_follows (
+    user_id uuid not null,
+    tag_id bigint not null,
+    created_at timestamptz not null default now(),
+    constraint tag_follows_pkey primary key (user_id, tag_id),
+    constraint tag_follows_user_id_fkey foreign key (user_id) references auth.users (id) on delete cascade,
+    constraint tag_follows_tag_id_fkey foreign key (tag_id) references public.tags (id) on delete cascade
+) tablespace pg_default;
+
+-- indexes
+
+create index tags_lower_name_idx on tags (lower(name));
+create index tags_usage_count_idx on tags (usage_count desc, id) where deleted_at is null and deprecated_at is null;
+create index blog_tags_tag_id_idx on blog_tags (tag_id);
+create index tag_aliases_tag_id_idx on tag_aliases (tag_id);
+create index tag_follows_tag_id_idx on tag_follows (tag_id);
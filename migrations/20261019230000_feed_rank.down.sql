@@ -0,0 +1,3 @@
+drop index if exists tech_projects_created_at_idx;
+drop index if exists blogs_rank_at_idx;
+alter table public.blogs drop column if exists rank_at;
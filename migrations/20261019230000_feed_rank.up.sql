@@ -0,0 +1,11 @@
+-- The feed position of a published blog: its publication time pushed forward
+-- by its engagement. Set on publish and refreshed by the maintenance.scores
+-- job, so feed pages can be keyed on it.
+alter table public.blogs add column rank_at timestamptz;
+
+update public.blogs set rank_at = published_at where published_at is not null;
+
+-- indexes
+
+create index blogs_rank_at_idx on blogs (rank_at desc, id desc) where published_at is not null and deleted_at is null;
+create index tech_projects_created_at_idx on tech_projects (created_at desc, id desc) where deleted_at is null;
@@ -0,0 +1,2 @@
+-- The replaced user ids can't be restored.
+select 1;
@@ -0,0 +1,16 @@
+// Package migrations embeds the SQL migrations applied by dp migrate.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
+
+// ReplacedChecksums maps a migration version to the checksums its up file had
+// before it was corrected, so databases that applied the earlier file keep
+// migrating. An applied file must never change without an entry here.
+var ReplacedChecksums = map[int64][]string{
+	// tech_projects.fts was missing, so the file failed on fresh databases.
+	// Existing ones get the column from 20261019200000_search.
+	20241221160528: {"f288502ced19e3fbaf8875143ec0a3ac592aeacff3dee79e38d170fc553fd04f"},
+}